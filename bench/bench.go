@@ -0,0 +1,246 @@
+/*
+Package bench is a benchmark matrix for the types.Map implementations in this
+library. Every map is run through the same workloads with the same keys so the
+numbers can be compared directly. Run it with
+
+	go test -run XXX -bench . -benchmem ./bench
+
+or print a comparison table with the cmd/dsbench runner. Each op is a single
+map operation (or a single step of an iteration) so ns/op and allocs/op are
+per operation, unlike the older benchmarks in the individual packages.
+*/
+package bench
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+
+	mrand "math/rand"
+)
+
+import (
+	"github.com/timtadh/data-structures/hashtable"
+	"github.com/timtadh/data-structures/tree/avl"
+	"github.com/timtadh/data-structures/tree/bptree"
+	"github.com/timtadh/data-structures/tree/rbtree"
+	"github.com/timtadh/data-structures/tree/skiplist"
+	"github.com/timtadh/data-structures/tree/treap"
+	"github.com/timtadh/data-structures/trie"
+	"github.com/timtadh/data-structures/types"
+)
+
+// A map which can iterate over the keys in [from, to].
+type Ranger interface {
+	Range(from, to types.Hashable) types.KVIterator
+}
+
+type Map struct {
+	Name string
+	New  func() types.Map
+	// nil if the map takes every key type
+	Takes func(key types.Hashable) bool
+}
+
+func takes_bytes(key types.Hashable) bool {
+	switch key.(type) {
+	case types.String, types.ByteSlice:
+		return true
+	}
+	return false
+}
+
+var Maps = []Map{
+	{"AvlTree", func() types.Map { return avl.NewAvlTree() }, nil},
+	{"ImmutableAvlTree", func() types.Map { return avl.NewImmutableAvlTree() }, nil},
+	{"RbTree", func() types.Map { return rbtree.NewRbTree() }, nil},
+	{"Treap", func() types.Map { return treap.NewTreap() }, nil},
+	{"SkipList", func() types.Map { return skiplist.NewSkipList() }, nil},
+	{"BpMap", func() types.Map { return bptree.NewBpMap(64) }, nil},
+	{"Hash", func() types.Map { return hashtable.NewHashTable(64) }, nil},
+	{"LinearHash", func() types.Map { return hashtable.NewLinearHash() }, nil},
+	{"TST", func() types.Map { return trie.NewTSTMap() }, takes_bytes},
+}
+
+type KeyType struct {
+	Name string
+	// makes n distinct keys, the same n for the same seed
+	Make func(rand *mrand.Rand, n int) []types.Hashable
+}
+
+func rand_bytes(rand *mrand.Rand, n int) [][]byte {
+	seen := make(map[string]bool, n)
+	keys := make([][]byte, 0, n)
+	for len(keys) < n {
+		key := []byte(fmt.Sprintf("%016x", rand.Int63()))
+		if !seen[string(key)] {
+			seen[string(key)] = true
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+var KeyTypes = []KeyType{
+	{"Int", func(rand *mrand.Rand, n int) []types.Hashable {
+		keys := make([]types.Hashable, n)
+		for i, k := range rand.Perm(n) {
+			keys[i] = types.Int(k * 2)
+		}
+		return keys
+	}},
+	{"String", func(rand *mrand.Rand, n int) []types.Hashable {
+		keys := make([]types.Hashable, n)
+		for i, k := range rand_bytes(rand, n) {
+			keys[i] = types.String(k)
+		}
+		return keys
+	}},
+	{"ByteSlice", func(rand *mrand.Rand, n int) []types.Hashable {
+		keys := make([]types.Hashable, n)
+		for i, k := range rand_bytes(rand, n) {
+			keys[i] = types.ByteSlice(k)
+		}
+		return keys
+	}},
+}
+
+var Sizes = []int{100, 10000, 100000}
+
+// The keys for a benchmark. The map is filled with Hits (in that order),
+// Misses are never put and Sorted is Hits in ascending order.
+type Keys struct {
+	Hits   []types.Hashable
+	Misses []types.Hashable
+	Sorted []types.Hashable
+}
+
+func MakeKeys(kt KeyType, n int) *Keys {
+	all := kt.Make(mrand.New(mrand.NewSource(int64(n))), 2*n)
+	k := &Keys{
+		Hits:   all[:n],
+		Misses: all[n:],
+		Sorted: make([]types.Hashable, n),
+	}
+	copy(k.Sorted, k.Hits)
+	sort.Slice(k.Sorted, func(i, j int) bool { return k.Sorted[i].Less(k.Sorted[j]) })
+	return k
+}
+
+func fill(m Map, keys []types.Hashable) types.Map {
+	t := m.New()
+	for i, k := range keys {
+		if err := t.Put(k, i); err != nil {
+			panic(err)
+		}
+	}
+	return t
+}
+
+type Workload struct {
+	Name string
+	Run  func(b *testing.B, m Map, keys *Keys)
+}
+
+// The width of the key ranges in the Range workload.
+const RangeWidth = 10
+
+var Workloads = []Workload{
+	{"Insert", func(b *testing.B, m Map, keys *Keys) {
+		t := m.New()
+		for i := 0; i < b.N; i++ {
+			j := i % len(keys.Hits)
+			if j == 0 && i > 0 {
+				b.StopTimer()
+				t = m.New()
+				b.StartTimer()
+			}
+			t.Put(keys.Hits[j], i)
+		}
+	}},
+	{"LookupHit", func(b *testing.B, m Map, keys *Keys) {
+		b.StopTimer()
+		t := fill(m, keys.Hits)
+		b.StartTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := t.Get(keys.Hits[i%len(keys.Hits)]); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}},
+	{"LookupMiss", func(b *testing.B, m Map, keys *Keys) {
+		b.StopTimer()
+		t := fill(m, keys.Hits)
+		b.StartTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := t.Get(keys.Misses[i%len(keys.Misses)]); err == nil {
+				b.Fatal("found a key which was never put")
+			}
+		}
+	}},
+	{"Delete", func(b *testing.B, m Map, keys *Keys) {
+		b.StopTimer()
+		t := fill(m, keys.Hits)
+		b.StartTimer()
+		for i := 0; i < b.N; i++ {
+			j := i % len(keys.Hits)
+			if j == 0 && i > 0 {
+				b.StopTimer()
+				t = fill(m, keys.Hits)
+				b.StartTimer()
+			}
+			if _, err := t.Remove(keys.Hits[j]); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}},
+	{"Iterate", func(b *testing.B, m Map, keys *Keys) {
+		b.StopTimer()
+		t := fill(m, keys.Hits)
+		b.StartTimer()
+		it := t.Iterate()
+		for i := 0; i < b.N; i++ {
+			_, _, it = it()
+			if it == nil {
+				it = t.Iterate()
+			}
+		}
+	}},
+	{"Range", func(b *testing.B, m Map, keys *Keys) {
+		b.StopTimer()
+		t := fill(m, keys.Hits).(Ranger)
+		b.StartTimer()
+		for i := 0; i < b.N; i++ {
+			j := i % (len(keys.Sorted) - RangeWidth + 1)
+			count := 0
+			for _, _, next := t.Range(keys.Sorted[j], keys.Sorted[j+RangeWidth-1])(); next != nil; _, _, next = next() {
+				count++
+			}
+			if count != RangeWidth {
+				b.Fatalf("range returned %v keys, expected %v", count, RangeWidth)
+			}
+		}
+	}},
+}
+
+// Can the map run the workload with the key type? Range needs a Ranger.
+func Supports(m Map, kt KeyType, w Workload) bool {
+	if m.Takes != nil && !m.Takes(kt.Make(mrand.New(mrand.NewSource(0)), 1)[0]) {
+		return false
+	}
+	if w.Name == "Range" {
+		_, ok := m.New().(Ranger)
+		return ok
+	}
+	return true
+}
+
+// Runs one cell of the matrix. The keys are made before the timer starts.
+func Run(b *testing.B, m Map, kt KeyType, w Workload, n int) {
+	b.StopTimer()
+	keys := MakeKeys(kt, n)
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.StartTimer()
+	w.Run(b, m, keys)
+}
@@ -0,0 +1,33 @@
+package bench
+
+import (
+	"fmt"
+	"testing"
+)
+
+// Runs the whole matrix as sub-benchmarks named map/keys/size/workload, pick
+// out a part of it with -bench, for instance
+//
+//	go test -run XXX -bench 'Maps/.*/String/10000/' ./bench
+func BenchmarkMaps(b *testing.B) {
+	for _, m := range Maps {
+		b.Run(m.Name, func(b *testing.B) {
+			for _, kt := range KeyTypes {
+				b.Run(kt.Name, func(b *testing.B) {
+					for _, n := range Sizes {
+						b.Run(fmt.Sprint(n), func(b *testing.B) {
+							for _, w := range Workloads {
+								if !Supports(m, kt, w) {
+									continue
+								}
+								b.Run(w.Name, func(b *testing.B) {
+									Run(b, m, kt, w, n)
+								})
+							}
+						})
+					}
+				})
+			}
+		})
+	}
+}
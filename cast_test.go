@@ -7,6 +7,9 @@ import (
 	"github.com/timtadh/data-structures/set"
 	"github.com/timtadh/data-structures/tree/avl"
 	"github.com/timtadh/data-structures/tree/bptree"
+	"github.com/timtadh/data-structures/tree/rbtree"
+	"github.com/timtadh/data-structures/tree/skiplist"
+	"github.com/timtadh/data-structures/tree/treap"
 	"github.com/timtadh/data-structures/trie"
 	"github.com/timtadh/data-structures/types"
 )
@@ -66,3 +69,18 @@ func TestSetCast(t *testing.T) {
 	_ = types.Set(s)
 	_ = types.Hashable(s)
 }
+
+func TestRbTreeCast(t *testing.T) {
+	tree := rbtree.NewRbTree()
+	_ = types.TreeMap(tree)
+}
+
+func TestTreapCast(t *testing.T) {
+	tree := treap.NewTreap()
+	_ = types.TreeMap(tree)
+}
+
+func TestSkipListCast(t *testing.T) {
+	list := skiplist.NewSkipList()
+	_ = types.Map(list)
+}
@@ -0,0 +1,147 @@
+/*
+dsbench runs the map benchmark matrix from the bench package and prints a
+table with one row per key type, size and workload and one column per map.
+Each cell is "ns/op (allocs/op)", "-" when the map does not support the
+workload (Range) or the key type (TST only takes String and ByteSlice keys).
+
+	dsbench -maps AvlTree,BpMap,Hash -keys String -sizes 1000,100000 -benchtime 200ms
+*/
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"text/tabwriter"
+)
+
+import (
+	"github.com/timtadh/data-structures/bench"
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: dsbench [flags]")
+	flag.PrintDefaults()
+}
+
+// the names in a comma separated flag, all of them when it is empty
+func selected(flag_value string, all []string) (names []string, err error) {
+	if flag_value == "" {
+		return all, nil
+	}
+outer:
+	for _, name := range strings.Split(flag_value, ",") {
+		for _, a := range all {
+			if strings.EqualFold(name, a) {
+				names = append(names, a)
+				continue outer
+			}
+		}
+		return nil, fmt.Errorf("unknown name %q, expected one of %v", name, strings.Join(all, ", "))
+	}
+	return names, nil
+}
+
+func main() {
+	testing.Init()
+	maps := flag.String("maps", "", "comma separated maps to run (default all)")
+	keys := flag.String("keys", "", "comma separated key types to use (default all)")
+	workloads := flag.String("workloads", "", "comma separated workloads to run (default all)")
+	sizes := flag.String("sizes", "", "comma separated map sizes (default 100,10000,100000)")
+	benchtime := flag.String("benchtime", "1s", "run each benchmark for this long, or Nx for N ops")
+	flag.Usage = usage
+	flag.Parse()
+	if err := run(*maps, *keys, *workloads, *sizes, *benchtime); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(map_flag, key_flag, workload_flag, size_flag, benchtime string) error {
+	if err := flag.Set("test.benchtime", benchtime); err != nil {
+		return err
+	}
+	all := func(n int, name func(i int) string) []string {
+		names := make([]string, n)
+		for i := range names {
+			names[i] = name(i)
+		}
+		return names
+	}
+	map_names, err := selected(map_flag, all(len(bench.Maps), func(i int) string { return bench.Maps[i].Name }))
+	if err != nil {
+		return err
+	}
+	key_names, err := selected(key_flag, all(len(bench.KeyTypes), func(i int) string { return bench.KeyTypes[i].Name }))
+	if err != nil {
+		return err
+	}
+	workload_names, err := selected(workload_flag, all(len(bench.Workloads), func(i int) string { return bench.Workloads[i].Name }))
+	if err != nil {
+		return err
+	}
+	sizes := bench.Sizes
+	if size_flag != "" {
+		sizes = nil
+		for _, s := range strings.Split(size_flag, ",") {
+			n, err := strconv.Atoi(s)
+			if err != nil || n <= bench.RangeWidth {
+				return fmt.Errorf("bad size %q, sizes must be ints greater than %v", s, bench.RangeWidth)
+			}
+			sizes = append(sizes, n)
+		}
+	}
+
+	maps := make([]bench.Map, 0, len(map_names))
+	for _, name := range map_names {
+		for _, m := range bench.Maps {
+			if m.Name == name {
+				maps = append(maps, m)
+			}
+		}
+	}
+	out := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(os.Stdout, "ns/op (allocs/op)")
+	fmt.Fprint(out, "keys\tsize\tworkload\t")
+	for _, m := range maps {
+		fmt.Fprintf(out, "%v\t", m.Name)
+	}
+	fmt.Fprintln(out)
+	for _, kt := range bench.KeyTypes {
+		if !contains(key_names, kt.Name) {
+			continue
+		}
+		for _, n := range sizes {
+			for _, w := range bench.Workloads {
+				if !contains(workload_names, w.Name) {
+					continue
+				}
+				fmt.Fprintf(out, "%v\t%v\t%v\t", kt.Name, n, w.Name)
+				for _, m := range maps {
+					if !bench.Supports(m, kt, w) {
+						fmt.Fprint(out, "-\t")
+						continue
+					}
+					r := testing.Benchmark(func(b *testing.B) {
+						bench.Run(b, m, kt, w, n)
+					})
+					fmt.Fprintf(out, "%v (%v)\t", r.NsPerOp(), r.AllocsPerOp())
+				}
+				fmt.Fprintln(out)
+			}
+		}
+	}
+	return out.Flush()
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
@@ -70,9 +70,17 @@ func (e *Error) String() string {
 
 type ErrorFmter func(a ...interface{}) error
 
+const not_found = "Key was not found."
+
 func NotFound(a ...interface{}) error {
 	// return fmt.Errorf("Key '%v' was not found.", a...)
-	return Errorf("Key was not found.")
+	return Errorf(not_found)
+}
+
+// Was err made by NotFound?
+func IsNotFound(err error) bool {
+	e, ok := err.(*Error)
+	return ok && len(e.Errs) > 0 && e.Errs[0].Error() == not_found
 }
 
 func NotFoundInBucket(a ...interface{}) error {
@@ -0,0 +1,148 @@
+package filter
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+import (
+	"github.com/timtadh/data-structures/errors"
+	"github.com/timtadh/data-structures/types"
+)
+
+/* A Bloom filter: k bits are set for each item added and an item is in the
+ * filter if all of its bits are set. Items can not be removed (see
+ * CountingBloom for that). The filter takes about 1.44*log2(1/p) bits per
+ * item for a false positive rate p.
+ */
+type Bloom struct {
+	bits  []uint64
+	m     uint64 // the number of bits
+	k     int
+	count int
+}
+
+// The most hashes a Bloom filter uses. Only false positive rates below about
+// 2^-64 want more.
+const bloom_max_hashes = 64
+
+// The number of bits and hashes for n items with a false positive rate of p.
+func bloom_size(n int, p float64) (m uint64, k int) {
+	if p <= 0 || p >= 1 {
+		panic(errors.Errorf("The false positive rate must be in (0, 1), got %v", p))
+	}
+	if n < 1 {
+		n = 1
+	}
+	m = uint64(math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2)))
+	k = int(math.Round(float64(m) / float64(n) * math.Ln2))
+	if k < 1 {
+		k = 1
+	} else if k > bloom_max_hashes {
+		k = bloom_max_hashes
+	}
+	return m, k
+}
+
+// The false positive rate of m bits and k hashes after n items.
+func bloom_rate(m uint64, k, n int) float64 {
+	return math.Pow(1-math.Exp(-float64(k)*float64(n)/float64(m)), float64(k))
+}
+
+// Makes a Bloom filter with a false positive rate of p after n items.
+func NewBloom(n int, p float64) *Bloom {
+	m, k := bloom_size(n, p)
+	return &Bloom{
+		bits: make([]uint64, (m+63)/64),
+		m:    m,
+		k:    k,
+	}
+}
+
+// The number of items added (counting repeats).
+func (b *Bloom) Count() int {
+	return b.count
+}
+
+// The expected false positive rate with the items added so far.
+func (b *Bloom) FalsePositiveRate() float64 {
+	return bloom_rate(b.m, b.k, b.count)
+}
+
+func (b *Bloom) Add(item types.Hashable) {
+	h1, h2 := Hash(item)
+	for i := 0; i < b.k; i++ {
+		bit := (h1 + uint64(i)*h2) % b.m
+		b.bits[bit/64] |= 1 << (bit % 64)
+	}
+	b.count++
+}
+
+// Has the item (probably) been added? False positives happen at about
+// FalsePositiveRate, false negatives never do.
+func (b *Bloom) Has(item types.Hashable) bool {
+	h1, h2 := Hash(item)
+	for i := 0; i < b.k; i++ {
+		bit := (h1 + uint64(i)*h2) % b.m
+		if b.bits[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Adds the items of o to b. They must have been made with the same n and p.
+func (b *Bloom) Merge(o *Bloom) error {
+	if b.m != o.m || b.k != o.k {
+		return errors.Errorf("Can not merge Bloom filters of different sizes")
+	}
+	for i := range b.bits {
+		b.bits[i] |= o.bits[i]
+	}
+	b.count += o.count
+	return nil
+}
+
+// The number of bits, the number of hashes, the count and then the bits,
+// little endian.
+func (b *Bloom) MarshalBinary() ([]byte, error) {
+	bytes := make([]byte, 24+8*len(b.bits))
+	binary.LittleEndian.PutUint64(bytes[0:], b.m)
+	binary.LittleEndian.PutUint64(bytes[8:], uint64(b.k))
+	binary.LittleEndian.PutUint64(bytes[16:], uint64(b.count))
+	for i, word := range b.bits {
+		binary.LittleEndian.PutUint64(bytes[24+8*i:], word)
+	}
+	return bytes, nil
+}
+
+func (b *Bloom) UnmarshalBinary(bytes []byte) error {
+	m, k, count, bits, err := unmarshal_bloom(bytes, 64, "Bloom filter")
+	if err != nil {
+		return err
+	}
+	b.m, b.k, b.count, b.bits = m, k, count, bits
+	return nil
+}
+
+// reads the header and words of a marshalled Bloom filter whose m bits or
+// counters are packed per_word to a word. m is checked against the length
+// before it is used so a bad header can not make the words the wrong size.
+func unmarshal_bloom(bytes []byte, per_word uint64, what string) (m uint64, k, count int, words []uint64, err error) {
+	if len(bytes) < 24 {
+		return 0, 0, 0, nil, errors.Errorf("A marshalled %v is at least 24 bytes, got %v", what, len(bytes))
+	}
+	m = binary.LittleEndian.Uint64(bytes[0:])
+	hashes := binary.LittleEndian.Uint64(bytes[8:])
+	n := uint64(len(bytes)-24) / 8
+	if m == 0 || (len(bytes)-24)%8 != 0 || m > per_word*n || (m+per_word-1)/per_word != n {
+		return 0, 0, 0, nil, errors.Errorf("A marshalled %v has a bad size", what)
+	} else if hashes == 0 || hashes > bloom_max_hashes {
+		return 0, 0, 0, nil, errors.Errorf("A marshalled %v has %v hashes, it must have 1 to %v", what, hashes, bloom_max_hashes)
+	}
+	words = make([]uint64, n)
+	for i := range words {
+		words[i] = binary.LittleEndian.Uint64(bytes[24+8*i:])
+	}
+	return m, int(hashes), int(binary.LittleEndian.Uint64(bytes[16:])), words, nil
+}
@@ -0,0 +1,134 @@
+package filter
+
+import (
+	"encoding/binary"
+)
+
+import (
+	"github.com/timtadh/data-structures/errors"
+	"github.com/timtadh/data-structures/types"
+)
+
+/* A counting Bloom filter: a Bloom filter with a 4 bit counter in place of
+ * each bit, so it takes 4 times the space but items can be deleted. A counter
+ * which reaches 15 sticks there (it is never decremented again) so that a
+ * delete can never cause a false negative.
+ *
+ * Only delete items which were added. Deleting an item which was not added
+ * but is a false positive removes it and may cause false negatives for the
+ * items which share its counters.
+ */
+type CountingBloom struct {
+	counters []uint64 // 16 counters a word
+	m        uint64   // the number of counters
+	k        int
+	count    int
+}
+
+const counter_max = 15
+
+// Makes a counting Bloom filter with a false positive rate of p after n
+// items.
+func NewCountingBloom(n int, p float64) *CountingBloom {
+	m, k := bloom_size(n, p)
+	return &CountingBloom{
+		counters: make([]uint64, (m+15)/16),
+		m:        m,
+		k:        k,
+	}
+}
+
+func (b *CountingBloom) get(c uint64) uint64 {
+	return (b.counters[c/16] >> (4 * (c % 16))) & 0xf
+}
+
+func (b *CountingBloom) set(c, v uint64) {
+	shift := 4 * (c % 16)
+	b.counters[c/16] = b.counters[c/16]&^(0xf<<shift) | v<<shift
+}
+
+// The number of items in the filter (adds less deletes).
+func (b *CountingBloom) Count() int {
+	return b.count
+}
+
+// The expected false positive rate with the items in the filter.
+func (b *CountingBloom) FalsePositiveRate() float64 {
+	return bloom_rate(b.m, b.k, b.count)
+}
+
+func (b *CountingBloom) Add(item types.Hashable) {
+	h1, h2 := Hash(item)
+	for i := 0; i < b.k; i++ {
+		c := (h1 + uint64(i)*h2) % b.m
+		if v := b.get(c); v < counter_max {
+			b.set(c, v+1)
+		}
+	}
+	b.count++
+}
+
+// Has the item (probably) been added and not deleted?
+func (b *CountingBloom) Has(item types.Hashable) bool {
+	h1, h2 := Hash(item)
+	for i := 0; i < b.k; i++ {
+		if b.get((h1+uint64(i)*h2)%b.m) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Deletes an item. It is an error if the item is not in the filter.
+func (b *CountingBloom) Delete(item types.Hashable) error {
+	if !b.Has(item) {
+		return errors.NotFound(item)
+	}
+	h1, h2 := Hash(item)
+	for i := 0; i < b.k; i++ {
+		c := (h1 + uint64(i)*h2) % b.m
+		if v := b.get(c); v < counter_max {
+			b.set(c, v-1)
+		}
+	}
+	b.count--
+	return nil
+}
+
+// Adds the items of o to b. They must have been made with the same n and p.
+func (b *CountingBloom) Merge(o *CountingBloom) error {
+	if b.m != o.m || b.k != o.k {
+		return errors.Errorf("Can not merge counting Bloom filters of different sizes")
+	}
+	for c := uint64(0); c < b.m; c++ {
+		v := b.get(c) + o.get(c)
+		if v > counter_max {
+			v = counter_max
+		}
+		b.set(c, v)
+	}
+	b.count += o.count
+	return nil
+}
+
+// The number of counters, the number of hashes, the count and then the
+// counters, little endian.
+func (b *CountingBloom) MarshalBinary() ([]byte, error) {
+	bytes := make([]byte, 24+8*len(b.counters))
+	binary.LittleEndian.PutUint64(bytes[0:], b.m)
+	binary.LittleEndian.PutUint64(bytes[8:], uint64(b.k))
+	binary.LittleEndian.PutUint64(bytes[16:], uint64(b.count))
+	for i, word := range b.counters {
+		binary.LittleEndian.PutUint64(bytes[24+8*i:], word)
+	}
+	return bytes, nil
+}
+
+func (b *CountingBloom) UnmarshalBinary(bytes []byte) error {
+	m, k, count, counters, err := unmarshal_bloom(bytes, 16, "counting Bloom filter")
+	if err != nil {
+		return err
+	}
+	b.m, b.k, b.count, b.counters = m, k, count, counters
+	return nil
+}
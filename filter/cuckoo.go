@@ -0,0 +1,240 @@
+package filter
+
+import (
+	"encoding/binary"
+	"math"
+	"math/rand"
+)
+
+import (
+	"github.com/timtadh/data-structures/errors"
+	"github.com/timtadh/data-structures/types"
+)
+
+/* A cuckoo filter (Fan, Andersen, Kaminsky and Mitzenmacher). It keeps a
+ * short fingerprint of each item in one of two buckets of 4 slots. The second
+ * bucket is found from the first and the fingerprint, so a full bucket can
+ * move a fingerprint to its other bucket to make room. Unlike a Bloom filter
+ * items can be deleted, and for false positive rates below about 3% it takes
+ * less space.
+ *
+ * Adding an item twice stores it twice (so it must be deleted twice) and
+ * Add returns an error once the filter is too full to make room.
+ */
+type Cuckoo struct {
+	slots  []uint16 // cuckoo_bucket a bucket, 0 is empty
+	mask   uint64   // the number of buckets - 1
+	fpbits uint
+	count  int
+	// a fingerprint which could not be placed by the last failed Add
+	victim       bool
+	victim_index uint64
+	victim_fp    uint16
+}
+
+const cuckoo_bucket = 4
+const cuckoo_max_kicks = 500
+
+// Makes a cuckoo filter for n items with a false positive rate of about p.
+// The fingerprints are between 4 and 16 bits so p is at least about 0.0001.
+func NewCuckoo(n int, p float64) *Cuckoo {
+	if p <= 0 || p >= 1 {
+		panic(errors.Errorf("The false positive rate must be in (0, 1), got %v", p))
+	}
+	fpbits := uint(math.Ceil(math.Log2(2 * cuckoo_bucket / p)))
+	if fpbits < 4 {
+		fpbits = 4
+	} else if fpbits > 16 {
+		fpbits = 16
+	}
+	// the table fills to about 95% before inserts fail
+	buckets := uint64(1)
+	for float64(buckets*cuckoo_bucket)*0.95 < float64(n) {
+		buckets *= 2
+	}
+	return &Cuckoo{
+		slots:  make([]uint16, buckets*cuckoo_bucket),
+		mask:   buckets - 1,
+		fpbits: fpbits,
+	}
+}
+
+// the fingerprint and the two buckets of an item
+func (f *Cuckoo) locate(item types.Hashable) (fp uint16, i1, i2 uint64) {
+	h1, _ := Hash(item)
+	fp = uint16((h1 >> 32) & (1<<f.fpbits - 1))
+	if fp == 0 {
+		fp = 1
+	}
+	i1 = h1 & f.mask
+	return fp, i1, f.alt(i1, fp)
+}
+
+// the other bucket of fingerprint fp in bucket i
+func (f *Cuckoo) alt(i uint64, fp uint16) uint64 {
+	return (i ^ mix(uint64(fp))) & f.mask
+}
+
+func (f *Cuckoo) bucket(i uint64) []uint16 {
+	return f.slots[i*cuckoo_bucket : (i+1)*cuckoo_bucket]
+}
+
+func (f *Cuckoo) put(i uint64, fp uint16) bool {
+	b := f.bucket(i)
+	for j := range b {
+		if b[j] == 0 {
+			b[j] = fp
+			return true
+		}
+	}
+	return false
+}
+
+func (f *Cuckoo) has(i uint64, fp uint16) bool {
+	for _, x := range f.bucket(i) {
+		if x == fp {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *Cuckoo) remove(i uint64, fp uint16) bool {
+	b := f.bucket(i)
+	for j := range b {
+		if b[j] == fp {
+			b[j] = 0
+			return true
+		}
+	}
+	return false
+}
+
+// The number of items in the filter (adds less deletes).
+func (f *Cuckoo) Count() int {
+	return f.count
+}
+
+// The fraction of the slots in use.
+func (f *Cuckoo) LoadFactor() float64 {
+	return float64(f.count) / float64(len(f.slots))
+}
+
+// The expected false positive rate with the items in the filter: a lookup
+// compares against the 8 slots of two buckets.
+func (f *Cuckoo) FalsePositiveRate() float64 {
+	full := 2 * cuckoo_bucket * f.LoadFactor()
+	return 1 - math.Pow(1-1/float64(uint(1)<<f.fpbits-1), full)
+}
+
+// Adds an item. When the filter is too full the item is still added but
+// later Adds fail until an item is deleted.
+func (f *Cuckoo) Add(item types.Hashable) error {
+	if f.victim {
+		return errors.Errorf("Cuckoo filter is full")
+	}
+	fp, i1, _ := f.locate(item)
+	f.count++
+	f.insert(i1, fp)
+	return nil
+}
+
+// Puts fp in bucket i or its other bucket, moving fingerprints to their other
+// buckets to make room. If there is no room the fingerprint left over becomes
+// the victim.
+func (f *Cuckoo) insert(i uint64, fp uint16) {
+	i2 := f.alt(i, fp)
+	if f.put(i, fp) || f.put(i2, fp) {
+		return
+	}
+	if rand.Intn(2) == 0 {
+		i = i2
+	}
+	for kick := 0; kick < cuckoo_max_kicks; kick++ {
+		j := rand.Intn(cuckoo_bucket)
+		b := f.bucket(i)
+		fp, b[j] = b[j], fp
+		i = f.alt(i, fp)
+		if f.put(i, fp) {
+			return
+		}
+	}
+	f.victim, f.victim_index, f.victim_fp = true, i, fp
+}
+
+// Has the item (probably) been added and not deleted?
+func (f *Cuckoo) Has(item types.Hashable) bool {
+	fp, i1, i2 := f.locate(item)
+	if f.has(i1, fp) || f.has(i2, fp) {
+		return true
+	}
+	return f.victim && f.victim_fp == fp && (f.victim_index == i1 || f.victim_index == i2)
+}
+
+// Deletes (one copy of) an item. It is an error if the item is not in the
+// filter. Only delete items which were added: deleting a false positive
+// removes some other item.
+func (f *Cuckoo) Delete(item types.Hashable) error {
+	fp, i1, i2 := f.locate(item)
+	if f.remove(i1, fp) || f.remove(i2, fp) {
+		f.count--
+		if f.victim {
+			// there may be room for it now
+			f.victim = false
+			f.insert(f.victim_index, f.victim_fp)
+		}
+		return nil
+	} else if f.victim && f.victim_fp == fp && (f.victim_index == i1 || f.victim_index == i2) {
+		f.victim = false
+		f.count--
+		return nil
+	}
+	return errors.NotFound(item)
+}
+
+// The fingerprint bits, the number of buckets, the count, the victim and
+// then the slots, little endian.
+func (f *Cuckoo) MarshalBinary() ([]byte, error) {
+	bytes := make([]byte, 28+2*len(f.slots))
+	bytes[0] = byte(f.fpbits)
+	if f.victim {
+		bytes[1] = 1
+	}
+	binary.LittleEndian.PutUint16(bytes[2:], f.victim_fp)
+	binary.LittleEndian.PutUint64(bytes[4:], f.mask+1)
+	binary.LittleEndian.PutUint64(bytes[12:], uint64(f.count))
+	binary.LittleEndian.PutUint64(bytes[20:], f.victim_index)
+	for i, fp := range f.slots {
+		binary.LittleEndian.PutUint16(bytes[28+2*i:], fp)
+	}
+	return bytes, nil
+}
+
+func (f *Cuckoo) UnmarshalBinary(bytes []byte) error {
+	if len(bytes) < 28 {
+		return errors.Errorf("A marshalled cuckoo filter is at least 28 bytes, got %v", len(bytes))
+	}
+	buckets := binary.LittleEndian.Uint64(bytes[4:])
+	fpbits := uint(bytes[0])
+	if fpbits < 4 || fpbits > 16 || buckets == 0 || buckets&(buckets-1) != 0 ||
+		(len(bytes)-28)%(2*cuckoo_bucket) != 0 || buckets != uint64(len(bytes)-28)/(2*cuckoo_bucket) {
+		return errors.Errorf("A marshalled cuckoo filter has a bad size")
+	}
+	victim := bytes[1] == 1
+	victim_fp := binary.LittleEndian.Uint16(bytes[2:])
+	victim_index := binary.LittleEndian.Uint64(bytes[20:])
+	if victim && (victim_fp == 0 || uint(victim_fp) >= 1<<fpbits || victim_index >= buckets) {
+		return errors.Errorf("A marshalled cuckoo filter has a bad victim %v in bucket %v", victim_fp, victim_index)
+	}
+	f.fpbits = fpbits
+	f.victim = victim
+	f.victim_fp = victim_fp
+	f.mask = buckets - 1
+	f.count = int(binary.LittleEndian.Uint64(bytes[12:]))
+	f.victim_index = victim_index
+	f.slots = make([]uint16, buckets*cuckoo_bucket)
+	for i := range f.slots {
+		f.slots[i] = binary.LittleEndian.Uint16(bytes[28+2*i:])
+	}
+	return nil
+}
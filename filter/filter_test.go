@@ -0,0 +1,288 @@
+package filter
+
+import (
+	"encoding/binary"
+	"fmt"
+	"testing"
+)
+
+import (
+	"github.com/timtadh/data-structures/types"
+)
+
+const N = 10000
+
+func key(i int) types.Hashable {
+	return types.String(fmt.Sprintf("key-%d", i))
+}
+
+type filter interface {
+	Has(types.Hashable) bool
+	FalsePositiveRate() float64
+}
+
+// No false negatives for the added keys [0, N) and about rate false positives
+// for the keys [N, 11N).
+func check(t *testing.T, f filter, rate float64) {
+	for i := 0; i < N; i++ {
+		if !f.Has(key(i)) {
+			t.Fatalf("false negative for %v", key(i))
+		}
+	}
+	fp := 0
+	for i := N; i < 11*N; i++ {
+		if f.Has(key(i)) {
+			fp++
+		}
+	}
+	observed := float64(fp) / (10 * N)
+	if observed > 2*rate {
+		t.Fatalf("false positive rate %v, expected about %v", observed, rate)
+	}
+	if est := f.FalsePositiveRate(); est > 2*rate {
+		t.Fatalf("estimated false positive rate %v, expected about %v", est, rate)
+	}
+}
+
+func TestHash(t *testing.T) {
+	a1, a2 := Hash(types.String("a"))
+	b1, b2 := Hash(types.ByteSlice("a"))
+	c1, _ := Hash(types.String("b"))
+	if a1 != b1 || a2 != b2 {
+		t.Fatal("a String and a ByteSlice with the same bytes hash differently")
+	}
+	if a1 == c1 || a1 == a2 || a2%2 != 1 {
+		t.Fatal("bad hashes")
+	}
+	i1, _ := Hash(types.Int(1))
+	j1, _ := Hash(types.Int(2))
+	if i1 == j1 {
+		t.Fatal("ints collide")
+	}
+}
+
+func TestBloom(t *testing.T) {
+	for _, rate := range []float64{0.1, 0.01, 0.001} {
+		b := NewBloom(N, rate)
+		for i := 0; i < N; i++ {
+			b.Add(key(i))
+		}
+		check(t, b, rate)
+		bytes, err := b.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		u := new(Bloom)
+		if err := u.UnmarshalBinary(bytes); err != nil {
+			t.Fatal(err)
+		}
+		check(t, u, rate)
+		if u.UnmarshalBinary(bytes[:len(bytes)-8]) == nil {
+			t.Fatal("unmarshalled a truncated filter")
+		}
+	}
+}
+
+func TestBloomMerge(t *testing.T) {
+	a := NewBloom(N, 0.01)
+	b := NewBloom(N, 0.01)
+	for i := 0; i < N; i++ {
+		if i%2 == 0 {
+			a.Add(key(i))
+		} else {
+			b.Add(key(i))
+		}
+	}
+	if err := a.Merge(b); err != nil {
+		t.Fatal(err)
+	}
+	check(t, a, 0.01)
+	if a.Count() != N {
+		t.Fatalf("count is %v", a.Count())
+	}
+	if a.Merge(NewBloom(N, 0.1)) == nil {
+		t.Fatal("merged filters of different sizes")
+	}
+}
+
+func TestCountingBloom(t *testing.T) {
+	b := NewCountingBloom(N, 0.01)
+	for i := 0; i < 2*N; i++ {
+		b.Add(key(i))
+	}
+	for i := N; i < 2*N; i++ {
+		if err := b.Delete(key(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if b.Count() != N {
+		t.Fatalf("count is %v", b.Count())
+	}
+	check(t, b, 0.01)
+	if b.Delete(types.String("never added")) == nil && !b.Has(types.String("never added")) {
+		t.Fatal("deleted an item which is not in the filter")
+	}
+	bytes, err := b.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	u := new(CountingBloom)
+	if err := u.UnmarshalBinary(bytes); err != nil {
+		t.Fatal(err)
+	}
+	check(t, u, 0.01)
+	o := NewCountingBloom(N, 0.01)
+	o.Add(key(N))
+	if err := u.Merge(o); err != nil {
+		t.Fatal(err)
+	} else if !u.Has(key(N)) {
+		t.Fatal("merge lost an item")
+	}
+}
+
+func TestBloomUnmarshalBad(t *testing.T) {
+	header := func(m, k uint64, words int) []byte {
+		bytes := make([]byte, 24+8*words)
+		binary.LittleEndian.PutUint64(bytes[0:], m)
+		binary.LittleEndian.PutUint64(bytes[8:], k)
+		return bytes
+	}
+	for _, u := range []interface{ UnmarshalBinary([]byte) error }{new(Bloom), new(CountingBloom)} {
+		// (m+63)/64 overflows to 0
+		if u.UnmarshalBinary(header(1<<64-1, 3, 0)) == nil {
+			t.Fatalf("%T: unmarshalled a filter whose size overflows", u)
+		}
+		if u.UnmarshalBinary(header(1<<40, 3, 1)) == nil {
+			t.Fatalf("%T: unmarshalled a filter bigger than its words", u)
+		}
+		if u.UnmarshalBinary(header(8, 0, 1)) == nil {
+			t.Fatalf("%T: unmarshalled a filter with no hashes", u)
+		}
+		if u.UnmarshalBinary(header(8, 1<<63, 1)) == nil {
+			t.Fatalf("%T: unmarshalled a filter with too many hashes", u)
+		}
+		if u.UnmarshalBinary(append(header(8, 3, 1), 0)) == nil {
+			t.Fatalf("%T: unmarshalled a filter with a partial word", u)
+		}
+		if err := u.UnmarshalBinary(header(8, 3, 1)); err != nil {
+			t.Fatalf("%T: %v", u, err)
+		}
+	}
+	if NewBloom(10, 1e-30).k != bloom_max_hashes {
+		t.Fatal("a tiny false positive rate was not capped at the most hashes")
+	}
+}
+
+func TestCountingBloomSaturates(t *testing.T) {
+	b := NewCountingBloom(10, 0.01)
+	for i := 0; i < 100; i++ {
+		b.Add(key(0))
+	}
+	b.Add(key(1))
+	for i := 0; i < 100; i++ {
+		b.Delete(key(0))
+	}
+	if !b.Has(key(1)) {
+		t.Fatal("deletes of a saturated item caused a false negative")
+	}
+}
+
+func TestCuckoo(t *testing.T) {
+	for _, rate := range []float64{0.01, 0.001} {
+		f := NewCuckoo(N, rate)
+		for i := 0; i < 2*N; i++ {
+			if i < N || i%2 == 0 {
+				if err := f.Add(key(i)); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+		for i := N; i < 2*N; i += 2 {
+			if err := f.Delete(key(i)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if f.Count() != N {
+			t.Fatalf("count is %v", f.Count())
+		}
+		check(t, f, rate)
+		bytes, err := f.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		u := new(Cuckoo)
+		if err := u.UnmarshalBinary(bytes); err != nil {
+			t.Fatal(err)
+		}
+		check(t, u, rate)
+		if u.UnmarshalBinary(bytes[:len(bytes)-2]) == nil {
+			t.Fatal("unmarshalled a truncated filter")
+		}
+	}
+}
+
+func TestCuckooFull(t *testing.T) {
+	f := NewCuckoo(100, 0.01)
+	added := 0
+	for ; added < 1000; added++ {
+		if err := f.Add(key(added)); err != nil {
+			break
+		}
+	}
+	if added == 1000 || f.LoadFactor() < 0.8 {
+		t.Fatalf("filled to %v after %v items", f.LoadFactor(), added)
+	}
+	for i := 0; i < added; i++ {
+		if !f.Has(key(i)) {
+			t.Fatalf("false negative for %v", key(i))
+		}
+	}
+	// deleting makes room for the victim (it will almost always find it)
+	if err := f.Delete(key(0)); err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i < added; i++ {
+		if !f.Has(key(i)) {
+			t.Fatalf("false negative for %v", key(i))
+		}
+	}
+	full := f.victim
+	if err := f.Add(key(0)); (err != nil) != full {
+		t.Fatalf("add after delete returned %v while full is %v", err, full)
+	}
+}
+
+func TestCuckooUnmarshalBad(t *testing.T) {
+	f := NewCuckoo(10, 0.01)
+	f.Add(key(0))
+	good, err := f.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	victim := func(fp uint16, index uint64) []byte {
+		bytes := append([]byte{}, good...)
+		bytes[1] = 1
+		binary.LittleEndian.PutUint16(bytes[2:], fp)
+		binary.LittleEndian.PutUint64(bytes[20:], index)
+		return bytes
+	}
+	u := new(Cuckoo)
+	if err := u.UnmarshalBinary(victim(1, 1)); err != nil {
+		t.Fatal(err)
+	}
+	if u.UnmarshalBinary(victim(1, 1<<40)) == nil {
+		t.Fatal("unmarshalled a victim past the last bucket")
+	}
+	if u.UnmarshalBinary(victim(0, 1)) == nil {
+		t.Fatal("unmarshalled an empty victim")
+	}
+	if u.UnmarshalBinary(victim(1<<f.fpbits, 1)) == nil {
+		t.Fatal("unmarshalled a victim wider than the fingerprints")
+	}
+	// 2*4*buckets overflows to 0
+	header := append([]byte{}, good[:28]...)
+	binary.LittleEndian.PutUint64(header[4:], 1<<61)
+	if u.UnmarshalBinary(header) == nil {
+		t.Fatal("unmarshalled a filter whose size overflows")
+	}
+}
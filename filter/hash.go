@@ -0,0 +1,70 @@
+/*
+Package filter has probabilistic sets: a Bloom filter, a counting Bloom filter
+which supports delete and a cuckoo filter. They answer "has this item been
+added?" in a few bits per item, at the cost of a tunable rate of false
+positives (Has is true for an item which was never added). There are no
+false negatives. Each is made for an expected number of items and a false
+positive rate and implements MarshalBinary and UnmarshalBinary so it can be
+saved and loaded.
+
+The filters take any types.Hashable. An item is hashed with Hash below.
+*/
+package filter
+
+import (
+	"hash/fnv"
+)
+
+import (
+	"github.com/timtadh/data-structures/types"
+)
+
+// An item with a 64 bit hash. Filters over very many items should use one,
+// as the int from types.Hashable.Hash is often 32 bits and collisions of the
+// hash are false positives of the filter.
+type Hashable64 interface {
+	types.Hashable
+	Hash64() uint64
+}
+
+// Hashes an item for a filter. h1 is the primary hash and h2 the secondary
+// one, which is odd. The k probes of an item are h1 + i*h2 (Kirsch and
+// Mitzenmacher's double hashing).
+//
+// Hash64 is used for a Hashable64, the bytes for types.String and
+// types.ByteSlice and the value for the integer types. Anything else falls
+// back to Hash. h2 is a different mix of the same 64 bits as h1.
+func Hash(item types.Hashable) (h1, h2 uint64) {
+	var h uint64
+	switch x := item.(type) {
+	case Hashable64:
+		h = x.Hash64()
+	case types.String:
+		f := fnv.New64a()
+		f.Write([]byte(x))
+		h = f.Sum64()
+	case types.ByteSlice:
+		f := fnv.New64a()
+		f.Write([]byte(x))
+		h = f.Sum64()
+	case types.Int:
+		h = uint64(x)
+	case types.Int64:
+		h = uint64(x)
+	case types.UInt64:
+		h = uint64(x)
+	default:
+		h = uint64(item.Hash())
+	}
+	return mix(h), mix(h^0x9e3779b97f4a7c15) | 1
+}
+
+// the splitmix64 finalizer
+func mix(h uint64) uint64 {
+	h ^= h >> 30
+	h *= 0xbf58476d1ce4e5b9
+	h ^= h >> 27
+	h *= 0x94d049bb133111eb
+	h ^= h >> 31
+	return h
+}
@@ -0,0 +1,23 @@
+package hashtable
+
+import (
+	"testing"
+)
+
+import (
+	"github.com/timtadh/data-structures/test"
+)
+
+func FuzzHashTable(f *testing.F) {
+	test.AddSeeds(f)
+	f.Fuzz(func(t *testing.T, ops []byte) {
+		test.CheckMap(t, NewHashTable(4), ops, test.StringKey)
+	})
+}
+
+func FuzzLinearHash(f *testing.F) {
+	test.AddSeeds(f)
+	f.Fuzz(func(t *testing.T, ops []byte) {
+		test.CheckMap(t, NewLinearHash(), ops, test.StringKey)
+	})
+}
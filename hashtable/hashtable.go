@@ -22,6 +22,15 @@ func abs(x int) int {
 	}
 }
 
+func clamp(x, lo, hi int) int {
+	if x < lo {
+		return lo
+	} else if x > hi {
+		return hi
+	}
+	return x
+}
+
 func (self *entry) Put(key Hashable, value interface{}) (e *entry, appended bool) {
 	if self == nil {
 		return &entry{key, value, nil}, true
@@ -123,8 +132,20 @@ func (self *Hash) Remove(key Hashable) (value interface{}, err error) {
 }
 
 func (self *Hash) Iterate() KVIterator {
-	table := self.table
-	i := -1
+	return self.IterateBuckets(0, len(self.table))
+}
+
+// The number of buckets in the table.
+func (self *Hash) Buckets() int { return len(self.table) }
+
+// Iterates over the entries in the buckets [from, to). Iterating over
+// disjoint bucket ranges visits every entry once, so the ranges can be
+// iterated at the same time (while the table is not changed).
+func (self *Hash) IterateBuckets(from, to int) KVIterator {
+	from = clamp(from, 0, len(self.table))
+	to = clamp(to, from, len(self.table))
+	table := self.table[:to]
+	i := from - 1
 	var e *entry
 	var kv_iterator KVIterator
 	kv_iterator = func() (key Hashable, val interface{}, next KVIterator) {
@@ -304,3 +304,26 @@ func BenchmarkMLHashBetter(b *testing.B) {
 		}
 	}
 }
+
+func TestIterateBuckets(t *testing.T) {
+	table := NewHashTable(64)
+	for i := 0; i < 1000; i++ {
+		table.Put(Int(i), i)
+	}
+	seen := make(map[int]bool)
+	n := table.Buckets()
+	for from := -5; from < n; from += n / 7 {
+		for k, v, next := table.IterateBuckets(from, from+n/7)(); next != nil; k, v, next = next() {
+			if seen[v.(int)] || int(k.(Int)) != v.(int) {
+				t.Fatal("bad entry or entry seen twice", k, v)
+			}
+			seen[v.(int)] = true
+		}
+	}
+	if len(seen) != 1000 {
+		t.Error("the bucket ranges missed entries", len(seen))
+	}
+	if _, _, next := table.IterateBuckets(10, 5)(); next != nil {
+		t.Fatal("a backward bucket range was not empty")
+	}
+}
@@ -48,6 +48,9 @@ func (u *UniquePQ) Peek() interface{} {
 
 // Get and remove the top element
 func (u *UniquePQ) Pop() interface{} {
+	if u.pq.Size() == 0 {
+		return nil
+	}
 	item := u.pq.Pop().(types.Hashable)
 	u.set.Delete(item)
 	return item
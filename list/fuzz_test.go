@@ -0,0 +1,16 @@
+package list
+
+import (
+	"testing"
+)
+
+import (
+	"github.com/timtadh/data-structures/test"
+)
+
+func FuzzList(f *testing.F) {
+	test.AddSeeds(f)
+	f.Fuzz(func(t *testing.T, ops []byte) {
+		test.CheckList(t, New(1), ops, test.IntKey)
+	})
+}
@@ -140,6 +140,16 @@ func (s *Sorted) Remove(i int) (err error) {
 }
 
 func (s *Sorted) Add(item types.Hashable) (err error) {
+	// an item after the last one is appended without a search so building
+	// a list from sorted items is linear
+	if s.Size() > 0 {
+		last, err := s.list.Get(s.Size() - 1)
+		if err != nil {
+			return err
+		} else if last.Less(item) {
+			return s.list.Append(item)
+		}
+	}
 	i, has, err := s.Find(item)
 	if err != nil {
 		return err
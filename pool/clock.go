@@ -0,0 +1,125 @@
+package pool
+
+import (
+	"sync"
+	"time"
+)
+
+/* The time source of a Scheduler. RealClock uses the time package and
+ * ManualClock is moved by hand, for tests.
+ */
+type Clock interface {
+	Now() time.Time
+	// A timer which fires at (or as soon as possible after) at.
+	NewTimer(at time.Time) Timer
+}
+
+type Timer interface {
+	C() <-chan time.Time
+	// Stops the timer. It returns false if the timer already fired.
+	Stop() bool
+}
+
+type RealClock struct{}
+
+func (RealClock) Now() time.Time {
+	return time.Now()
+}
+
+func (RealClock) NewTimer(at time.Time) Timer {
+	return real_timer{time.NewTimer(time.Until(at))}
+}
+
+type real_timer struct {
+	t *time.Timer
+}
+
+func (t real_timer) C() <-chan time.Time {
+	return t.t.C
+}
+
+func (t real_timer) Stop() bool {
+	return t.t.Stop()
+}
+
+/* A Clock which only moves when it is told to. Timers fire when the clock is
+ * moved to or past them, on the goroutine which moved it.
+ */
+type ManualClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*manual_timer
+}
+
+func NewManualClock(now time.Time) *ManualClock {
+	return &ManualClock{now: now}
+}
+
+func (c *ManualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *ManualClock) NewTimer(at time.Time) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &manual_timer{clock: c, at: at, c: make(chan time.Time, 1)}
+	if !at.After(c.now) {
+		t.c <- c.now
+	} else {
+		c.timers = append(c.timers, t)
+	}
+	return t
+}
+
+// Moves the clock forward by d, firing the timers it passes.
+func (c *ManualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	timers := c.timers[:0]
+	for _, t := range c.timers {
+		if !t.at.After(c.now) {
+			t.c <- c.now
+		} else {
+			timers = append(timers, t)
+		}
+	}
+	for i := len(timers); i < len(c.timers); i++ {
+		c.timers[i] = nil
+	}
+	c.timers = timers
+}
+
+// The number of timers waiting to fire.
+func (c *ManualClock) Timers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
+
+type manual_timer struct {
+	clock *ManualClock
+	at    time.Time
+	c     chan time.Time
+}
+
+func (t *manual_timer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *manual_timer) Stop() bool {
+	c := t.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, u := range c.timers {
+		if u == t {
+			copy(c.timers[i:], c.timers[i+1:])
+			c.timers[len(c.timers)-1] = nil
+			c.timers = c.timers[:len(c.timers)-1]
+			return true
+		}
+	}
+	return false
+}
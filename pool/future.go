@@ -0,0 +1,135 @@
+package pool
+
+import (
+	"context"
+	"sync"
+)
+
+// The result of a task given to Submit.
+type Future struct {
+	done  chan struct{}
+	value interface{}
+	err   error
+}
+
+func (f *Future) finish(value interface{}, err error) {
+	f.value = value
+	f.err = err
+	close(f.done)
+}
+
+// Closed when the task has finished (or was never run).
+func (f *Future) Done() <-chan struct{} {
+	return f.done
+}
+
+// Waits for the task to finish and returns its error.
+func (f *Future) Wait() error {
+	<-f.done
+	return f.err
+}
+
+// Waits for the task to finish and returns what it returned.
+func (f *Future) Result() (interface{}, error) {
+	<-f.done
+	return f.value, f.err
+}
+
+// Runs fn on the pool with ctx. If ctx is done before a worker gets to fn, fn
+// is not run and the Future has the context's error. If the pool is stopped
+// the Future has the error from Do. Waiting for room in the queue stops when
+// ctx is done. If fn panics the Future has the panic as an exc.Throwable.
+func (p *Pool) Submit(ctx context.Context, fn func(context.Context) (interface{}, error)) *Future {
+	f := &Future{done: make(chan struct{})}
+	if err := ctx.Err(); err != nil {
+		f.finish(nil, err)
+		return f
+	}
+	err := p.do(ctx, func() {
+		if err := ctx.Err(); err != nil {
+			f.finish(nil, err)
+			return
+		}
+		defer func() {
+			if r := recover(); r != nil {
+				t := as_throwable(r)
+				f.finish(nil, t)
+				// the worker recovers it again and counts it
+				panic(t)
+			}
+		}()
+		f.finish(fn(ctx))
+	})
+	if err != nil {
+		f.finish(nil, err)
+	}
+	return f
+}
+
+/* A group of tasks run on a pool which fail together: the first task to return
+ * an error cancels the context given to the rest and is the error Wait
+ * returns. Tasks which have not started by then are not run.
+ */
+type Group struct {
+	pool    *Pool
+	ctx     context.Context
+	cancel  context.CancelFunc
+	futures []*Future
+	mu      sync.Mutex
+	once    sync.Once
+	err     error
+}
+
+// Makes a Group whose tasks get a context derived from ctx. The context is
+// returned as well and is cancelled on the first error or when Wait returns.
+func (p *Pool) Group(ctx context.Context) (*Group, context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	return &Group{pool: p, ctx: ctx, cancel: cancel}, ctx
+}
+
+// Runs fn on the pool as part of the group. If fn panics the panic fails the
+// group as an exc.Throwable.
+func (g *Group) Go(fn func(context.Context) error) {
+	f := g.pool.Submit(g.ctx, func(ctx context.Context) (interface{}, error) {
+		defer func() {
+			if r := recover(); r != nil {
+				t := as_throwable(r)
+				g.fail(t)
+				// for the Future and the worker's counts
+				panic(t)
+			}
+		}()
+		err := fn(ctx)
+		if err != nil {
+			g.fail(err)
+		}
+		return nil, err
+	})
+	g.mu.Lock()
+	g.futures = append(g.futures, f)
+	g.mu.Unlock()
+}
+
+func (g *Group) fail(err error) {
+	g.once.Do(func() {
+		g.err = err
+		g.cancel()
+	})
+}
+
+// Waits for every task in the group and returns the first error.
+func (g *Group) Wait() error {
+	g.mu.Lock()
+	futures := g.futures
+	g.futures = nil
+	g.mu.Unlock()
+	for _, f := range futures {
+		if err := f.Wait(); err != nil {
+			// a task which never ran because the pool was stopped or the
+			// parent context was done is a failure too
+			g.fail(err)
+		}
+	}
+	g.cancel()
+	return g.err
+}
@@ -0,0 +1,162 @@
+package pool
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+import (
+	"github.com/timtadh/data-structures/exc"
+)
+
+// What the hooks are told about a task.
+type TaskInfo struct {
+	Id       uint64
+	Queued   time.Time
+	Started  time.Time
+	Finished time.Time     // zero in the Start hook
+	Panic    exc.Throwable // what the task panicked with, if it did
+}
+
+/* Funcs the workers call before and after each task, on the worker's
+ * goroutine. Either may be nil. They should be quick as the worker waits for
+ * them, and they must not panic.
+ */
+type Hooks struct {
+	Start  func(*TaskInfo)
+	Finish func(*TaskInfo)
+}
+
+// Sets the hooks called for the tasks which start from now on.
+func (p *Pool) SetHooks(h Hooks) {
+	p.hooks.Store(h)
+}
+
+// The number of buckets in a Histogram.
+const HistogramBuckets = 32
+
+/* A histogram of durations. Bucket 0 counts the durations under 1
+ * microsecond and bucket i > 0 those in [2^(i-1), 2^i) microseconds. The last
+ * bucket also counts everything longer.
+ */
+type Histogram struct {
+	Counts [HistogramBuckets]uint64
+}
+
+func histogram_bucket(d time.Duration) int {
+	us := uint64(d / time.Microsecond)
+	i := 0
+	for us > 0 && i < HistogramBuckets-1 {
+		us >>= 1
+		i++
+	}
+	return i
+}
+
+// The upper bound of the durations in bucket i.
+func HistogramBound(i int) time.Duration {
+	if i >= HistogramBuckets-1 {
+		return time.Duration(1<<63 - 1)
+	}
+	return time.Duration(1<<uint(i)) * time.Microsecond
+}
+
+// The number of durations counted.
+func (h *Histogram) Count() uint64 {
+	var n uint64
+	for _, c := range h.Counts {
+		n += c
+	}
+	return n
+}
+
+// An upper bound on the q quantile (in [0, 1]) of the durations: the bound of
+// the bucket it is in. It is 0 for an empty histogram.
+func (h *Histogram) Quantile(q float64) time.Duration {
+	n := h.Count()
+	if n == 0 {
+		return 0
+	}
+	rank := uint64(q * float64(n))
+	if rank >= n {
+		rank = n - 1
+	}
+	var seen uint64
+	for i, c := range h.Counts {
+		seen += c
+		if seen > rank {
+			return HistogramBound(i)
+		}
+	}
+	return HistogramBound(HistogramBuckets - 1)
+}
+
+func (h *Histogram) String() string {
+	return fmt.Sprintf("n=%v p50<%v p99<%v", h.Count(), h.Quantile(.5), h.Quantile(.99))
+}
+
+/* A snapshot of the counters of a pool, from Pool.Stats. Completed counts
+ * every task which finished, the ones which panicked included. Waiting is how
+ * long the tasks were in the queue and Running how long they ran.
+ */
+type Stats struct {
+	Queued    int
+	Running   int
+	Completed uint64
+	Panicked  uint64
+	Waiting   Histogram
+	Runtime   Histogram
+}
+
+// the counters, updated atomically
+type metrics struct {
+	completed uint64
+	panicked  uint64
+	running   int64
+	waiting   [HistogramBuckets]uint64
+	runtime   [HistogramBuckets]uint64
+}
+
+func (m *metrics) start(info *TaskInfo) {
+	atomic.AddInt64(&m.running, 1)
+	atomic.AddUint64(&m.waiting[histogram_bucket(info.Started.Sub(info.Queued))], 1)
+}
+
+func (m *metrics) finish(info *TaskInfo) {
+	atomic.AddUint64(&m.runtime[histogram_bucket(info.Finished.Sub(info.Started))], 1)
+	if info.Panic != nil {
+		atomic.AddUint64(&m.panicked, 1)
+	}
+	atomic.AddUint64(&m.completed, 1)
+	atomic.AddInt64(&m.running, -1)
+}
+
+// The current counters. They are read one at a time so a snapshot taken while
+// tasks run may be a little inconsistent.
+func (p *Pool) Stats() Stats {
+	m := p.metrics
+	s := Stats{
+		Queued:    p.queue.Size(),
+		Running:   int(atomic.LoadInt64(&m.running)),
+		Completed: atomic.LoadUint64(&m.completed),
+		Panicked:  atomic.LoadUint64(&m.panicked),
+	}
+	for i := 0; i < HistogramBuckets; i++ {
+		s.Waiting.Counts[i] = atomic.LoadUint64(&m.waiting[i])
+		s.Runtime.Counts[i] = atomic.LoadUint64(&m.runtime[i])
+	}
+	return s
+}
+
+// turns a recovered panic into a Throwable
+func as_throwable(r interface{}) exc.Throwable {
+	switch e := r.(type) {
+	case exc.Throwable:
+		return e
+	case error:
+		return exc.FromError(e).Exception()
+	default:
+		return exc.Errorf("task panicked: %v", r).Exception()
+	}
+}
@@ -0,0 +1,156 @@
+/* Package parallel runs bulk operations over a collection on a pool.Pool.
+ *
+ * The collection is given as partitions, iterators over disjoint parts of it,
+ * made by List, BpTreeRange or HashBuckets (or by hand). Each partition is a
+ * task on the pool. The results of the partitions are merged in partition
+ * order so they do not depend on the order the tasks ran in. A partition which
+ * fails stops at its first error. The other partitions still run and the
+ * errors are returned together as Errors.
+ *
+ * The collection must not be changed while an operation is running.
+ */
+package parallel
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+import (
+	"github.com/timtadh/data-structures/list"
+	"github.com/timtadh/data-structures/pool"
+	"github.com/timtadh/data-structures/types"
+)
+
+// The error of one partition.
+type PartitionError struct {
+	Partition int
+	Err       error
+}
+
+func (e *PartitionError) Error() string {
+	return fmt.Sprintf("partition %v: %v", e.Partition, e.Err)
+}
+
+// The errors of the partitions which failed, in partition order.
+type Errors []*PartitionError
+
+func (e Errors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Runs fn on every item.
+func ForEach(ctx context.Context, p *pool.Pool, parts []types.KIterator, fn func(types.Hashable) error) error {
+	return run(ctx, p, parts, func(i int, item types.Hashable) error {
+		return fn(item)
+	})
+}
+
+// Returns a list of fn of every item, in partition order.
+func Map(ctx context.Context, p *pool.Pool, parts []types.KIterator, fn func(types.Hashable) (types.Hashable, error)) (*list.List, error) {
+	results := make([][]types.Hashable, len(parts))
+	err := run(ctx, p, parts, func(i int, item types.Hashable) error {
+		mapped, err := fn(item)
+		if err != nil {
+			return err
+		}
+		results[i] = append(results[i], mapped)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return concat(results), nil
+}
+
+// Returns a list of the items keep is true for, in partition order.
+func Filter(ctx context.Context, p *pool.Pool, parts []types.KIterator, keep func(types.Hashable) (bool, error)) (*list.List, error) {
+	results := make([][]types.Hashable, len(parts))
+	err := run(ctx, p, parts, func(i int, item types.Hashable) error {
+		ok, err := keep(item)
+		if err != nil {
+			return err
+		} else if ok {
+			results[i] = append(results[i], item)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return concat(results), nil
+}
+
+// Folds each partition with fn starting from zero, then combines the results
+// of the partitions from first to last. zero is shared by the partitions so
+// it should not be changed by fn.
+func Reduce(ctx context.Context, p *pool.Pool, parts []types.KIterator, zero interface{}, fn func(acc interface{}, item types.Hashable) (interface{}, error), combine func(a, b interface{}) interface{}) (interface{}, error) {
+	results := make([]interface{}, len(parts))
+	for i := range results {
+		results[i] = zero
+	}
+	err := run(ctx, p, parts, func(i int, item types.Hashable) (err error) {
+		results[i], err = fn(results[i], item)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return zero, nil
+	}
+	acc := results[0]
+	for _, r := range results[1:] {
+		acc = combine(acc, r)
+	}
+	return acc, nil
+}
+
+// runs each partition as a task calling fn with the partition's index and
+// each of its items
+func run(ctx context.Context, p *pool.Pool, parts []types.KIterator, fn func(i int, item types.Hashable) error) error {
+	futures := make([]*pool.Future, 0, len(parts))
+	for i, part := range parts {
+		i, part := i, part
+		futures = append(futures, p.Submit(ctx, func(ctx context.Context) (interface{}, error) {
+			for item, next := part(); next != nil; item, next = next() {
+				if err := ctx.Err(); err != nil {
+					return nil, err
+				}
+				if err := fn(i, item); err != nil {
+					return nil, err
+				}
+			}
+			return nil, nil
+		}))
+	}
+	var errs Errors
+	for i, f := range futures {
+		if err := f.Wait(); err != nil {
+			errs = append(errs, &PartitionError{Partition: i, Err: err})
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func concat(results [][]types.Hashable) *list.List {
+	size := 0
+	for _, r := range results {
+		size += len(r)
+	}
+	l := list.New(size)
+	for _, r := range results {
+		for _, item := range r {
+			l.Append(item)
+		}
+	}
+	return l
+}
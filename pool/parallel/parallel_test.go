@@ -0,0 +1,200 @@
+package parallel
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+)
+
+import (
+	"github.com/timtadh/data-structures/hashtable"
+	"github.com/timtadh/data-structures/list"
+	"github.com/timtadh/data-structures/pool"
+	"github.com/timtadh/data-structures/tree/bptree"
+	"github.com/timtadh/data-structures/types"
+)
+
+func ints(n int) *list.List {
+	l := list.New(n)
+	for i := 0; i < n; i++ {
+		l.Append(types.Int(i))
+	}
+	return l
+}
+
+func TestListPartitions(t *testing.T) {
+	for _, size := range []int{0, 1, 7, 100} {
+		for _, n := range []int{0, 1, 3, 8, 200} {
+			parts := List(ints(size), n)
+			if len(parts) > n && len(parts) > 1 {
+				t.Fatalf("%v partitions for %v", len(parts), n)
+			}
+			i := 0
+			for _, part := range parts {
+				for item, next := part(); next != nil; item, next = next() {
+					if int(item.(types.Int)) != i {
+						t.Fatalf("got %v want %v", item, i)
+					}
+					i++
+				}
+			}
+			if i != size {
+				t.Fatalf("the partitions of %v items have %v", size, i)
+			}
+		}
+	}
+}
+
+func TestMap(t *testing.T) {
+	p := pool.New(4)
+	defer p.Stop()
+	l := ints(1000)
+	for _, n := range []int{1, 4, 16} {
+		squares, err := Map(context.Background(), p, List(l, n), func(item types.Hashable) (types.Hashable, error) {
+			i := item.(types.Int)
+			return i * i, nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if squares.Size() != 1000 {
+			t.Fatalf("size is %v", squares.Size())
+		}
+		for i := 0; i < 1000; i++ {
+			if sq, _ := squares.Get(i); sq.(types.Int) != types.Int(i*i) {
+				t.Fatalf("squares[%v] is %v", i, sq)
+			}
+		}
+	}
+}
+
+func TestFilterAndReduce(t *testing.T) {
+	p := pool.New(4)
+	defer p.Stop()
+	parts := func() []types.KIterator { return List(ints(1000), 7) }
+	evens, err := Filter(context.Background(), p, parts(), func(item types.Hashable) (bool, error) {
+		return item.(types.Int)%2 == 0, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < evens.Size(); i++ {
+		if e, _ := evens.Get(i); e.(types.Int) != types.Int(2*i) {
+			t.Fatalf("evens[%v] is %v", i, e)
+		}
+	}
+	sum, err := Reduce(context.Background(), p, parts(), 0,
+		func(acc interface{}, item types.Hashable) (interface{}, error) {
+			return acc.(int) + int(item.(types.Int)), nil
+		},
+		func(a, b interface{}) interface{} { return a.(int) + b.(int) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum.(int) != 499500 {
+		t.Fatalf("sum is %v", sum)
+	}
+	// combine sees the partitions in order
+	order, err := Reduce(context.Background(), p, List(ints(8), 4), "",
+		func(acc interface{}, item types.Hashable) (interface{}, error) {
+			return fmt.Sprintf("%v%v", acc, item), nil
+		},
+		func(a, b interface{}) interface{} { return a.(string) + b.(string) })
+	if err != nil || order.(string) != "01234567" {
+		t.Fatalf("reduce gave %v, %v", order, err)
+	}
+}
+
+func TestErrorsPerPartition(t *testing.T) {
+	p := pool.New(4)
+	defer p.Stop()
+	var seen int64
+	err := ForEach(context.Background(), p, List(ints(100), 10), func(item types.Hashable) error {
+		atomic.AddInt64(&seen, 1)
+		if i := item.(types.Int); i == 15 || i == 75 {
+			return fmt.Errorf("bad item %v", i)
+		}
+		return nil
+	})
+	errs, ok := err.(Errors)
+	if !ok || len(errs) != 2 || errs[0].Partition != 1 || errs[1].Partition != 7 {
+		t.Fatalf("wrong errors %v", err)
+	}
+	// the failing partitions stop after their bad items
+	if seen != 100-4-4 {
+		t.Fatalf("%v items were seen", seen)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Map(ctx, p, List(ints(100), 4), func(item types.Hashable) (types.Hashable, error) {
+		return item, nil
+	}); err == nil || len(err.(Errors)) != 4 {
+		t.Fatalf("a cancelled map returned %v", err)
+	}
+}
+
+func TestBpTreeRange(t *testing.T) {
+	p := pool.New(4)
+	defer p.Stop()
+	bpt := bptree.NewBpTree(8)
+	for i := 0; i < 2000; i++ {
+		bpt.Add(types.Int(i/2), i)
+	}
+	for _, n := range []int{1, 3, 8, 100} {
+		parts := BpTreeRange(bpt, types.Int(100), types.Int(899), n)
+		if n > 1 && len(parts) < 2 {
+			t.Fatalf("the range was not split into %v parts", n)
+		}
+		keys, err := Map(context.Background(), p, parts, func(item types.Hashable) (types.Hashable, error) {
+			return item.(*types.MapEntry).Key, nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if keys.Size() != 1600 {
+			t.Fatalf("%v entries in the range", keys.Size())
+		}
+		for i := 0; i < keys.Size(); i++ {
+			if k, _ := keys.Get(i); k.(types.Int) != types.Int(100+i/2) {
+				t.Fatalf("keys[%v] is %v", i, k)
+			}
+		}
+	}
+	if parts := BpTreeRange(bpt, types.Int(10), types.Int(5), 4); len(parts) != 0 {
+		t.Fatal("a backward range has partitions")
+	}
+}
+
+func TestHashBuckets(t *testing.T) {
+	p := pool.New(4)
+	defer p.Stop()
+	h := hashtable.NewHashTable(16)
+	for i := 0; i < 1000; i++ {
+		h.Put(types.Int(i), i)
+	}
+	total, err := Reduce(context.Background(), p, HashBuckets(h, 8), 0,
+		func(acc interface{}, item types.Hashable) (interface{}, error) {
+			return acc.(int) + item.(*types.MapEntry).Value.(int), nil
+		},
+		func(a, b interface{}) interface{} { return a.(int) + b.(int) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total.(int) != 499500 {
+		t.Fatalf("total is %v", total)
+	}
+	// the result does not depend on the scheduling
+	keys := func() *list.List {
+		l, err := Map(context.Background(), p, HashBuckets(h, 8), func(item types.Hashable) (types.Hashable, error) {
+			return item.(*types.MapEntry).Key, nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return l
+	}
+	if a, b := keys(), keys(); !a.Equals(b) {
+		t.Fatal("the merged results differ between runs")
+	}
+}
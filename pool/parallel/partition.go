@@ -0,0 +1,109 @@
+package parallel
+
+import (
+	"github.com/timtadh/data-structures/hashtable"
+	"github.com/timtadh/data-structures/list"
+	"github.com/timtadh/data-structures/tree/bptree"
+	"github.com/timtadh/data-structures/types"
+)
+
+// Splits the list into at most n runs of about the same length.
+func List(l *list.List, n int) []types.KIterator {
+	parts := make([]types.KIterator, 0, n)
+	for _, r := range ranges(l.Size(), n) {
+		parts = append(parts, list_range(l, r[0], r[1]))
+	}
+	return parts
+}
+
+func list_range(l *list.List, from, to int) (it types.KIterator) {
+	i := from
+	it = func() (item types.Hashable, next types.KIterator) {
+		if i >= to {
+			return nil, nil
+		}
+		item, err := l.Get(i)
+		if err != nil {
+			// the list was changed
+			return nil, nil
+		}
+		i++
+		return item, it
+	}
+	return it
+}
+
+// Splits the entries of the tree with keys in [from, to] into at most n
+// parts, using bptree.SplitKeys. The items are *types.MapEntry.
+func BpTreeRange(t *bptree.BpTree, from, to types.Hashable, n int) []types.KIterator {
+	if to.Less(from) {
+		return nil
+	}
+	keys := t.SplitKeys(from, to, n)
+	bounds := make([]types.Hashable, 0, len(keys)+2)
+	bounds = append(bounds, from)
+	bounds = append(bounds, keys...)
+	bounds = append(bounds, to)
+	parts := make([]types.KIterator, 0, len(bounds)-1)
+	for i := 1; i < len(bounds); i++ {
+		parts = append(parts, tree_range(t, bounds[i-1], bounds[i], i == len(bounds)-1))
+	}
+	return parts
+}
+
+// the entries in [from, to), or [from, to] when last
+func tree_range(t *bptree.BpTree, from, to types.Hashable, last bool) (it types.KIterator) {
+	var kvi types.KVIterator
+	done := false
+	it = func() (item types.Hashable, next types.KIterator) {
+		if done {
+			return nil, nil
+		} else if kvi == nil {
+			// the range is found by the worker which iterates it
+			kvi = t.Range(from, to)
+		}
+		var key types.Hashable
+		var value interface{}
+		key, value, kvi = kvi()
+		if kvi == nil || (!last && !key.Less(to)) {
+			done = true
+			return nil, nil
+		}
+		return &types.MapEntry{Key: key, Value: value}, it
+	}
+	return it
+}
+
+// Splits the buckets of the table into at most n runs of about the same
+// number of buckets. The items are *types.MapEntry.
+func HashBuckets(h *hashtable.Hash, n int) []types.KIterator {
+	parts := make([]types.KIterator, 0, n)
+	for _, r := range ranges(h.Buckets(), n) {
+		parts = append(parts, types.MakeItemsIterator(buckets{h, r[0], r[1]}))
+	}
+	return parts
+}
+
+type buckets struct {
+	h        *hashtable.Hash
+	from, to int
+}
+
+func (b buckets) Iterate() types.KVIterator {
+	return b.h.IterateBuckets(b.from, b.to)
+}
+
+// splits [0, size) into at most n ranges [from, to) of about the same length
+func ranges(size, n int) [][2]int {
+	if n < 1 {
+		n = 1
+	}
+	if n > size {
+		n = size
+	}
+	rs := make([][2]int, 0, n)
+	for i := 0; i < n; i++ {
+		rs = append(rs, [2]int{i * size / n, (i + 1) * size / n})
+	}
+	return rs
+}
@@ -1,38 +1,107 @@
 package pool
 
 import (
+	"context"
 	"sync"
-	"math/rand"
+	"sync/atomic"
+	"time"
 )
 
 import (
 	"github.com/timtadh/data-structures/errors"
+	"github.com/timtadh/data-structures/types"
 )
 
+/* A pool of workers which run the funcs given to Do. The tasks wait in one
+ * queue shared by the workers, so an idle worker always picks up the next
+ * task and a slow task only holds up its own worker. The queue is bounded:
+ * Do waits for room and TryDo fails when there is none. The number of
+ * workers can be changed with Resize.
+ *
+ * A task which panics is recovered by its worker. The panic is counted in
+ * Stats and given to the Finish hook as an exc.Throwable.
+ */
 type Pool struct {
-	workers []*worker
-	wg      sync.WaitGroup
-	workin  int
+	queue    Queue
+	workers  []*worker
+	wmu      sync.Mutex // guards workers
+	wg       sync.WaitGroup
+	workin   int
 	workCond *sync.Cond
-	mu      sync.RWMutex
+	mu       sync.RWMutex
+	stopped  bool
+	hooks    atomic.Value // Hooks
+	metrics  *metrics
 }
 
+// The queue capacity per worker of New.
+const QueuePerWorker = 100
+
+// Makes a pool of n workers whose queue holds QueuePerWorker tasks a worker.
+// Like the other constructors it panics if n < 1.
 func New(n int) *Pool {
+	return NewWithCapacity(n, QueuePerWorker*n)
+}
+
+// Makes a pool of n workers whose queue holds capacity tasks.
+func NewWithCapacity(n, capacity int) *Pool {
+	return NewWithQueue(n, NewBoundedQueue(capacity))
+}
+
+// Makes a pool of n workers which take their tasks from q. The pool closes q
+// when it is stopped. It panics if n < 1.
+func NewWithQueue(n int, q Queue) *Pool {
+	if n < 1 {
+		panic(errors.Errorf("A pool needs at least 1 worker, got %v", n))
+	}
 	pool := &Pool{
-		workers: make([]*worker, 0, n),
+		queue:    q,
 		workCond: sync.NewCond(&sync.Mutex{}),
+		metrics:  &metrics{},
 	}
-	for i := 0; i < n; i++ {
-		w := &worker{
-			in: make(chan func(), 100),
-			wg: &pool.wg,
-			workin: &pool.workin,
-			workCond: pool.workCond,
-		}
+	pool.hooks.Store(Hooks{})
+	pool.Resize(n)
+	return pool
+}
+
+// The number of tasks waiting in the queue.
+func (p *Pool) Queued() int {
+	return p.queue.Size()
+}
+
+// The number of workers.
+func (p *Pool) Size() int {
+	p.wmu.Lock()
+	defer p.wmu.Unlock()
+	return len(p.workers)
+}
+
+// Changes the number of workers to n (at least 1). Removed workers finish
+// the task they are running first. It is an error to resize a stopped pool.
+func (p *Pool) Resize(n int) error {
+	if n < 1 {
+		return errors.Errorf("A pool needs at least 1 worker, got %v", n)
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.stopped {
+		return errors.Errorf("The pool was stopped")
+	}
+	p.wmu.Lock()
+	defer p.wmu.Unlock()
+	for len(p.workers) < n {
+		ctx, cancel := context.WithCancel(context.Background())
+		w := &worker{pool: p, ctx: ctx, cancel: cancel}
+		p.wg.Add(1)
 		go w.work()
-		pool.workers = append(pool.workers, w)
+		p.workers = append(p.workers, w)
 	}
-	return pool
+	for len(p.workers) > n {
+		p.workers[len(p.workers)-1].cancel()
+		p.workers[len(p.workers)-1] = nil
+		p.workers = p.workers[:len(p.workers)-1]
+	}
+	return nil
 }
 
 func (p *Pool) WaitCount() int {
@@ -61,60 +130,143 @@ func (p *Pool) Unlock() {
 	p.mu.Unlock()
 }
 
+// Stops the pool after the work already given to it is done.
 func (p *Pool) Stop() {
+	p.Shutdown(context.Background())
+}
+
+// Stops the pool from taking more work and waits for the work already given
+// to it to finish. If ctx is done first Shutdown returns its error and the
+// workers finish the work in the background.
+func (p *Pool) Shutdown(ctx context.Context) error {
 	p.mu.Lock()
-	if len(p.workers) == 0 {
-		p.mu.Unlock()
-		return
-	}
-	workers := p.workers
-	p.workers = nil
+	p.stopped = true
 	p.mu.Unlock()
-	for _, wrkr := range workers {
-		close(wrkr.in)
+	// the workers drain the closed queue and then exit
+	p.queue.Close()
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
-	p.wg.Wait()
 }
 
-// This will only return an error if the pool is stopped
+// This will only return an error if the pool is stopped. It waits for room
+// in the queue.
 func (p *Pool) Do(f func()) error {
+	return p.do(context.Background(), f)
+}
+
+// Like Do but it returns an error rather than waiting when the queue is full.
+func (p *Pool) TryDo(f func()) error {
+	return p.put(f, func(t *task) error {
+		if !p.queue.TryPut(t) {
+			return errors.Errorf("The pool's queue is full")
+		}
+		return nil
+	})
+}
+
+// Like Do but it stops waiting for room when ctx is done.
+func (p *Pool) do(ctx context.Context, f func()) error {
+	return p.put(f, func(t *task) error {
+		return p.queue.PutCtx(ctx, t)
+	})
+}
+
+func (p *Pool) put(f func(), put func(*task) error) error {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
-	if len(p.workers) <= 0 {
+	if p.stopped {
 		return errors.Errorf("The pool was stopped")
 	}
 	p.workCond.L.Lock()
 	p.workin += 1
 	p.workCond.L.Unlock()
-	offset := rand.Intn(len(p.workers))
-	for i := 0; i < len(p.workers); i++ {
-		j := (offset + i) % len(p.workers)
-		wrkr := p.workers[j].in
-		select {
-		case wrkr<-f:
-			return nil
-		default:
-		}
+	t := &task{id: atomic.AddUint64(&task_ids, 1), f: f, queued: time.Now()}
+	if err := put(t); err != nil {
+		p.finished()
+		return err
 	}
-	p.workers[offset].in<-f
 	return nil
 }
 
+func (p *Pool) finished() {
+	p.workCond.L.Lock()
+	p.workin -= 1
+	p.workCond.L.Unlock()
+	p.workCond.Broadcast()
+}
+
+var task_ids uint64
+
+// A func in the queue. Queues hold types.Hashable so each task gets an id.
+type task struct {
+	id     uint64
+	f      func()
+	queued time.Time
+}
+
+func (t *task) Equals(o types.Equatable) bool {
+	if u, ok := o.(*task); ok {
+		return t.id == u.id
+	}
+	return false
+}
+
+func (t *task) Less(o types.Sortable) bool {
+	if u, ok := o.(*task); ok {
+		return t.id < u.id
+	}
+	return false
+}
+
+func (t *task) Hash() int {
+	return int(t.id)
+}
+
 type worker struct {
-	in chan func()
-	wg *sync.WaitGroup
-	workin *int
-	workCond *sync.Cond
+	pool   *Pool
+	ctx    context.Context // cancelled when Resize removes the worker
+	cancel context.CancelFunc
 }
 
 func (w *worker) work() {
-	w.wg.Add(1)
-	for f := range w.in {
-		f()
-		w.workCond.L.Lock()
-		*w.workin -= 1
-		w.workCond.L.Unlock()
-		w.workCond.Broadcast()
+	defer w.pool.wg.Done()
+	for w.ctx.Err() == nil {
+		item, err := w.pool.queue.TakeCtx(w.ctx)
+		if err != nil {
+			// removed by Resize or the queue was closed and is empty
+			return
+		}
+		w.run(item.(*task))
+	}
+}
+
+func (w *worker) run(t *task) {
+	p := w.pool
+	hooks := p.hooks.Load().(Hooks)
+	info := &TaskInfo{Id: t.id, Queued: t.queued, Started: time.Now()}
+	p.metrics.start(info)
+	if hooks.Start != nil {
+		hooks.Start(info)
 	}
-	w.wg.Done()
+	defer func() {
+		if r := recover(); r != nil {
+			info.Panic = as_throwable(r)
+		}
+		info.Finished = time.Now()
+		p.metrics.finish(info)
+		if hooks.Finish != nil {
+			hooks.Finish(info)
+		}
+		p.finished()
+	}()
+	t.f()
 }
@@ -0,0 +1,431 @@
+package pool
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/timtadh/data-structures/errors"
+	"github.com/timtadh/data-structures/exc"
+)
+
+func TestSubmit(t *testing.T) {
+	p := New(4)
+	defer p.Stop()
+	futures := make([]*Future, 100)
+	for i := range futures {
+		i := i
+		futures[i] = p.Submit(context.Background(), func(ctx context.Context) (interface{}, error) {
+			if i%10 == 0 {
+				return nil, fmt.Errorf("task %v failed", i)
+			}
+			return i * i, nil
+		})
+	}
+	for i, f := range futures {
+		v, err := f.Result()
+		if i%10 == 0 {
+			if err == nil {
+				t.Fatalf("task %v did not fail", i)
+			}
+		} else if err != nil || v.(int) != i*i {
+			t.Fatalf("task %v returned %v, %v", i, v, err)
+		}
+	}
+}
+
+func TestSubmitCancelled(t *testing.T) {
+	p := New(1)
+	defer p.Stop()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	ran := false
+	f := p.Submit(ctx, func(ctx context.Context) (interface{}, error) {
+		ran = true
+		return nil, nil
+	})
+	if err := f.Wait(); err != context.Canceled || ran {
+		t.Fatalf("a task with a cancelled context ran or returned %v", err)
+	}
+	// a task cancelled while it waits in the queue does not run either
+	block := make(chan struct{})
+	p.Submit(context.Background(), func(ctx context.Context) (interface{}, error) {
+		<-block
+		return nil, nil
+	})
+	ctx, cancel = context.WithCancel(context.Background())
+	f = p.Submit(ctx, func(ctx context.Context) (interface{}, error) {
+		ran = true
+		return nil, nil
+	})
+	cancel()
+	close(block)
+	if err := f.Wait(); err != context.Canceled || ran {
+		t.Fatalf("a cancelled task ran or returned %v", err)
+	}
+}
+
+func TestSubmitStopped(t *testing.T) {
+	p := New(2)
+	p.Stop()
+	f := p.Submit(context.Background(), func(ctx context.Context) (interface{}, error) {
+		return 1, nil
+	})
+	select {
+	case <-f.Done():
+	default:
+		t.Fatal("the future of a stopped pool is not done")
+	}
+	if err := f.Wait(); err == nil {
+		t.Fatal("submit to a stopped pool did not fail")
+	}
+}
+
+func TestGroup(t *testing.T) {
+	p := New(4)
+	defer p.Stop()
+	g, _ := p.Group(context.Background())
+	var sum int64
+	for i := 0; i < 100; i++ {
+		i := int64(i)
+		g.Go(func(ctx context.Context) error {
+			atomic.AddInt64(&sum, i)
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	if sum != 4950 {
+		t.Fatalf("sum is %v", sum)
+	}
+}
+
+func TestGroupCancelsOnError(t *testing.T) {
+	p := New(4)
+	defer p.Stop()
+	g, ctx := p.Group(context.Background())
+	failure := errors.Errorf("failed")
+	g.Go(func(ctx context.Context) error {
+		return failure
+	})
+	// the siblings only finish when they are cancelled (or are not run)
+	for i := 0; i < 10; i++ {
+		g.Go(func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+	}
+	if err := g.Wait(); err != failure {
+		t.Fatalf("wait returned %v", err)
+	}
+	if ctx.Err() == nil {
+		t.Fatal("the group context was not cancelled")
+	}
+}
+
+func TestGroupCancelsOnPanic(t *testing.T) {
+	p := New(2)
+	defer p.Stop()
+	g, ctx := p.Group(context.Background())
+	// queued before the panic, it only finishes when the group is cancelled
+	g.Go(func(ctx context.Context) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(5 * time.Second):
+			return errors.Errorf("the panic did not cancel the group")
+		}
+	})
+	g.Go(func(ctx context.Context) error {
+		panic("oops")
+	})
+	err := g.Wait()
+	if _, ok := err.(exc.Throwable); !ok {
+		t.Fatalf("wait returned %T %v", err, err)
+	}
+	if ctx.Err() == nil {
+		t.Fatal("the group context was not cancelled")
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	p := New(2)
+	var done int64
+	for i := 0; i < 10; i++ {
+		p.Do(func() {
+			time.Sleep(time.Millisecond)
+			atomic.AddInt64(&done, 1)
+		})
+	}
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if done != 10 {
+		t.Fatalf("shutdown returned before the work was done: %v", done)
+	}
+	if err := p.Do(func() {}); err == nil {
+		t.Fatal("do after shutdown did not fail")
+	}
+}
+
+func TestShutdownDeadline(t *testing.T) {
+	p := New(1)
+	block := make(chan struct{})
+	p.Do(func() { <-block })
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := p.Shutdown(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("shutdown returned %v", err)
+	}
+	close(block)
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTryDoFull(t *testing.T) {
+	p := NewWithCapacity(1, 2)
+	defer p.Stop()
+	started := make(chan struct{})
+	block := make(chan struct{})
+	p.Do(func() {
+		close(started)
+		<-block
+	})
+	<-started
+	for i := 0; i < 2; i++ {
+		if err := p.TryDo(func() {}); err != nil {
+			t.Fatalf("try do %v failed with room in the queue: %v", i, err)
+		}
+	}
+	if err := p.TryDo(func() {}); err == nil {
+		t.Fatal("try do did not fail on a full queue")
+	}
+	if p.Queued() != 2 {
+		t.Fatalf("queued is %v", p.Queued())
+	}
+	close(block)
+	p.WaitLock()
+	p.Unlock()
+	if err := p.TryDo(func() {}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSubmitBackpressure(t *testing.T) {
+	p := NewWithCapacity(1, 1)
+	defer p.Stop()
+	block := make(chan struct{})
+	defer close(block)
+	p.Do(func() { <-block })
+	// the second Do waits until the worker takes the first, then fills the queue
+	p.Do(func() {})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	f := p.Submit(ctx, func(ctx context.Context) (interface{}, error) { return nil, nil })
+	if err := f.Wait(); err != context.DeadlineExceeded {
+		t.Fatalf("submit to a full queue returned %v", err)
+	}
+}
+
+func TestNoStallBehindSlowTask(t *testing.T) {
+	p := New(2)
+	defer p.Stop()
+	block := make(chan struct{})
+	defer close(block)
+	p.Do(func() { <-block })
+	var done int64
+	for i := 0; i < 100; i++ {
+		p.Do(func() { atomic.AddInt64(&done, 1) })
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for atomic.LoadInt64(&done) != 100 {
+		if time.Now().After(deadline) {
+			t.Fatalf("only %v of the tasks ran beside a slow one", atomic.LoadInt64(&done))
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestResize(t *testing.T) {
+	p := New(1)
+	if err := p.Resize(0); err == nil {
+		t.Fatal("resize to 0 workers did not fail")
+	}
+	if err := p.Resize(4); err != nil {
+		t.Fatal(err)
+	}
+	if p.Size() != 4 {
+		t.Fatalf("size is %v", p.Size())
+	}
+	// all 4 workers run at once
+	var running int64
+	all := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		p.Do(func() {
+			if atomic.AddInt64(&running, 1) == 4 {
+				close(all)
+			}
+			<-all
+		})
+	}
+	select {
+	case <-all:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the added workers did not run")
+	}
+	if err := p.Resize(1); err != nil {
+		t.Fatal(err)
+	}
+	if p.Size() != 1 {
+		t.Fatalf("size is %v", p.Size())
+	}
+	var done int64
+	for i := 0; i < 10; i++ {
+		p.Do(func() { atomic.AddInt64(&done, 1) })
+	}
+	p.Stop()
+	if done != 10 {
+		t.Fatalf("%v of the tasks ran after shrinking the pool", done)
+	}
+	if err := p.Resize(2); err == nil {
+		t.Fatal("resize of a stopped pool did not fail")
+	}
+}
+
+func TestPanicRecovered(t *testing.T) {
+	p := New(2)
+	defer p.Stop()
+	var finished []*TaskInfo
+	var mu sync.Mutex
+	p.SetHooks(Hooks{
+		Finish: func(info *TaskInfo) {
+			mu.Lock()
+			finished = append(finished, info)
+			mu.Unlock()
+		},
+	})
+	p.Do(func() { panic("oops") })
+	p.Do(func() { exc.Throwf("thrown") })
+	p.WaitLock()
+	p.Unlock()
+	if s := p.Stats(); s.Panicked != 2 || s.Completed != 2 || s.Running != 0 {
+		t.Fatalf("wrong stats %+v", s)
+	}
+	for _, info := range finished {
+		if info.Panic == nil {
+			t.Fatalf("task %v did not report its panic", info.Id)
+		}
+	}
+	// the workers survived
+	ran := false
+	p.Do(func() { ran = true })
+	p.WaitLock()
+	p.Unlock()
+	if !ran {
+		t.Fatal("the pool did not run a task after a panic")
+	}
+}
+
+func TestSubmitPanic(t *testing.T) {
+	p := New(1)
+	defer p.Stop()
+	f := p.Submit(context.Background(), func(ctx context.Context) (interface{}, error) {
+		panic(fmt.Errorf("bad"))
+	})
+	err := f.Wait()
+	if _, ok := err.(exc.Throwable); !ok {
+		t.Fatalf("the future of a panicking task has %T %v", err, err)
+	}
+	g, _ := p.Group(context.Background())
+	g.Go(func(ctx context.Context) error {
+		var m map[int]int
+		m[1] = 1
+		return nil
+	})
+	if err := g.Wait(); err == nil {
+		t.Fatal("a panicking group task did not fail the group")
+	}
+}
+
+func TestHooksAndStats(t *testing.T) {
+	p := New(1)
+	defer p.Stop()
+	var started, finished int64
+	p.SetHooks(Hooks{
+		Start: func(info *TaskInfo) {
+			if info.Started.Before(info.Queued) || !info.Finished.IsZero() {
+				t.Errorf("bad start info %+v", info)
+			}
+			atomic.AddInt64(&started, 1)
+		},
+		Finish: func(info *TaskInfo) {
+			if info.Finished.Before(info.Started) || info.Panic != nil {
+				t.Errorf("bad finish info %+v", info)
+			}
+			atomic.AddInt64(&finished, 1)
+		},
+	})
+	block := make(chan struct{})
+	running := make(chan struct{})
+	p.Do(func() {
+		close(running)
+		<-block
+	})
+	for i := 0; i < 5; i++ {
+		p.Do(func() { time.Sleep(time.Millisecond) })
+	}
+	<-running
+	if s := p.Stats(); s.Running != 1 || s.Queued != 5 {
+		t.Fatalf("wrong stats while running %+v", s)
+	}
+	close(block)
+	p.WaitLock()
+	p.Unlock()
+	s := p.Stats()
+	if s.Completed != 6 || s.Running != 0 || s.Queued != 0 || started != 6 || finished != 6 {
+		t.Fatalf("wrong stats %+v, %v started, %v finished", s, started, finished)
+	}
+	if s.Runtime.Count() != 6 || s.Waiting.Count() != 6 {
+		t.Fatalf("wrong histograms %v %v", &s.Runtime, &s.Waiting)
+	}
+	if q := s.Runtime.Quantile(.5); q < time.Millisecond {
+		t.Fatalf("the median runtime is under %v", q)
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	var h Histogram
+	if h.Quantile(.5) != 0 {
+		t.Fatal("an empty histogram has a median")
+	}
+	for _, d := range []time.Duration{0, 500 * time.Nanosecond, time.Microsecond, 3 * time.Microsecond, time.Millisecond, time.Hour} {
+		h.Counts[histogram_bucket(d)]++
+		i := histogram_bucket(d)
+		if d >= HistogramBound(i) || (i > 0 && d < HistogramBound(i-1)) {
+			t.Fatalf("%v is in bucket %v", d, i)
+		}
+	}
+	if h.Quantile(0) != time.Microsecond || h.Quantile(1) != HistogramBound(HistogramBuckets-1) {
+		t.Fatalf("wrong quantiles %v", &h)
+	}
+}
+
+func TestNewNoWorkers(t *testing.T) {
+	for _, n := range []int{0, -1} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("New(%v) did not panic", n)
+				}
+			}()
+			New(n)
+		}()
+	}
+}
@@ -0,0 +1,141 @@
+package pool
+
+import (
+	"context"
+	"sync"
+)
+
+import (
+	"github.com/timtadh/data-structures/errors"
+	"github.com/timtadh/data-structures/types"
+)
+
+/* The queue the workers of a Pool take their tasks from. It holds
+ * types.Hashable items so that the general purpose queues in this library can
+ * be used; the pool puts its own task type in it. It must be safe to use from
+ * many goroutines at once.
+ */
+type Queue interface {
+	types.Sized
+	// Puts an item in the queue, waiting for room until ctx is done. It is
+	// an error to put into a closed queue.
+	PutCtx(ctx context.Context, item types.Hashable) error
+	// Puts an item in the queue if there is room right now.
+	TryPut(item types.Hashable) bool
+	// Takes an item from the queue, waiting for one until ctx is done. Once
+	// the queue is closed it returns the items left and then an error.
+	TakeCtx(ctx context.Context) (types.Hashable, error)
+	// Stops the queue taking more items. Blocked puts return an error.
+	Close()
+}
+
+/* The default Queue: a FIFO of at most a fixed number of items in a ring
+ * buffer under a mutex. Waiters wait on a channel which is closed (and
+ * replaced) whenever an item goes in or out, so they can also wait on a
+ * context.
+ */
+type BoundedQueue struct {
+	mu      sync.Mutex
+	items   []types.Hashable
+	head    int
+	size    int
+	closed  bool
+	changed chan struct{}
+}
+
+func NewBoundedQueue(capacity int) *BoundedQueue {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &BoundedQueue{
+		items:   make([]types.Hashable, capacity),
+		changed: make(chan struct{}),
+	}
+}
+
+func (q *BoundedQueue) Size() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.size
+}
+
+func (q *BoundedQueue) Capacity() int {
+	return len(q.items)
+}
+
+// wakes the waiters, q.mu must be held
+func (q *BoundedQueue) signal() {
+	close(q.changed)
+	q.changed = make(chan struct{})
+}
+
+// puts an item if there is room, q.mu must be held
+func (q *BoundedQueue) put(item types.Hashable) bool {
+	if q.size >= len(q.items) {
+		return false
+	}
+	q.items[(q.head+q.size)%len(q.items)] = item
+	q.size++
+	q.signal()
+	return true
+}
+
+func (q *BoundedQueue) PutCtx(ctx context.Context, item types.Hashable) error {
+	for {
+		q.mu.Lock()
+		if q.closed {
+			q.mu.Unlock()
+			return errors.Errorf("The queue is closed")
+		} else if q.put(item) {
+			q.mu.Unlock()
+			return nil
+		}
+		changed := q.changed
+		q.mu.Unlock()
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (q *BoundedQueue) TryPut(item types.Hashable) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return !q.closed && q.put(item)
+}
+
+func (q *BoundedQueue) TakeCtx(ctx context.Context) (types.Hashable, error) {
+	for {
+		q.mu.Lock()
+		if q.size > 0 {
+			item := q.items[q.head]
+			q.items[q.head] = nil
+			q.head = (q.head + 1) % len(q.items)
+			q.size--
+			q.signal()
+			q.mu.Unlock()
+			return item, nil
+		} else if q.closed {
+			q.mu.Unlock()
+			return nil, errors.Errorf("The queue is closed")
+		}
+		changed := q.changed
+		q.mu.Unlock()
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (q *BoundedQueue) Close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if !q.closed {
+		q.closed = true
+		q.signal()
+	}
+}
@@ -0,0 +1,261 @@
+package pool
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+import (
+	"github.com/timtadh/data-structures/errors"
+	"github.com/timtadh/data-structures/heap"
+)
+
+// The priority class of a scheduled job.
+type Priority int
+
+const (
+	Low Priority = iota
+	Normal
+	High
+	priorities
+)
+
+/* A Scheduler runs funcs on a Pool at given times, once or every interval.
+ * Each priority class has a min heap of its jobs keyed by their next run time
+ * (in UnixNano). When jobs of several classes are due together the jobs of
+ * the higher classes are given to the pool first. That is all priority does:
+ * the pool runs its tasks in the order they were given to it, so a due High
+ * job still waits behind the tasks already in the pool's queue.
+ *
+ * Cancelled jobs stay in their heap until they would have run. A periodic job
+ * which falls behind skips the runs it missed. Its runs may overlap if it
+ * runs for longer than its interval.
+ */
+type Scheduler struct {
+	pool    *Pool
+	clock   Clock
+	mu      sync.Mutex
+	heaps   [priorities]*heap.Heap
+	wake    chan struct{}
+	ctx     context.Context // cancelled by Stop
+	cancel  context.CancelFunc
+	stop    chan struct{}
+	done    chan struct{}
+	stopped bool
+}
+
+// A handle on a scheduled func.
+type Job struct {
+	s         *Scheduler
+	f         func()
+	at        time.Time
+	every     time.Duration
+	priority  Priority
+	cancelled bool
+	given     bool // a one off job given to the pool
+}
+
+// Makes a Scheduler which runs its jobs on p. A nil clock is a RealClock.
+// The scheduler does not stop the pool when it is stopped.
+func NewScheduler(p *Pool, clock Clock) *Scheduler {
+	if clock == nil {
+		clock = RealClock{}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Scheduler{
+		pool:   p,
+		clock:  clock,
+		wake:   make(chan struct{}, 1),
+		ctx:    ctx,
+		cancel: cancel,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	for i := range s.heaps {
+		s.heaps[i] = heap.NewMinHeap(16)
+	}
+	go s.run()
+	return s
+}
+
+// Runs f at (or as soon as possible after) at, with Normal priority.
+func (s *Scheduler) Schedule(at time.Time, f func()) *Job {
+	return s.SchedulePriority(at, Normal, f)
+}
+
+// Runs f every interval from now, with Normal priority.
+func (s *Scheduler) Every(interval time.Duration, f func()) *Job {
+	return s.EveryPriority(interval, Normal, f)
+}
+
+func (s *Scheduler) SchedulePriority(at time.Time, priority Priority, f func()) *Job {
+	return s.add(&Job{s: s, f: f, at: at, priority: priority})
+}
+
+// The interval must be positive.
+func (s *Scheduler) EveryPriority(interval time.Duration, priority Priority, f func()) *Job {
+	if interval <= 0 {
+		panic(errors.Errorf("The interval of a periodic job must be positive, got %v", interval))
+	}
+	j := &Job{s: s, f: f, every: interval, priority: priority}
+	j.at = s.clock.Now().Add(interval)
+	return s.add(j)
+}
+
+func (s *Scheduler) add(j *Job) *Job {
+	if j.priority < Low || j.priority > High {
+		panic(errors.Errorf("Unknown priority %v", j.priority))
+	}
+	s.mu.Lock()
+	if s.stopped {
+		j.cancelled = true
+	} else {
+		s.push(j)
+	}
+	s.mu.Unlock()
+	s.signal()
+	return j
+}
+
+// s.mu must be held
+func (s *Scheduler) push(j *Job) {
+	s.heaps[j.priority].Push(int(j.at.UnixNano()), j)
+}
+
+func (s *Scheduler) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// The number of jobs waiting, cancelled ones included.
+func (s *Scheduler) Size() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for _, h := range s.heaps {
+		n += h.Size()
+	}
+	return n
+}
+
+// Stops the scheduler. The jobs which have not been given to the pool are
+// dropped, including any waiting for room in the pool's queue.
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		<-s.done
+		return
+	}
+	s.stopped = true
+	s.mu.Unlock()
+	s.cancel()
+	close(s.stop)
+	<-s.done
+}
+
+func (s *Scheduler) run() {
+	defer close(s.done)
+	for {
+		now := s.clock.Now()
+		s.mu.Lock()
+		due := s.due(now)
+		next, has := s.next()
+		s.mu.Unlock()
+		for _, j := range due {
+			if s.ctx.Err() != nil {
+				break
+			}
+			if err := s.pool.do(s.ctx, j.f); err != nil {
+				// the pool or the scheduler was stopped
+				j.Cancel()
+			}
+		}
+		var timer Timer
+		var fired <-chan time.Time
+		if has {
+			timer = s.clock.NewTimer(next)
+			fired = timer.C()
+		}
+		select {
+		case <-fired:
+		case <-s.wake:
+		case <-s.stop:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		select {
+		case <-s.stop:
+			return
+		default:
+		}
+	}
+}
+
+// pops the jobs due at now, highest priority first, and puts periodic jobs
+// back for their next run. s.mu must be held.
+func (s *Scheduler) due(now time.Time) []*Job {
+	var due []*Job
+	for p := High; p >= Low; p-- {
+		h := s.heaps[p]
+		for h.Size() > 0 {
+			j := h.Peek().(*Job)
+			if j.at.After(now) {
+				break
+			}
+			h.Pop()
+			if j.cancelled {
+				continue
+			}
+			j.given = j.every == 0
+			due = append(due, j)
+		}
+	}
+	for _, j := range due {
+		if j.every > 0 {
+			missed := now.Sub(j.at) / j.every
+			j.at = j.at.Add((missed + 1) * j.every)
+			s.push(j)
+		}
+	}
+	return due
+}
+
+// the earliest run time of a job which is not cancelled. s.mu must be held.
+func (s *Scheduler) next() (at time.Time, has bool) {
+	for _, h := range s.heaps {
+		for h.Size() > 0 && h.Peek().(*Job).cancelled {
+			h.Pop()
+		}
+		if h.Size() > 0 {
+			if j := h.Peek().(*Job); !has || j.at.Before(at) {
+				at, has = j.at, true
+			}
+		}
+	}
+	return at, has
+}
+
+// Stops the job from running again. It returns false if the job was already
+// cancelled or was a one off job which was already given to the pool.
+func (j *Job) Cancel() bool {
+	s := j.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if j.cancelled || j.given {
+		return false
+	}
+	j.cancelled = true
+	return true
+}
+
+// When the job runs next.
+func (j *Job) Next() time.Time {
+	j.s.mu.Lock()
+	defer j.s.mu.Unlock()
+	return j.at
+}
@@ -0,0 +1,228 @@
+package pool
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+var epoch = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// waits for a value from c
+func recv(t *testing.T, c <-chan int) int {
+	select {
+	case i := <-c:
+		return i
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a job to run")
+	}
+	return -1
+}
+
+// waits until the scheduler is waiting on a timer (or has nothing to wait on)
+func settle(t *testing.T, clock *ManualClock, timers int) {
+	deadline := time.Now().Add(5 * time.Second)
+	for clock.Timers() != timers {
+		if time.Now().After(deadline) {
+			t.Fatalf("the scheduler is waiting on %v timers", clock.Timers())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSchedule(t *testing.T) {
+	p := New(2)
+	defer p.Stop()
+	clock := NewManualClock(epoch)
+	s := NewScheduler(p, clock)
+	defer s.Stop()
+	ran := make(chan int, 10)
+	for _, i := range []int{3, 1, 2} {
+		i := i
+		s.Schedule(epoch.Add(time.Duration(i)*time.Second), func() { ran <- i })
+	}
+	settle(t, clock, 1)
+	clock.Advance(1500 * time.Millisecond)
+	if i := recv(t, ran); i != 1 {
+		t.Fatalf("job %v ran first", i)
+	}
+	settle(t, clock, 1)
+	clock.Advance(2 * time.Second)
+	a, b := recv(t, ran), recv(t, ran)
+	if a+b != 5 {
+		t.Fatalf("jobs %v and %v ran", a, b)
+	}
+	// a job in the past runs at once
+	s.Schedule(epoch, func() { ran <- 0 })
+	if i := recv(t, ran); i != 0 {
+		t.Fatalf("job %v ran", i)
+	}
+	if s.Size() != 0 {
+		t.Fatalf("%v jobs left", s.Size())
+	}
+}
+
+func TestSchedulePriority(t *testing.T) {
+	p := New(1)
+	defer p.Stop()
+	clock := NewManualClock(epoch)
+	s := NewScheduler(p, clock)
+	defer s.Stop()
+	// the jobs queue up behind this one, in the order they are given to the pool
+	block := make(chan struct{})
+	p.Do(func() { <-block })
+	var mu sync.Mutex
+	var order []Priority
+	at := epoch.Add(time.Second)
+	for _, pri := range []Priority{Low, High, Normal, Low, High} {
+		pri := pri
+		s.SchedulePriority(at, pri, func() {
+			mu.Lock()
+			order = append(order, pri)
+			mu.Unlock()
+		})
+	}
+	settle(t, clock, 1)
+	clock.Advance(time.Second)
+	deadline := time.Now().Add(5 * time.Second)
+	for p.Stats().Queued != 5 {
+		if time.Now().After(deadline) {
+			t.Fatal("the jobs were not given to the pool")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(block)
+	p.WaitLock()
+	p.Unlock()
+	want := []Priority{High, High, Normal, Low, Low}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("the jobs ran in the order %v", order)
+		}
+	}
+}
+
+func TestStopWithFullPool(t *testing.T) {
+	p := NewWithCapacity(1, 1)
+	clock := NewManualClock(epoch)
+	s := NewScheduler(p, clock)
+	// one task running and one filling the queue
+	block := make(chan struct{})
+	p.Do(func() { <-block })
+	p.Do(func() {})
+	ran := make(chan int, 1)
+	s.Schedule(epoch, func() { ran <- 1 })
+	// the scheduler has taken the job and is waiting for room
+	deadline := time.Now().Add(5 * time.Second)
+	for s.Size() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("the job was not taken")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	stopped := make(chan struct{})
+	go func() {
+		s.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Stop waited for room in the pool's queue")
+	}
+	close(block)
+	p.Stop()
+	select {
+	case <-ran:
+		t.Fatal("the job was given to the pool after Stop")
+	default:
+	}
+}
+
+func TestEvery(t *testing.T) {
+	p := New(2)
+	defer p.Stop()
+	clock := NewManualClock(epoch)
+	s := NewScheduler(p, clock)
+	defer s.Stop()
+	ran := make(chan int, 10)
+	n := 0
+	j := s.Every(time.Second, func() {
+		n++
+		ran <- n
+	})
+	for i := 1; i <= 3; i++ {
+		settle(t, clock, 1)
+		clock.Advance(time.Second)
+		if r := recv(t, ran); r != i {
+			t.Fatalf("run %v was %v", i, r)
+		}
+	}
+	// the missed runs are skipped
+	settle(t, clock, 1)
+	clock.Advance(3500 * time.Millisecond)
+	if r := recv(t, ran); r != 4 {
+		t.Fatalf("run 4 was %v", r)
+	}
+	if next := j.Next(); !next.Equal(epoch.Add(7 * time.Second)) {
+		t.Fatalf("the next run is at %v", next.Sub(epoch))
+	}
+	settle(t, clock, 1)
+	if !j.Cancel() || j.Cancel() {
+		t.Fatal("cancel of a periodic job returned the wrong value")
+	}
+	clock.Advance(10 * time.Second)
+	settle(t, clock, 0)
+	p.WaitLock()
+	p.Unlock()
+	if n != 4 {
+		t.Fatalf("a cancelled job ran %v times", n)
+	}
+}
+
+func TestCancel(t *testing.T) {
+	p := New(1)
+	defer p.Stop()
+	clock := NewManualClock(epoch)
+	s := NewScheduler(p, clock)
+	ran := make(chan int, 10)
+	a := s.Schedule(epoch.Add(time.Second), func() { ran <- 1 })
+	b := s.Schedule(epoch.Add(2*time.Second), func() { ran <- 2 })
+	if !b.Cancel() {
+		t.Fatal("cancel of a waiting job failed")
+	}
+	settle(t, clock, 1)
+	clock.Advance(5 * time.Second)
+	if r := recv(t, ran); r != 1 {
+		t.Fatalf("job %v ran", r)
+	}
+	if a.Cancel() {
+		t.Fatal("cancel of a job which ran succeeded")
+	}
+	settle(t, clock, 0)
+	p.WaitLock()
+	p.Unlock()
+	select {
+	case r := <-ran:
+		t.Fatalf("cancelled job %v ran", r)
+	default:
+	}
+	s.Stop()
+	if j := s.Schedule(epoch, func() { ran <- 3 }); j.Cancel() {
+		t.Fatal("a job of a stopped scheduler was not cancelled")
+	}
+}
+
+func TestRealClock(t *testing.T) {
+	p := New(1)
+	defer p.Stop()
+	s := NewScheduler(p, nil)
+	defer s.Stop()
+	ran := make(chan int, 1)
+	start := time.Now()
+	s.Schedule(start.Add(10*time.Millisecond), func() { ran <- 1 })
+	recv(t, ran)
+	if time.Since(start) < 10*time.Millisecond {
+		t.Fatal("the job ran early")
+	}
+}
@@ -0,0 +1,212 @@
+package queue
+
+import (
+	"context"
+	"sync"
+)
+
+import (
+	"github.com/timtadh/data-structures/linked"
+	"github.com/timtadh/data-structures/types"
+)
+
+/* A linked.LinkedList which many goroutines can use at once, with puts and
+ * takes at either end which wait until they can go on. As a pool.Queue it is
+ * a FIFO: PutCtx puts at the back and TakeCtx takes from the front.
+ */
+type BlockingDeque struct {
+	mu       sync.Mutex
+	list     *linked.LinkedList
+	capacity int
+	closed   bool
+	items    waiters // takers waiting for an item
+	space    waiters // putters waiting for room
+}
+
+// Makes a deque of at most capacity items. If capacity < 1 it is unbounded.
+func NewBlockingDeque(capacity int) *BlockingDeque {
+	return &BlockingDeque{
+		list:     linked.New(),
+		capacity: capacity,
+	}
+}
+
+// The most items the deque holds, 0 if it is unbounded.
+func (d *BlockingDeque) Capacity() int {
+	if d.capacity < 1 {
+		return 0
+	}
+	return d.capacity
+}
+
+func (d *BlockingDeque) Size() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.list.Size()
+}
+
+func (d *BlockingDeque) Closed() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.closed
+}
+
+// Stops the deque taking more items. The items in it can still be taken.
+func (d *BlockingDeque) Close() {
+	d.mu.Lock()
+	d.closed = true
+	d.mu.Unlock()
+	d.items.broadcast()
+	d.space.broadcast()
+}
+
+// whether the deque was closed and whether the item went in
+func (d *BlockingDeque) put(item types.Hashable, front bool) (closed, ok bool) {
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return true, false
+	} else if d.capacity > 0 && d.list.Size() >= d.capacity {
+		d.mu.Unlock()
+		return false, false
+	}
+	if front {
+		d.list.EnqueFront(item)
+	} else {
+		d.list.EnqueBack(item)
+	}
+	d.mu.Unlock()
+	d.items.broadcast()
+	return false, true
+}
+
+// whether the deque was closed and the item taken, if there was one
+func (d *BlockingDeque) take(back bool) (closed bool, item types.Hashable, ok bool) {
+	d.mu.Lock()
+	if d.list.Size() == 0 {
+		closed := d.closed
+		d.mu.Unlock()
+		return closed, nil, false
+	}
+	var err error
+	if back {
+		item, err = d.list.DequeBack()
+	} else {
+		item, err = d.list.DequeFront()
+	}
+	d.mu.Unlock()
+	if err != nil {
+		return false, nil, false
+	}
+	d.space.broadcast()
+	return false, item, true
+}
+
+func (d *BlockingDeque) put_ctx(ctx context.Context, item types.Hashable, front bool) error {
+	for {
+		if closed, ok := d.put(item, front); closed {
+			return closed_error()
+		} else if ok {
+			return nil
+		}
+		changed := d.space.wait()
+		if closed, ok := d.put(item, front); closed || ok {
+			d.space.done()
+			if closed {
+				return closed_error()
+			}
+			return nil
+		}
+		select {
+		case <-changed:
+			d.space.done()
+		case <-ctx.Done():
+			d.space.done()
+			return ctx.Err()
+		}
+	}
+}
+
+func (d *BlockingDeque) take_ctx(ctx context.Context, back bool) (types.Hashable, error) {
+	for {
+		if closed, item, ok := d.take(back); ok {
+			return item, nil
+		} else if closed {
+			return nil, closed_error()
+		}
+		changed := d.items.wait()
+		if closed, item, ok := d.take(back); ok || closed {
+			d.items.done()
+			if closed {
+				return nil, closed_error()
+			}
+			return item, nil
+		}
+		select {
+		case <-changed:
+			d.items.done()
+		case <-ctx.Done():
+			d.items.done()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Puts the item at the back, waiting for room until ctx is done. It is an
+// error to put into a closed deque.
+func (d *BlockingDeque) PutCtx(ctx context.Context, item types.Hashable) error {
+	return d.put_ctx(ctx, item, false)
+}
+
+// Puts the item at the front, waiting for room until ctx is done.
+func (d *BlockingDeque) PutFrontCtx(ctx context.Context, item types.Hashable) error {
+	return d.put_ctx(ctx, item, true)
+}
+
+// Takes the item at the front, waiting for one until ctx is done. Once the
+// deque is closed it returns the items left in it and then an error.
+func (d *BlockingDeque) TakeCtx(ctx context.Context) (types.Hashable, error) {
+	return d.take_ctx(ctx, false)
+}
+
+// Takes the item at the back, waiting for one until ctx is done.
+func (d *BlockingDeque) TakeBackCtx(ctx context.Context) (types.Hashable, error) {
+	return d.take_ctx(ctx, true)
+}
+
+// Puts the item at the back if the deque is open and has room.
+func (d *BlockingDeque) TryPut(item types.Hashable) bool {
+	_, ok := d.put(item, false)
+	return ok
+}
+
+// Puts the item at the front if the deque is open and has room.
+func (d *BlockingDeque) TryPutFront(item types.Hashable) bool {
+	_, ok := d.put(item, true)
+	return ok
+}
+
+// Takes the item at the front if there is one.
+func (d *BlockingDeque) TryTake() (types.Hashable, bool) {
+	_, item, ok := d.take(false)
+	return item, ok
+}
+
+// Takes the item at the back if there is one.
+func (d *BlockingDeque) TryTakeBack() (types.Hashable, bool) {
+	_, item, ok := d.take(true)
+	return item, ok
+}
+
+// Takes the items from the front, until the deque is empty, as they are
+// iterated.
+func (d *BlockingDeque) Drain() (it types.KIterator) {
+	it = func() (types.Hashable, types.KIterator) {
+		_, item, ok := d.take(false)
+		if !ok {
+			return nil, nil
+		}
+		return item, it
+	}
+	return it
+}
@@ -0,0 +1,57 @@
+/* Package queue has queues which are safe to use from many goroutines at once:
+ * Ring, a bounded lock free multi producer multi consumer ring buffer, and
+ * BlockingDeque, a linked.LinkedList behind a mutex. Both block in PutCtx
+ * and TakeCtx until they can go on or the context is done, can be closed,
+ * and satisfy pool.Queue so they can be the task queue of a pool.Pool.
+ */
+package queue
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+import (
+	"github.com/timtadh/data-structures/errors"
+)
+
+func closed_error() error {
+	return errors.Errorf("The queue is closed")
+}
+
+/* The goroutines waiting for a queue to change. A waiter calls wait, checks
+ * the queue again and then waits on the channel. The channel is closed by the
+ * next broadcast after the wait. A broadcast which sees no waiters does
+ * nothing, so a queue with none never takes the lock.
+ */
+type waiters struct {
+	count int32
+	mu    sync.Mutex
+	ch    chan struct{}
+}
+
+func (w *waiters) wait() <-chan struct{} {
+	atomic.AddInt32(&w.count, 1)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.ch == nil {
+		w.ch = make(chan struct{})
+	}
+	return w.ch
+}
+
+func (w *waiters) done() {
+	atomic.AddInt32(&w.count, -1)
+}
+
+func (w *waiters) broadcast() {
+	if atomic.LoadInt32(&w.count) <= 0 {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.ch != nil {
+		close(w.ch)
+		w.ch = nil
+	}
+}
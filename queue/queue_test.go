@@ -0,0 +1,226 @@
+package queue
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/timtadh/data-structures/pool"
+	"github.com/timtadh/data-structures/types"
+)
+
+var _ pool.Queue = (*Ring)(nil)
+var _ pool.Queue = (*BlockingDeque)(nil)
+
+type blocking interface {
+	pool.Queue
+	TryTake() (types.Hashable, bool)
+	Drain() types.KIterator
+	Capacity() int
+}
+
+func queues(capacity int) map[string]blocking {
+	return map[string]blocking{
+		"ring":  NewRing(capacity),
+		"deque": NewBlockingDeque(capacity),
+	}
+}
+
+func TestFIFO(t *testing.T) {
+	for name, q := range queues(8) {
+		for i := 0; i < 8; i++ {
+			if !q.TryPut(types.Int(i)) {
+				t.Fatalf("%v: put %v failed", name, i)
+			}
+		}
+		if q.TryPut(types.Int(8)) {
+			t.Fatalf("%v: put into a full queue", name)
+		}
+		if q.Size() != 8 {
+			t.Fatalf("%v: size is %v", name, q.Size())
+		}
+		for i := 0; i < 4; i++ {
+			if item, ok := q.TryTake(); !ok || item.(types.Int) != types.Int(i) {
+				t.Fatalf("%v: took %v, %v", name, item, ok)
+			}
+		}
+		// wrap around the ring
+		for i := 8; i < 12; i++ {
+			q.TryPut(types.Int(i))
+		}
+		i := 4
+		for item, next := q.Drain()(); next != nil; item, next = next() {
+			if item.(types.Int) != types.Int(i) {
+				t.Fatalf("%v: drained %v want %v", name, item, i)
+			}
+			i++
+		}
+		if i != 12 || q.Size() != 0 {
+			t.Fatalf("%v: drained to %v, %v left", name, i, q.Size())
+		}
+		if _, ok := q.TryTake(); ok {
+			t.Fatalf("%v: took from an empty queue", name)
+		}
+	}
+}
+
+func TestRingCapacity(t *testing.T) {
+	for capacity, want := range map[int]int{0: 2, 1: 2, 2: 2, 3: 4, 100: 128} {
+		if c := NewRing(capacity).Capacity(); c != want {
+			t.Errorf("a ring of %v has a capacity of %v", capacity, c)
+		}
+	}
+}
+
+func TestBlocking(t *testing.T) {
+	for name, q := range queues(2) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		if _, err := q.TakeCtx(ctx); err != context.DeadlineExceeded {
+			t.Fatalf("%v: take from an empty queue returned %v", name, err)
+		}
+		cancel()
+		got := make(chan types.Hashable)
+		go func() {
+			item, _ := q.TakeCtx(context.Background())
+			got <- item
+		}()
+		q.PutCtx(context.Background(), types.Int(1))
+		if item := <-got; item.(types.Int) != 1 {
+			t.Fatalf("%v: the waiting take got %v", name, item)
+		}
+		for q.TryPut(types.Int(2)) {
+		}
+		ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+		if err := q.PutCtx(ctx, types.Int(3)); err != context.DeadlineExceeded {
+			t.Fatalf("%v: put into a full queue returned %v", name, err)
+		}
+		cancel()
+		put := make(chan error)
+		go func() {
+			put <- q.PutCtx(context.Background(), types.Int(3))
+		}()
+		q.TakeCtx(context.Background())
+		if err := <-put; err != nil {
+			t.Fatalf("%v: the waiting put failed %v", name, err)
+		}
+	}
+}
+
+func TestClose(t *testing.T) {
+	for name, q := range queues(4) {
+		q.TryPut(types.Int(1))
+		waiting := make(chan error)
+		go func() {
+			err := q.PutCtx(context.Background(), types.Int(2))
+			for err == nil {
+				err = q.PutCtx(context.Background(), types.Int(2))
+			}
+			waiting <- err
+		}()
+		for q.Size() < q.Capacity() {
+			time.Sleep(time.Millisecond)
+		}
+		q.Close()
+		if err := <-waiting; err == nil {
+			t.Fatalf("%v: a waiting put did not fail on close", name)
+		}
+		if q.TryPut(types.Int(3)) {
+			t.Fatalf("%v: put into a closed queue", name)
+		}
+		for i := 0; i < q.Capacity(); i++ {
+			if _, err := q.TakeCtx(context.Background()); err != nil {
+				t.Fatalf("%v: take %v from a closed queue failed %v", name, i, err)
+			}
+		}
+		if _, err := q.TakeCtx(context.Background()); err == nil {
+			t.Fatalf("%v: take from a closed empty queue did not fail", name)
+		}
+	}
+}
+
+func TestUnboundedDeque(t *testing.T) {
+	d := NewBlockingDeque(0)
+	for i := 0; i < 1000; i++ {
+		if !d.TryPut(types.Int(i)) {
+			t.Fatal("put into an unbounded deque failed")
+		}
+	}
+	d.TryPutFront(types.Int(-1))
+	if item, _ := d.TryTake(); item.(types.Int) != -1 {
+		t.Fatalf("the front was %v", item)
+	}
+	if item, _ := d.TryTakeBack(); item.(types.Int) != 999 {
+		t.Fatalf("the back was %v", item)
+	}
+	item, err := d.TakeBackCtx(context.Background())
+	if err != nil || item.(types.Int) != 998 {
+		t.Fatalf("took %v, %v from the back", item, err)
+	}
+	if err := d.PutFrontCtx(context.Background(), types.Int(-2)); err != nil {
+		t.Fatal(err)
+	}
+	if item, _ := d.TakeCtx(context.Background()); item.(types.Int) != -2 {
+		t.Fatalf("the front was %v", item)
+	}
+}
+
+func TestConcurrent(t *testing.T) {
+	const producers, consumers, each = 4, 4, 5000
+	for name, q := range queues(16) {
+		var wg sync.WaitGroup
+		var sum, count int64
+		for p := 0; p < producers; p++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 1; i <= each; i++ {
+					if err := q.PutCtx(context.Background(), types.Int(i)); err != nil {
+						t.Error(err)
+						return
+					}
+				}
+			}()
+		}
+		var cwg sync.WaitGroup
+		for c := 0; c < consumers; c++ {
+			cwg.Add(1)
+			go func() {
+				defer cwg.Done()
+				for {
+					item, err := q.TakeCtx(context.Background())
+					if err != nil {
+						return
+					}
+					atomic.AddInt64(&sum, int64(item.(types.Int)))
+					atomic.AddInt64(&count, 1)
+				}
+			}()
+		}
+		wg.Wait()
+		q.Close()
+		cwg.Wait()
+		if count != producers*each || sum != producers*each*(each+1)/2 {
+			t.Fatalf("%v: took %v items summing to %v", name, count, sum)
+		}
+	}
+}
+
+func TestPoolQueue(t *testing.T) {
+	for name, q := range queues(4) {
+		p := pool.NewWithQueue(3, q)
+		var done int64
+		for i := 0; i < 100; i++ {
+			if err := p.Do(func() { atomic.AddInt64(&done, 1) }); err != nil {
+				t.Fatal(err)
+			}
+		}
+		p.Stop()
+		if done != 100 {
+			t.Fatalf("%v: the pool ran %v tasks", name, done)
+		}
+	}
+}
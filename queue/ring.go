@@ -0,0 +1,207 @@
+package queue
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+import (
+	"github.com/timtadh/data-structures/types"
+)
+
+type ring_cell struct {
+	seq  uint64
+	item types.Hashable
+}
+
+/* A bounded FIFO which many goroutines can put into and take from without
+ * locks, after Dmitry Vyukov's bounded MPMC queue. Each cell of the buffer has
+ * a sequence number which says whether it is ready for the put or the take of
+ * a given position, and positions are claimed with a compare and swap.
+ *
+ * TryPut and TryTake never block. PutCtx and TakeCtx wait, and only they take
+ * a lock (to sleep). Items put while the ring is being closed may be left in
+ * it.
+ */
+type Ring struct {
+	head   uint64
+	_      [56]byte // keep head and tail on separate cache lines
+	tail   uint64
+	_      [56]byte
+	closed int32
+	mask   uint64
+	cells  []ring_cell
+	items  waiters // takers waiting for an item
+	space  waiters // putters waiting for room
+}
+
+// Makes a ring of at least capacity (rounded up to a power of 2) items.
+func NewRing(capacity int) *Ring {
+	size := uint64(2)
+	for size < uint64(capacity) {
+		size <<= 1
+	}
+	r := &Ring{
+		mask:  size - 1,
+		cells: make([]ring_cell, size),
+	}
+	for i := range r.cells {
+		r.cells[i].seq = uint64(i)
+	}
+	return r
+}
+
+func (r *Ring) Capacity() int {
+	return len(r.cells)
+}
+
+// The number of items in the ring. It may be out of date by the time it
+// returns.
+func (r *Ring) Size() int {
+	head := atomic.LoadUint64(&r.head)
+	tail := atomic.LoadUint64(&r.tail)
+	if tail < head {
+		return 0
+	} else if tail-head > uint64(len(r.cells)) {
+		return len(r.cells)
+	}
+	return int(tail - head)
+}
+
+func (r *Ring) Closed() bool {
+	return atomic.LoadInt32(&r.closed) != 0
+}
+
+// Stops the ring taking more items. The items in it can still be taken.
+func (r *Ring) Close() {
+	atomic.StoreInt32(&r.closed, 1)
+	r.items.broadcast()
+	r.space.broadcast()
+}
+
+func (r *Ring) put(item types.Hashable) bool {
+	pos := atomic.LoadUint64(&r.tail)
+	var cell *ring_cell
+	for {
+		cell = &r.cells[pos&r.mask]
+		seq := atomic.LoadUint64(&cell.seq)
+		if diff := int64(seq - pos); diff == 0 {
+			if atomic.CompareAndSwapUint64(&r.tail, pos, pos+1) {
+				break
+			}
+			pos = atomic.LoadUint64(&r.tail)
+		} else if diff < 0 {
+			// the cell still has the item from a lap ago
+			return false
+		} else {
+			pos = atomic.LoadUint64(&r.tail)
+		}
+	}
+	cell.item = item
+	atomic.StoreUint64(&cell.seq, pos+1)
+	r.items.broadcast()
+	return true
+}
+
+func (r *Ring) take() (types.Hashable, bool) {
+	pos := atomic.LoadUint64(&r.head)
+	var cell *ring_cell
+	for {
+		cell = &r.cells[pos&r.mask]
+		seq := atomic.LoadUint64(&cell.seq)
+		if diff := int64(seq - (pos + 1)); diff == 0 {
+			if atomic.CompareAndSwapUint64(&r.head, pos, pos+1) {
+				break
+			}
+			pos = atomic.LoadUint64(&r.head)
+		} else if diff < 0 {
+			// the cell has not been put into yet
+			return nil, false
+		} else {
+			pos = atomic.LoadUint64(&r.head)
+		}
+	}
+	item := cell.item
+	cell.item = nil
+	atomic.StoreUint64(&cell.seq, pos+r.mask+1)
+	r.space.broadcast()
+	return item, true
+}
+
+// Puts the item if the ring is open and has room.
+func (r *Ring) TryPut(item types.Hashable) bool {
+	return !r.Closed() && r.put(item)
+}
+
+// Takes an item if there is one.
+func (r *Ring) TryTake() (types.Hashable, bool) {
+	return r.take()
+}
+
+// Puts the item, waiting for room until ctx is done. It is an error to put
+// into a closed ring.
+func (r *Ring) PutCtx(ctx context.Context, item types.Hashable) error {
+	for {
+		if r.Closed() {
+			return closed_error()
+		} else if r.put(item) {
+			return nil
+		}
+		changed := r.space.wait()
+		if r.Closed() {
+			r.space.done()
+			return closed_error()
+		} else if r.put(item) {
+			r.space.done()
+			return nil
+		}
+		select {
+		case <-changed:
+			r.space.done()
+		case <-ctx.Done():
+			r.space.done()
+			return ctx.Err()
+		}
+	}
+}
+
+// Takes an item, waiting for one until ctx is done. Once the ring is closed
+// it returns the items left in it and then an error.
+func (r *Ring) TakeCtx(ctx context.Context) (types.Hashable, error) {
+	for {
+		if item, ok := r.take(); ok {
+			return item, nil
+		}
+		changed := r.items.wait()
+		if item, ok := r.take(); ok {
+			r.items.done()
+			return item, nil
+		} else if r.Closed() {
+			r.items.done()
+			// an item may have gone in just before the close
+			if item, ok := r.take(); ok {
+				return item, nil
+			}
+			return nil, closed_error()
+		}
+		select {
+		case <-changed:
+			r.items.done()
+		case <-ctx.Done():
+			r.items.done()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Takes the items in the ring, until it is empty, as they are iterated.
+func (r *Ring) Drain() (it types.KIterator) {
+	it = func() (types.Hashable, types.KIterator) {
+		item, ok := r.take()
+		if !ok {
+			return nil, nil
+		}
+		return item, it
+	}
+	return it
+}
@@ -0,0 +1,380 @@
+package set
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/bits"
+	"strings"
+)
+
+import (
+	"github.com/timtadh/data-structures/errors"
+	"github.com/timtadh/data-structures/types"
+)
+
+// The value of an integer item (any of the integer types in the types
+// package). ok is false for other items and for values outside [0, max].
+func int_item(item types.Hashable, max uint64) (i uint64, ok bool) {
+	var v int64
+	switch x := item.(type) {
+	case types.Int:
+		v = int64(x)
+	case types.Int8:
+		v = int64(x)
+	case types.Int16:
+		v = int64(x)
+	case types.Int32:
+		v = int64(x)
+	case types.Int64:
+		v = int64(x)
+	case types.UInt:
+		return uint64(x), uint64(x) <= max
+	case types.UInt8:
+		return uint64(x), uint64(x) <= max
+	case types.UInt16:
+		return uint64(x), uint64(x) <= max
+	case types.UInt32:
+		return uint64(x), uint64(x) <= max
+	case types.UInt64:
+		return uint64(x), uint64(x) <= max
+	default:
+		return 0, false
+	}
+	return uint64(v), v >= 0 && uint64(v) <= max
+}
+
+// The largest item a BitSet holds, the same bound as a Roaring's. A BitSet
+// with it takes 512MB.
+const MaxBitSetItem = math.MaxUint32
+
+/* A set of non-negative integers as a bit vector: item i is bit i%64 of word
+ * i/64. It takes a bit for every integer up to the largest item, so it suits
+ * dense ids. Items may be of any integer type in the types package, must be
+ * at most MaxBitSetItem and come out of Items in ascending order as
+ * types.Int.
+ *
+ * Union, Intersect, Subtract, SymmetricDifference, Subset and Equals work a
+ * word at a time when both sets are BitSets.
+ */
+type BitSet struct {
+	words []uint64
+	size  int
+}
+
+// Makes a BitSet which can hold the items in [0, initialSize) without growing.
+func NewBitSet(initialSize int) *BitSet {
+	return &BitSet{words: make([]uint64, (initialSize+63)/64)}
+}
+
+func (s *BitSet) Copy() *BitSet {
+	words := make([]uint64, len(s.words))
+	copy(words, s.words)
+	return &BitSet{words: words, size: s.size}
+}
+
+func (s *BitSet) bit(item types.Hashable) (int, bool) {
+	i, ok := int_item(item, MaxBitSetItem)
+	// less on a 32 bit platform
+	return int(i), ok && i <= math.MaxInt
+}
+
+func (s *BitSet) Size() int {
+	return s.size
+}
+
+func (s *BitSet) Has(item types.Hashable) bool {
+	i, ok := s.bit(item)
+	return ok && i/64 < len(s.words) && s.words[i/64]&(1<<uint(i%64)) != 0
+}
+
+func (s *BitSet) Item(item types.Hashable) (types.Hashable, error) {
+	if !s.Has(item) {
+		return nil, errors.NotFound(item)
+	}
+	i, _ := s.bit(item)
+	return types.Int(i), nil
+}
+
+func (s *BitSet) Add(item types.Hashable) (err error) {
+	i, ok := s.bit(item)
+	if !ok {
+		return errors.Errorf("A BitSet only holds integers in [0, %v], got %v (%T)", uint64(MaxBitSetItem), item, item)
+	}
+	w := i / 64
+	if w >= cap(s.words) {
+		c := 2 * (w + 1)
+		if c > MaxBitSetItem/64+1 {
+			c = MaxBitSetItem/64 + 1
+		}
+		words := make([]uint64, w+1, c)
+		copy(words, s.words)
+		s.words = words
+	} else if w >= len(s.words) {
+		s.words = s.words[:w+1]
+	}
+	if s.words[w]&(1<<uint(i%64)) == 0 {
+		s.words[w] |= 1 << uint(i%64)
+		s.size++
+	}
+	return nil
+}
+
+func (s *BitSet) Delete(item types.Hashable) (err error) {
+	if !s.Has(item) {
+		return errors.NotFound(item)
+	}
+	i, _ := s.bit(item)
+	s.words[i/64] &^= 1 << uint(i%64)
+	s.size--
+	return nil
+}
+
+func (s *BitSet) Extend(items types.KIterator) (err error) {
+	for item, next := items(); next != nil; item, next = next() {
+		err := s.Add(item)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *BitSet) Items() (it types.KIterator) {
+	w := 0
+	var word uint64
+	if len(s.words) > 0 {
+		word = s.words[0]
+	}
+	it = func() (types.Hashable, types.KIterator) {
+		for word == 0 {
+			w++
+			if w >= len(s.words) {
+				return nil, nil
+			}
+			word = s.words[w]
+		}
+		b := bits.TrailingZeros64(word)
+		word &^= 1 << uint(b)
+		return types.Int(w*64 + b), it
+	}
+	return it
+}
+
+// The number of items less than i.
+func (s *BitSet) Rank(i int) int {
+	if i <= 0 {
+		return 0
+	}
+	w := i / 64
+	if w >= len(s.words) {
+		return s.size
+	}
+	rank := bits.OnesCount64(s.words[w] & (1<<uint(i%64) - 1))
+	for _, word := range s.words[:w] {
+		rank += bits.OnesCount64(word)
+	}
+	return rank
+}
+
+// The item with rank r, that is the r'th smallest item counting from 0.
+func (s *BitSet) Select(r int) (types.Int, error) {
+	if r < 0 || r >= s.size {
+		return 0, errors.Errorf("Select(%v) out of range [0, %v)", r, s.size)
+	}
+	for w, word := range s.words {
+		c := bits.OnesCount64(word)
+		if r < c {
+			return types.Int(w*64 + select_word(word, r)), nil
+		}
+		r -= c
+	}
+	panic(errors.Errorf("BitSet size is wrong"))
+}
+
+// the index of the r'th set bit of word
+func select_word(word uint64, r int) int {
+	for ; r > 0; r-- {
+		word &= word - 1
+	}
+	return bits.TrailingZeros64(word)
+}
+
+// Is b a set with the same items?
+func (s *BitSet) Equals(b types.Equatable) bool {
+	if o, ok := b.(*BitSet); ok {
+		if s.size != o.size {
+			return false
+		}
+		return s.subset(o)
+	}
+	o, ok := b.(types.Set)
+	if !ok || o.Size() != s.Size() {
+		return false
+	}
+	return Subset(o, s)
+}
+
+func (s *BitSet) String() string {
+	items := make([]string, 0, s.Size())
+	for item, next := s.Items()(); next != nil; item, next = next() {
+		items = append(items, fmt.Sprintf("%v", item))
+	}
+	return "{" + strings.Join(items, ", ") + "}"
+}
+
+// Applies op a word at a time. Words past the end of the shorter set are 0.
+func (s *BitSet) combine(o *BitSet, op func(a, b uint64) uint64) *BitSet {
+	n := len(s.words)
+	if len(o.words) > n {
+		n = len(o.words)
+	}
+	c := &BitSet{words: make([]uint64, n)}
+	for i := range c.words {
+		var a, b uint64
+		if i < len(s.words) {
+			a = s.words[i]
+		}
+		if i < len(o.words) {
+			b = o.words[i]
+		}
+		c.words[i] = op(a, b)
+		c.size += bits.OnesCount64(c.words[i])
+	}
+	return c
+}
+
+// Unions s with o and returns a new BitSet. o must only have integers.
+func (s *BitSet) Union(other types.Set) (types.Set, error) {
+	if o, ok := other.(*BitSet); ok {
+		return s.combine(o, func(a, b uint64) uint64 { return a | b }), nil
+	}
+	c := s.Copy()
+	if err := c.Extend(other.Items()); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Intersects s with o and returns a new BitSet.
+func (s *BitSet) Intersect(other types.Set) (types.Set, error) {
+	if o, ok := other.(*BitSet); ok {
+		return s.combine(o, func(a, b uint64) uint64 { return a & b }), nil
+	}
+	return s.filter(func(item types.Hashable) bool { return other.Has(item) }), nil
+}
+
+// Subtracts o from s and returns a new BitSet.
+func (s *BitSet) Subtract(other types.Set) (types.Set, error) {
+	if o, ok := other.(*BitSet); ok {
+		return s.combine(o, func(a, b uint64) uint64 { return a &^ b }), nil
+	}
+	return s.filter(func(item types.Hashable) bool { return !other.Has(item) }), nil
+}
+
+// The items in exactly one of s and o as a new BitSet. o must only have
+// integers.
+func (s *BitSet) SymmetricDifference(other types.Set) (types.Set, error) {
+	o, ok := other.(*BitSet)
+	if !ok {
+		o = NewBitSet(0)
+		if err := o.Extend(other.Items()); err != nil {
+			return nil, err
+		}
+	}
+	return s.combine(o, func(a, b uint64) uint64 { return a ^ b }), nil
+}
+
+func (s *BitSet) filter(keep func(types.Hashable) bool) *BitSet {
+	c := NewBitSet(len(s.words) * 64)
+	for item, next := s.Items()(); next != nil; item, next = next() {
+		if keep(item) {
+			i := int(item.(types.Int))
+			c.words[i/64] |= 1 << uint(i%64)
+			c.size++
+		}
+	}
+	return c
+}
+
+// Do s and o have no items in common?
+func (s *BitSet) Disjoint(other types.Set) bool {
+	if o, ok := other.(*BitSet); ok {
+		for i := 0; i < len(s.words) && i < len(o.words); i++ {
+			if s.words[i]&o.words[i] != 0 {
+				return false
+			}
+		}
+		return true
+	}
+	return Disjoint(s, other)
+}
+
+func (s *BitSet) subset(o *BitSet) bool {
+	for i, word := range s.words {
+		var b uint64
+		if i < len(o.words) {
+			b = o.words[i]
+		}
+		if word&^b != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Is s a subset of o?
+func (s *BitSet) Subset(other types.Set) bool {
+	if o, ok := other.(*BitSet); ok {
+		return s.size <= o.size && s.subset(o)
+	}
+	return Subset(s, other)
+}
+
+// Is s a proper subset of o?
+func (s *BitSet) ProperSubset(o types.Set) bool {
+	return s.Size() < o.Size() && s.Subset(o)
+}
+
+// Is s a superset of o?
+func (s *BitSet) Superset(o types.Set) bool {
+	return o.Subset(s)
+}
+
+// Is s a proper superset of o?
+func (s *BitSet) ProperSuperset(o types.Set) bool {
+	return o.ProperSubset(s)
+}
+
+// The size followed by the words (up to the last non-zero one), all little
+// endian.
+func (s *BitSet) MarshalBinary() ([]byte, error) {
+	n := len(s.words)
+	for n > 0 && s.words[n-1] == 0 {
+		n--
+	}
+	bytes := make([]byte, 8+8*n)
+	binary.LittleEndian.PutUint64(bytes, uint64(s.size))
+	for i, word := range s.words[:n] {
+		binary.LittleEndian.PutUint64(bytes[8+8*i:], word)
+	}
+	return bytes, nil
+}
+
+func (s *BitSet) UnmarshalBinary(bytes []byte) error {
+	if len(bytes) < 8 || len(bytes)%8 != 0 {
+		return errors.Errorf("A marshalled BitSet is a multiple of 8 bytes, got %v", len(bytes))
+	}
+	words := make([]uint64, len(bytes)/8-1)
+	size := 0
+	for i := range words {
+		words[i] = binary.LittleEndian.Uint64(bytes[8+8*i:])
+		size += bits.OnesCount64(words[i])
+	}
+	if uint64(size) != binary.LittleEndian.Uint64(bytes) {
+		return errors.Errorf("A marshalled BitSet has %v items but says it has %v", size, binary.LittleEndian.Uint64(bytes))
+	}
+	s.words = words
+	s.size = size
+	return nil
+}
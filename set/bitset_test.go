@@ -0,0 +1,133 @@
+package set
+
+import "testing"
+
+import (
+	"fmt"
+	"math"
+)
+
+import (
+	"github.com/timtadh/data-structures/types"
+)
+
+// an integer set which is checked against a map
+type int_set interface {
+	types.Set
+	Rank(i int) int
+	Select(r int) (types.Int, error)
+	MarshalBinary() ([]byte, error)
+	UnmarshalBinary([]byte) error
+}
+
+// adds and deletes random items in [0, max) and checks has, size, rank,
+// select and iteration against a map
+func check_int_set(t *T, set int_set, max, ops int) map[int]bool {
+	model := make(map[int]bool)
+	for i := 0; i < ops; i++ {
+		k := rand.Intn(max)
+		if rand.Intn(3) == 0 {
+			err := set.Delete(types.Int(k))
+			t.assert(fmt.Sprintf("delete(%v) err %v", k, err), (err == nil) == model[k])
+			delete(model, k)
+		} else {
+			t.assert_nil(set.Add(types.Int(k)))
+			model[k] = true
+		}
+	}
+	t.assert(fmt.Sprintf("size %v != %v", set.Size(), len(model)), set.Size() == len(model))
+	r := 0
+	for k := 0; k < max; k++ {
+		t.assert(fmt.Sprintf("has(%v) != %v", k, model[k]), set.Has(types.Int(k)) == model[k])
+		t.assert(fmt.Sprintf("rank(%v) = %v != %v", k, set.Rank(k), r), set.Rank(k) == r)
+		if model[k] {
+			item, err := set.Select(r)
+			t.assert_nil(err)
+			t.assert(fmt.Sprintf("select(%v) = %v != %v", r, item, k), int(item) == k)
+			r++
+		}
+	}
+	prev := -1
+	for item, next := set.Items()(); next != nil; item, next = next() {
+		i := int(item.(types.Int))
+		t.assert(fmt.Sprintf("iterated %v after %v", i, prev), i > prev && model[i])
+		prev = i
+	}
+	_, err := set.Select(set.Size())
+	t.assert("select past the end is an error", err != nil)
+	return model
+}
+
+func TestBitSetRandom(x *testing.T) {
+	t := (*T)(x)
+	set := NewBitSet(0)
+	check_int_set(t, set, 1000, 3000)
+	t.assert("negative items are not allowed", set.Add(types.Int(-1)) != nil)
+	t.assert("items past the max are not allowed", set.Add(types.UInt64(MaxBitSetItem+1)) != nil)
+	t.assert("huge items are not allowed", set.Add(types.Int64(1<<58)) != nil)
+	t.assert("items past the max are not in the set", !set.Has(types.UInt64(MaxBitSetItem+1)))
+	t.assert("other types are not allowed", set.Add(types.String("1")) != nil)
+	t.assert_nil(set.Add(types.UInt8(7)))
+	t.assert("any integer type", set.Has(types.Int64(7)))
+	item, err := set.Item(types.UInt8(7))
+	t.assert_nil(err)
+	t.assert("items are types.Int", item.(types.Int) == 7)
+}
+
+func TestBitSetMax(x *testing.T) {
+	if testing.Short() || math.MaxInt < MaxBitSetItem {
+		x.Skip("the largest item takes 512MB")
+	}
+	t := (*T)(x)
+	set := NewBitSet(0)
+	t.assert_nil(set.Add(types.UInt32(MaxBitSetItem)))
+	t.assert("the max is in the set", set.Has(types.UInt64(MaxBitSetItem)))
+	t.assert("the words do not grow past the max", cap(set.words) == MaxBitSetItem/64+1)
+	t.assert("one item", set.Size() == 1)
+}
+
+func TestBitSetAlgebra(x *testing.T) {
+	t := (*T)(x)
+	a := NewBitSet(0)
+	b := NewBitSet(0)
+	for i := 0; i < 300; i += 2 {
+		t.assert_nil(a.Add(types.Int(i)))
+	}
+	for i := 0; i < 600; i += 3 {
+		t.assert_nil(b.Add(types.Int(i)))
+	}
+	for _, o := range []types.Set{b, SortedFromSet(b)} {
+		u := t.assert_set(a.Union(o))
+		n := t.assert_set(a.Intersect(o))
+		d := t.assert_set(a.Subtract(o))
+		s := t.assert_set(a.SymmetricDifference(o))
+		for i := 0; i < 600; i++ {
+			inA, inB := i < 300 && i%2 == 0, i%3 == 0
+			t.assert(fmt.Sprintf("%v in a | b", i), u.Has(types.Int(i)) == (inA || inB))
+			t.assert(fmt.Sprintf("%v in a & b", i), n.Has(types.Int(i)) == (inA && inB))
+			t.assert(fmt.Sprintf("%v in a - b", i), d.Has(types.Int(i)) == (inA && !inB))
+			t.assert(fmt.Sprintf("%v in a ^ b", i), s.Has(types.Int(i)) == (inA != inB))
+		}
+		t.assert("sizes", u.Size() == 150+200-50 && n.Size() == 50 && d.Size() == 100 && s.Size() == 250)
+		t.assert("a and b overlap", !a.Disjoint(o))
+		t.assert("a & b subset of a", n.Subset(a) && n.ProperSubset(o) && o.Superset(n))
+	}
+	c := NewBitSet(0)
+	c.Add(types.Int(1))
+	t.assert("a and {1} are disjoint", a.Disjoint(c))
+	t.assert("b == sorted b", b.Equals(SortedFromSet(b)) && b.Equals(b.Copy()) && !b.Equals(a))
+}
+
+func TestBitSetMarshal(x *testing.T) {
+	t := (*T)(x)
+	set := NewBitSet(0)
+	model := check_int_set(t, set, 2000, 1000)
+	bytes, err := set.MarshalBinary()
+	t.assert_nil(err)
+	set2 := NewBitSet(0)
+	t.assert_nil(set2.UnmarshalBinary(bytes))
+	t.assert("unmarshalled set is the same", set2.Equals(set) && set2.Size() == len(model))
+	t.assert("bad length", set2.UnmarshalBinary(bytes[:len(bytes)-1]) != nil)
+	bytes[0]++
+	t.assert("bad size", set2.UnmarshalBinary(bytes) != nil)
+}
@@ -0,0 +1,52 @@
+package set
+
+import (
+	"testing"
+)
+
+import (
+	"github.com/timtadh/data-structures/hashtable"
+	"github.com/timtadh/data-structures/test"
+)
+
+func FuzzSortedSet(f *testing.F) {
+	test.AddSeeds(f)
+	f.Fuzz(func(t *testing.T, ops []byte) {
+		test.CheckSet(t, NewSortedSet(1), ops, test.IntKey)
+	})
+}
+
+func FuzzSetMap(f *testing.F) {
+	test.AddSeeds(f)
+	f.Fuzz(func(t *testing.T, ops []byte) {
+		test.CheckSet(t, NewSetMap(hashtable.NewLinearHash()), ops, test.StringKey)
+	})
+}
+
+func FuzzMapSet(f *testing.F) {
+	test.AddSeeds(f)
+	f.Fuzz(func(t *testing.T, ops []byte) {
+		test.CheckMap(t, NewMapSet(NewSortedSet(1)), ops, test.IntKey)
+	})
+}
+
+func FuzzHashSet(f *testing.F) {
+	test.AddSeeds(f)
+	f.Fuzz(func(t *testing.T, ops []byte) {
+		test.CheckSet(t, NewHashSet(1), ops, test.IntKey)
+	})
+}
+
+func FuzzBitSet(f *testing.F) {
+	test.AddSeeds(f)
+	f.Fuzz(func(t *testing.T, ops []byte) {
+		test.CheckSet(t, NewBitSet(0), ops, test.IntKey)
+	})
+}
+
+func FuzzRoaring(f *testing.F) {
+	test.AddSeeds(f)
+	f.Fuzz(func(t *testing.T, ops []byte) {
+		test.CheckSet(t, NewRoaring(), ops, test.IntKey)
+	})
+}
@@ -0,0 +1,273 @@
+package set
+
+import (
+	"fmt"
+	"strings"
+)
+
+import (
+	"github.com/timtadh/data-structures/errors"
+	"github.com/timtadh/data-structures/types"
+)
+
+/* A set in an open addressing hash table with linear probing. Has, Add,
+ * Item and Delete take O(1) expected time, unlike the O(log n) lookups and
+ * O(n) inserts of a SortedSet. The items are iterated in no particular order.
+ *
+ * The table is a power of two in size and kept at most 3/4 full. Each slot
+ * caches the hash of its item. Delete shifts the following items of the probe
+ * run back so the table never has tombstones.
+ */
+type HashSet struct {
+	table []hash_slot
+	size  int
+}
+
+type hash_slot struct {
+	hash int
+	item types.Hashable // nil when the slot is empty
+}
+
+const min_hash_set_table = 8
+
+// Makes a HashSet which can hold initialSize items without growing.
+func NewHashSet(initialSize int) *HashSet {
+	n := min_hash_set_table
+	for n*3 < initialSize*4 {
+		n *= 2
+	}
+	return &HashSet{table: make([]hash_slot, n)}
+}
+
+func HashSetFromSet(s types.Set) *HashSet {
+	if h, ok := s.(*HashSet); ok {
+		return h.Copy()
+	}
+	n := NewHashSet(s.Size())
+	for item, next := s.Items()(); next != nil; item, next = next() {
+		n.add(item.Hash(), item)
+	}
+	return n
+}
+
+func (s *HashSet) Copy() *HashSet {
+	table := make([]hash_slot, len(s.table))
+	copy(table, s.table)
+	return &HashSet{table: table, size: s.size}
+}
+
+// the home slot of a hash (fibonacci hashing so that sequential hashes,
+// which types.Int has, spread out)
+func (s *HashSet) slot(hash int) int {
+	return int((uint64(hash) * 0x9e3779b97f4a7c15) >> 32 & uint64(len(s.table)-1))
+}
+
+// the slot of item or else the empty slot ending its probe run
+func (s *HashSet) find(hash int, item types.Hashable) (i int, has bool) {
+	mask := len(s.table) - 1
+	for i = s.slot(hash); s.table[i].item != nil; i = (i + 1) & mask {
+		if s.table[i].hash == hash && s.table[i].item.Equals(item) {
+			return i, true
+		}
+	}
+	return i, false
+}
+
+func (s *HashSet) Size() int {
+	return s.size
+}
+
+func (s *HashSet) Has(item types.Hashable) bool {
+	_, has := s.find(item.Hash(), item)
+	return has
+}
+
+func (s *HashSet) Item(item types.Hashable) (types.Hashable, error) {
+	i, has := s.find(item.Hash(), item)
+	if !has {
+		return nil, errors.NotFound(item)
+	}
+	return s.table[i].item, nil
+}
+
+func (s *HashSet) Add(item types.Hashable) (err error) {
+	if item == nil {
+		return errors.Errorf("Can not add nil to a HashSet")
+	}
+	s.add(item.Hash(), item)
+	return nil
+}
+
+func (s *HashSet) add(hash int, item types.Hashable) {
+	i, has := s.find(hash, item)
+	if has {
+		return
+	}
+	s.table[i] = hash_slot{hash, item}
+	s.size++
+	if s.size*4 > len(s.table)*3 {
+		s.expand()
+	}
+}
+
+func (s *HashSet) expand() {
+	table := s.table
+	s.table = make([]hash_slot, len(table)*2)
+	mask := len(s.table) - 1
+	for _, e := range table {
+		if e.item == nil {
+			continue
+		}
+		i := s.slot(e.hash)
+		for s.table[i].item != nil {
+			i = (i + 1) & mask
+		}
+		s.table[i] = e
+	}
+}
+
+func (s *HashSet) Delete(item types.Hashable) (err error) {
+	i, has := s.find(item.Hash(), item)
+	if !has {
+		return errors.NotFound(item)
+	}
+	// shift back each following item in the run which may not be left
+	// after the hole (its home is not cyclically in (i, j])
+	mask := len(s.table) - 1
+	for j := (i + 1) & mask; s.table[j].item != nil; j = (j + 1) & mask {
+		home := s.slot(s.table[j].hash)
+		if (j > i && (home <= i || home > j)) || (j < i && home <= i && home > j) {
+			s.table[i] = s.table[j]
+			i = j
+		}
+	}
+	s.table[i] = hash_slot{}
+	s.size--
+	return nil
+}
+
+func (s *HashSet) Extend(items types.KIterator) (err error) {
+	for item, next := items(); next != nil; item, next = next() {
+		err := s.Add(item)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *HashSet) Items() (it types.KIterator) {
+	table := s.table
+	i := -1
+	it = func() (types.Hashable, types.KIterator) {
+		for i++; i < len(table); i++ {
+			if table[i].item != nil {
+				return table[i].item, it
+			}
+		}
+		return nil, nil
+	}
+	return it
+}
+
+// Is o a set with the same items?
+func (s *HashSet) Equals(b types.Equatable) bool {
+	o, ok := b.(types.Set)
+	if !ok || o.Size() != s.Size() {
+		return false
+	}
+	return Subset(o, s)
+}
+
+func (s *HashSet) String() string {
+	items := make([]string, 0, s.Size())
+	for item, next := s.Items()(); next != nil; item, next = next() {
+		items = append(items, fmt.Sprintf("%v", item))
+	}
+	return "{" + strings.Join(items, ", ") + "}"
+}
+
+// the smaller and larger of s and o
+func (s *HashSet) order(o types.Set) (small, large types.Set) {
+	if o.Size() < s.Size() {
+		return o, s
+	}
+	return s, o
+}
+
+// Unions s with o and returns a new HashSet. Copies the larger set if it is
+// a HashSet and adds the other one to it.
+func (s *HashSet) Union(o types.Set) (types.Set, error) {
+	small, large := s.order(o)
+	n := HashSetFromSet(large)
+	for item, next := small.Items()(); next != nil; item, next = next() {
+		if err := n.Add(item); err != nil {
+			return nil, err
+		}
+	}
+	return n, nil
+}
+
+// Intersects s with o and returns a new HashSet. Looks up the items of the
+// smaller set in the larger one.
+func (s *HashSet) Intersect(o types.Set) (types.Set, error) {
+	small, large := s.order(o)
+	n := NewHashSet(small.Size())
+	for item, next := small.Items()(); next != nil; item, next = next() {
+		if large.Has(item) {
+			n.add(item.Hash(), item)
+		}
+	}
+	return n, nil
+}
+
+// Subtracts o from s and returns a new HashSet.
+func (s *HashSet) Subtract(o types.Set) (types.Set, error) {
+	n := NewHashSet(s.Size())
+	for _, e := range s.table {
+		if e.item != nil && !o.Has(e.item) {
+			n.add(e.hash, e.item)
+		}
+	}
+	return n, nil
+}
+
+// The items in exactly one of s and o as a new HashSet.
+func (s *HashSet) SymmetricDifference(o types.Set) (types.Set, error) {
+	small, large := s.order(o)
+	n := HashSetFromSet(large)
+	for item, next := small.Items()(); next != nil; item, next = next() {
+		if i, has := n.find(item.Hash(), item); has {
+			n.Delete(n.table[i].item)
+		} else {
+			n.add(item.Hash(), item)
+		}
+	}
+	return n, nil
+}
+
+// Do s and o have no items in common?
+func (s *HashSet) Disjoint(o types.Set) bool {
+	small, large := s.order(o)
+	return Disjoint(small, large)
+}
+
+// Is s a subset of o?
+func (s *HashSet) Subset(o types.Set) bool {
+	return Subset(s, o)
+}
+
+// Is s a proper subset of o?
+func (s *HashSet) ProperSubset(o types.Set) bool {
+	return ProperSubset(s, o)
+}
+
+// Is s a superset of o?
+func (s *HashSet) Superset(o types.Set) bool {
+	return Superset(s, o)
+}
+
+// Is s a proper superset of o?
+func (s *HashSet) ProperSuperset(o types.Set) bool {
+	return ProperSuperset(s, o)
+}
@@ -0,0 +1,91 @@
+package set
+
+import "testing"
+
+import (
+	"fmt"
+)
+
+import (
+	"github.com/timtadh/data-structures/types"
+)
+
+type collide int
+
+func (c collide) Equals(o types.Equatable) bool { return c == o.(collide) }
+func (c collide) Less(o types.Sortable) bool    { return c < o.(collide) }
+func (c collide) Hash() int                     { return int(c) % 3 }
+
+func TestHashSetAddHasDeleteRandom(x *testing.T) {
+	t := (*T)(x)
+	for _, key := range []func(int) types.Hashable{
+		func(i int) types.Hashable { return types.Int(i) },
+		func(i int) types.Hashable { return collide(i) },
+	} {
+		set := NewHashSet(1)
+		model := make(map[int]bool)
+		for i := 0; i < 5000; i++ {
+			k := rand.Intn(200)
+			if rand.Intn(3) == 0 {
+				err := set.Delete(key(k))
+				t.assert(fmt.Sprintf("delete(%v) err %v", k, err), (err == nil) == model[k])
+				delete(model, k)
+			} else {
+				t.assert_nil(set.Add(key(k)))
+				model[k] = true
+			}
+			t.assert(fmt.Sprintf("size %v != %v", set.Size(), len(model)), set.Size() == len(model))
+		}
+		for k := 0; k < 200; k++ {
+			t.assert(fmt.Sprintf("has(%v) != %v", k, model[k]), set.Has(key(k)) == model[k])
+		}
+		count := 0
+		for item, next := set.Items()(); next != nil; item, next = next() {
+			t.assert(fmt.Sprintf("iterated %v", item), set.Has(item))
+			count++
+		}
+		t.assert("iterated every item once", count == len(model))
+	}
+}
+
+func TestHashSetAlgebra(x *testing.T) {
+	t := (*T)(x)
+	a := NewHashSet(0)
+	b := NewHashSet(0)
+	for i := 0; i < 1000; i++ {
+		t.assert_nil(a.Add(types.Int(i)))
+	}
+	for i := 500; i < 2000; i++ {
+		t.assert_nil(b.Add(types.Int(i)))
+	}
+	sorted := SortedFromSet(b)
+	for _, o := range []types.Set{b, sorted} {
+		u := t.assert_set(a.Union(o))
+		n := t.assert_set(a.Intersect(o))
+		d := t.assert_set(a.Subtract(o))
+		x := t.assert_set(a.SymmetricDifference(o))
+		t.assert("sizes", u.Size() == 2000 && n.Size() == 500 && d.Size() == 500 && x.Size() == 1500)
+		for i := 0; i < 2000; i++ {
+			item := types.Int(i)
+			t.assert(fmt.Sprintf("%v in a | b", i), u.Has(item))
+			t.assert(fmt.Sprintf("%v in a & b", i), n.Has(item) == (i >= 500 && i < 1000))
+			t.assert(fmt.Sprintf("%v in a - b", i), d.Has(item) == (i < 500))
+			t.assert(fmt.Sprintf("%v in a ^ b", i), x.Has(item) == (i < 500 || i >= 1000))
+		}
+		t.assert("a and b overlap", !a.Disjoint(o))
+	}
+	t.assert("hash b == sorted b", b.Equals(sorted) && !a.Equals(sorted))
+	t.assert("a, b unchanged", a.Size() == 1000 && b.Size() == 1500)
+}
+
+func TestBestTypeIsHashSetForLargeInputs(x *testing.T) {
+	t := (*T)(x)
+	_, ok := newSetBestType(NewSetMap(nil), 0).(*SetMap)
+	t.assert("SetMap results stay SetMaps", ok)
+	_, ok = newSetBestType(NewHashSet(0), 0).(*HashSet)
+	t.assert("HashSet results stay HashSets", ok)
+	_, ok = newSetBestType(nil, hash_set_size_hint).(*HashSet)
+	t.assert("large results are HashSets", ok)
+	_, ok = newSetBestType(nil, hash_set_size_hint-1).(*SortedSet)
+	t.assert("small results are SortedSets", ok)
+}
@@ -0,0 +1,90 @@
+package set
+
+import (
+	"github.com/timtadh/data-structures/types"
+)
+
+/* Lazy set algebra over sorted iterators. Each function takes two KIterators
+ * which produce their items in ascending order without duplicates (such as
+ * SortedSet.Items or the Keys of a tree) and returns a KIterator which
+ * produces the result in ascending order. Nothing is materialized: each input
+ * is read once, one item at a time, as the result is read.
+ */
+
+// The items in a or b.
+func UnionIterator(a, b types.KIterator) types.KIterator {
+	return merge(a, b, func(inA, inB bool) bool {
+		return true
+	})
+}
+
+// The items in both a and b.
+func IntersectIterator(a, b types.KIterator) types.KIterator {
+	return merge(a, b, func(inA, inB bool) bool {
+		return inA && inB
+	})
+}
+
+// The items in a but not in b.
+func SubtractIterator(a, b types.KIterator) types.KIterator {
+	return merge(a, b, func(inA, inB bool) bool {
+		return inA && !inB
+	})
+}
+
+// The items in exactly one of a and b.
+func SymmetricDifferenceIterator(a, b types.KIterator) types.KIterator {
+	return merge(a, b, func(inA, inB bool) bool {
+		return inA != inB
+	})
+}
+
+// Do a and b have an item in common? Stops at the first one.
+func Overlapping(a, b types.KIterator) bool {
+	_, next := IntersectIterator(a, b)()
+	return next != nil
+}
+
+// Merges a and b and produces the items keep accepts. keep is told which of
+// the inputs had the item.
+func merge(a, b types.KIterator, keep func(inA, inB bool) bool) types.KIterator {
+	var ca, cb types.Hashable
+	started := false
+	var kit types.KIterator
+	kit = func() (types.Hashable, types.KIterator) {
+		if !started {
+			started = true
+			if a != nil {
+				ca, a = a()
+			}
+			if b != nil {
+				cb, b = b()
+			}
+		}
+		for a != nil || b != nil {
+			// once one side runs out the rest of the other side is either
+			// all kept or all dropped
+			if (a == nil && !keep(false, true)) || (b == nil && !keep(true, false)) {
+				break
+			}
+			var item types.Hashable
+			var inA, inB bool
+			if b == nil || (a != nil && ca.Less(cb)) {
+				item, inA = ca, true
+				ca, a = a()
+			} else if a == nil || cb.Less(ca) {
+				item, inB = cb, true
+				cb, b = b()
+			} else {
+				item, inA, inB = ca, true, true
+				ca, a = a()
+				cb, b = b()
+			}
+			if keep(inA, inB) {
+				return item, kit
+			}
+		}
+		return nil, nil
+	}
+	return kit
+}
@@ -0,0 +1,82 @@
+package set
+
+import "testing"
+
+import (
+	"github.com/timtadh/data-structures/types"
+)
+
+// a random sorted set of n items from [0, 2n)
+func random_sorted(n int) *SortedSet {
+	s := NewSortedSet(n)
+	for s.Size() < n {
+		s.Add(types.Int(rand.Intn(2 * n)))
+	}
+	return s
+}
+
+func sorted_items(t *T, it types.KIterator) []types.Hashable {
+	items := make([]types.Hashable, 0, 10)
+	for item, next := it(); next != nil; item, next = next() {
+		if len(items) > 0 {
+			t.assert("iterator is not in ascending order", items[len(items)-1].Less(item))
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+func TestSetIterators(x *testing.T) {
+	t := (*T)(x)
+	for _, sizes := range [][2]int{{0, 0}, {0, 10}, {10, 0}, {1, 1}, {50, 50}, {100, 10}, {10, 100}} {
+		a := random_sorted(sizes[0])
+		b := random_sorted(sizes[1])
+		union := sorted_items(t, UnionIterator(a.Items(), b.Items()))
+		intersect := sorted_items(t, IntersectIterator(a.Items(), b.Items()))
+		subtract := sorted_items(t, SubtractIterator(a.Items(), b.Items()))
+		symmetric := sorted_items(t, SymmetricDifferenceIterator(a.Items(), b.Items()))
+		for _, item := range union {
+			t.assert("union has an item not in a or b", a.Has(item) || b.Has(item))
+		}
+		for _, item := range intersect {
+			t.assert("intersect has an item not in a and b", a.Has(item) && b.Has(item))
+		}
+		for _, item := range subtract {
+			t.assert("subtract has an item not in a - b", a.Has(item) && !b.Has(item))
+		}
+		for _, item := range symmetric {
+			t.assert("symmetric difference has an item in both or neither", a.Has(item) != b.Has(item))
+		}
+		t.assert("|a| + |b| == |a | b| + |a & b|", a.Size()+b.Size() == len(union)+len(intersect))
+		t.assert("|a - b| == |a| - |a & b|", len(subtract) == a.Size()-len(intersect))
+		t.assert("|a ^ b| == |a | b| - |a & b|", len(symmetric) == len(union)-len(intersect))
+		t.assert("overlapping iff a & b is not empty", Overlapping(a.Items(), b.Items()) == (len(intersect) > 0))
+	}
+}
+
+func TestSetIteratorsAreLazy(x *testing.T) {
+	t := (*T)(x)
+	// reading the first item of the intersection reads 1, 2 and 3 from a and
+	// 2 and 3 from b (each side is read one past the item produced)
+	reads := 0
+	counting := func(s *SortedSet) types.KIterator {
+		var wrap func(types.KIterator) types.KIterator
+		wrap = func(it types.KIterator) types.KIterator {
+			return func() (types.Hashable, types.KIterator) {
+				reads++
+				item, next := it()
+				if next == nil {
+					return nil, nil
+				}
+				return item, wrap(next)
+			}
+		}
+		return wrap(s.Items())
+	}
+	a := FromSlice([]types.Hashable{types.Int(1), types.Int(2), types.Int(3), types.Int(4)})
+	b := FromSlice([]types.Hashable{types.Int(2), types.Int(3), types.Int(4)})
+	item, next := IntersectIterator(counting(a), counting(b))()
+	t.assert("first of a & b is 2", next != nil && item.Equals(types.Int(2)))
+	t.assert("read more than needed", reads == 5)
+	t.assert("a ^ (nil) == a", len(sorted_items(t, SymmetricDifferenceIterator(a.Items(), nil))) == a.Size())
+}
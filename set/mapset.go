@@ -84,10 +84,20 @@ func (m *MapSet) Add(item types.Hashable) (err error) {
 }
 
 func (m *MapSet) Put(key types.Hashable, value interface{}) (err error) {
-	return m.Add(&types.MapEntry{key, value})
+	me := &types.MapEntry{key, value}
+	if m.Set.Has(me) {
+		// the set would keep the old entry (and so the old value)
+		if err := m.Set.Delete(me); err != nil {
+			return err
+		}
+	}
+	return m.Add(me)
 }
 
 func (m *MapSet) Get(key types.Hashable) (value interface{}, err error) {
+	if !m.Set.Has(asMapEntry(key)) {
+		return nil, errors.NotFound(key)
+	}
 	item, err := m.Set.Item(asMapEntry(key))
 	if err != nil {
 		return nil, err
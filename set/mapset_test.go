@@ -96,3 +96,14 @@ func TestMapSetIterators(x *testing.T) {
 	}
 	t.assert("uneven iteration", items == nil && keys == nil)
 }
+
+func TestMapSetPutReplaces(x *testing.T) {
+	t := (*T)(x)
+	m := NewMapSet(NewSortedSet(10))
+	t.assert_nil(m.Put(types.Int(1), "a"))
+	t.assert_nil(m.Put(types.Int(1), "b"))
+	v, err := m.Get(types.Int(1))
+	t.assert_nil(err)
+	t.assert(fmt.Sprintf("value %v", v), v == "b")
+	t.assert(fmt.Sprintf("size %v", m.Size()), m.Size() == 1)
+}
@@ -1,10 +1,18 @@
 package set
 
+import (
+	"log"
+)
+
 import (
 	"github.com/timtadh/data-structures/hashtable"
 	"github.com/timtadh/data-structures/types"
 )
 
+// Results of at least this many items (by the size hint) which do not need to
+// be a particular kind of set are HashSets rather than SortedSets.
+const hash_set_size_hint = 256
+
 func newSetBestType(a types.Set, sizeHint int) types.Set {
 	switch a.(type) {
 	case *MapSet:
@@ -13,7 +21,16 @@ func newSetBestType(a types.Set, sizeHint int) types.Set {
 		return NewSortedSet(sizeHint)
 	case *SetMap:
 		return NewSetMap(hashtable.NewLinearHash())
+	case *HashSet:
+		return NewHashSet(sizeHint)
+	case *BitSet:
+		return NewBitSet(0)
+	case *Roaring:
+		return NewRoaring()
 	default:
+		if sizeHint >= hash_set_size_hint {
+			return NewHashSet(sizeHint)
+		}
 		return NewSortedSet(sizeHint)
 	}
 }
@@ -58,6 +75,104 @@ func Subtract(a, b types.Set) (types.Set, error) {
 	return c, nil
 }
 
+// The items in exactly one of a and b as a new set
+func SymmetricDifference(a, b types.Set) (types.Set, error) {
+	c := newSetBestType(a, a.Size()+b.Size())
+	for item, next := a.Items()(); next != nil; item, next = next() {
+		if !b.Has(item) {
+			err := c.Add(item)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	for item, next := b.Items()(); next != nil; item, next = next() {
+		if !a.Has(item) {
+			err := c.Add(item)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return c, nil
+}
+
+// Do a and b have no items in common?
+func Disjoint(a, b types.Set) bool {
+	for item, next := a.Items()(); next != nil; item, next = next() {
+		if b.Has(item) {
+			return false
+		}
+	}
+	return true
+}
+
+// Iterates over every pair of an item of a with an item of b as a
+// *types.MapEntry with the item of a as the Key and the item of b as the
+// Value. The pairs are produced lazily in the order of a then b. (They are not
+// put in a set as MapEntries are equal when their keys are.)
+func CartesianProduct(a, b types.Set) types.KIterator {
+	ai := a.Items()
+	var ca types.Hashable
+	var bi types.KIterator
+	var kit types.KIterator
+	kit = func() (types.Hashable, types.KIterator) {
+		for ai != nil {
+			if bi != nil {
+				var cb types.Hashable
+				if cb, bi = bi(); bi != nil {
+					return &types.MapEntry{Key: ca, Value: cb}, kit
+				}
+			}
+			if ca, ai = ai(); ai != nil {
+				bi = b.Items()
+			}
+		}
+		return nil, nil
+	}
+	return kit
+}
+
+type SetIterator func() (set types.Set, next SetIterator)
+
+// Iterates over all 2^n subsets of s, starting with the empty set. The
+// subsets are made one at a time as the iterator is called and are of the
+// same kind as newSetBestType picks for s. Changing s while iterating does
+// not change the subsets.
+func PowerSet(s types.Set) SetIterator {
+	items := make([]types.Hashable, 0, s.Size())
+	for item, next := s.Items()(); next != nil; item, next = next() {
+		items = append(items, item)
+	}
+	// in counts up in binary: in[i] says if items[i] is in the next subset
+	in := make([]bool, len(items))
+	done := false
+	var sit SetIterator
+	sit = func() (types.Set, SetIterator) {
+		if done {
+			return nil, nil
+		}
+		c := newSetBestType(s, len(items))
+		for i, item := range items {
+			if in[i] {
+				if err := c.Add(item); err != nil {
+					log.Panic(err)
+				}
+			}
+		}
+		done = true
+		for i := range in {
+			in[i] = !in[i]
+			if in[i] {
+				done = false
+				break
+			}
+		}
+		return c, sit
+	}
+	return sit
+}
+
 func Subset(a, b types.Set) bool {
 	if a.Size() > b.Size() {
 		return false
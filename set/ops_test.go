@@ -0,0 +1,62 @@
+package set
+
+import "testing"
+
+import (
+	"fmt"
+)
+
+import (
+	"github.com/timtadh/data-structures/hashtable"
+	"github.com/timtadh/data-structures/types"
+)
+
+func TestGenericSymmetricDifference(x *testing.T) {
+	t := (*T)(x)
+	a := NewSetMap(hashtable.NewLinearHash())
+	t.assert_nil(a.Extend(FromSlice([]types.Hashable{types.Int(0), types.Int(1), types.Int(2)}).Items()))
+	b := FromSlice([]types.Hashable{types.Int(2), types.Int(3)})
+	c := FromSlice([]types.Hashable{types.Int(0), types.Int(1), types.Int(3)})
+	d := t.assert_set(SymmetricDifference(a, b))
+	t.assert("a ^ b == c", d.Size() == c.Size() && d.Subset(c))
+	d = t.assert_set(SymmetricDifference(b, a))
+	t.assert("b ^ a == c", d.(*SortedSet).Equals(c))
+}
+
+func TestCartesianProduct(x *testing.T) {
+	t := (*T)(x)
+	a := FromSlice([]types.Hashable{types.Int(0), types.Int(1), types.Int(2)})
+	b := FromSlice([]types.Hashable{types.String("x"), types.String("y")})
+	pairs := make([]string, 0, 6)
+	for item, next := CartesianProduct(a, b)(); next != nil; item, next = next() {
+		me := item.(*types.MapEntry)
+		pairs = append(pairs, fmt.Sprintf("%v%v", me.Key, me.Value))
+	}
+	t.assert(fmt.Sprintf("a x b == %v", pairs), fmt.Sprint(pairs) == "[0x 0y 1x 1y 2x 2y]")
+	_, next := CartesianProduct(a, NewSortedSet(0))()
+	t.assert("a x {} is empty", next == nil)
+	_, next = CartesianProduct(NewSortedSet(0), b)()
+	t.assert("{} x b is empty", next == nil)
+}
+
+func TestPowerSet(x *testing.T) {
+	t := (*T)(x)
+	a := FromSlice([]types.Hashable{types.Int(0), types.Int(1), types.Int(2), types.Int(3)})
+	seen := make(map[string]bool)
+	count := 0
+	for s, next := PowerSet(a)(); next != nil; s, next = next() {
+		if count == 0 {
+			t.assert("the first subset is empty", s.Size() == 0)
+		}
+		t.assert("a subset of a", s.Subset(a))
+		key := fmt.Sprint(s)
+		t.assert(fmt.Sprintf("%v seen twice", key), !seen[key])
+		seen[key] = true
+		count++
+	}
+	t.assert(fmt.Sprintf("|P(a)| == 16 not %v", count), count == 16)
+	s, next := PowerSet(NewSortedSet(0))()
+	t.assert("P({}) == {{}}", next != nil && s.Size() == 0)
+	_, next = next()
+	t.assert("P({}) == {{}}", next == nil)
+}
@@ -0,0 +1,679 @@
+package set
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/bits"
+	"sort"
+	"strings"
+)
+
+import (
+	"github.com/timtadh/data-structures/errors"
+	"github.com/timtadh/data-structures/types"
+)
+
+/* A compressed bitmap set of the integers in [0, 2^32) in the style of
+ * Roaring bitmaps (Chambi, Lemire, Kaser and Godin). The items are split by
+ * their high 16 bits into containers holding their low 16 bits. A container
+ * with at most 4096 items is a sorted array of uint16s and a fuller one is a
+ * 65536 bit bitmap, so an item takes at most 16 bits and sparse ids do not
+ * pay for the gaps between them as they do in a BitSet. Items may be of any
+ * integer type in the types package and come out of Items in ascending order
+ * as types.Int.
+ *
+ * Union, Intersect, Subtract and SymmetricDifference with another Roaring
+ * merge the containers: two arrays are merged item by item, otherwise the
+ * bitmaps are combined a word at a time.
+ */
+type Roaring struct {
+	keys       []uint16
+	containers []*roaring_container
+	size       int
+}
+
+// the most items in an array container
+const roaring_array_max = 4096
+
+// the words in a bitmap container
+const roaring_words = 1 << 16 / 64
+
+type roaring_container struct {
+	array  []uint16 // sorted, used when bitmap is nil
+	bitmap []uint64
+	card   int
+}
+
+func NewRoaring() *Roaring {
+	return &Roaring{}
+}
+
+func (s *Roaring) Copy() *Roaring {
+	c := &Roaring{
+		keys:       make([]uint16, len(s.keys)),
+		containers: make([]*roaring_container, len(s.containers)),
+		size:       s.size,
+	}
+	copy(c.keys, s.keys)
+	for i, rc := range s.containers {
+		c.containers[i] = rc.copy()
+	}
+	return c
+}
+
+func roaring_split(item types.Hashable) (hi, lo uint16, ok bool) {
+	i, ok := int_item(item, math.MaxUint32)
+	return uint16(i >> 16), uint16(i), ok
+}
+
+// the index of the container for hi or where it would go
+func (s *Roaring) find(hi uint16) (int, bool) {
+	i := sort.Search(len(s.keys), func(i int) bool { return s.keys[i] >= hi })
+	return i, i < len(s.keys) && s.keys[i] == hi
+}
+
+func (s *Roaring) Size() int {
+	return s.size
+}
+
+func (s *Roaring) Has(item types.Hashable) bool {
+	hi, lo, ok := roaring_split(item)
+	if !ok {
+		return false
+	}
+	i, has := s.find(hi)
+	return has && s.containers[i].has(lo)
+}
+
+func (s *Roaring) Item(item types.Hashable) (types.Hashable, error) {
+	if !s.Has(item) {
+		return nil, errors.NotFound(item)
+	}
+	i, _ := int_item(item, math.MaxUint32)
+	return types.Int(i), nil
+}
+
+func (s *Roaring) Add(item types.Hashable) (err error) {
+	hi, lo, ok := roaring_split(item)
+	if !ok {
+		return errors.Errorf("A Roaring only holds integers in [0, 2^32), got %v (%T)", item, item)
+	}
+	i, has := s.find(hi)
+	if !has {
+		s.keys = append(s.keys, 0)
+		copy(s.keys[i+1:], s.keys[i:])
+		s.keys[i] = hi
+		s.containers = append(s.containers, nil)
+		copy(s.containers[i+1:], s.containers[i:])
+		s.containers[i] = &roaring_container{}
+	}
+	if s.containers[i].add(lo) {
+		s.size++
+	}
+	return nil
+}
+
+func (s *Roaring) Delete(item types.Hashable) (err error) {
+	hi, lo, ok := roaring_split(item)
+	if !ok {
+		return errors.NotFound(item)
+	}
+	i, has := s.find(hi)
+	if !has || !s.containers[i].remove(lo) {
+		return errors.NotFound(item)
+	}
+	s.size--
+	if s.containers[i].card == 0 {
+		s.keys = append(s.keys[:i], s.keys[i+1:]...)
+		s.containers = append(s.containers[:i], s.containers[i+1:]...)
+	}
+	return nil
+}
+
+func (s *Roaring) Extend(items types.KIterator) (err error) {
+	for item, next := items(); next != nil; item, next = next() {
+		err := s.Add(item)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Roaring) Items() (it types.KIterator) {
+	keys, containers := s.keys, s.containers
+	c := -1
+	var lows types.KIterator
+	it = func() (types.Hashable, types.KIterator) {
+		var lo types.Hashable
+		for lows != nil {
+			if lo, lows = lows(); lows != nil {
+				return types.Int(int(keys[c])<<16 | int(lo.(types.Int))), it
+			}
+		}
+		c++
+		if c >= len(containers) {
+			return nil, nil
+		}
+		lows = containers[c].items()
+		return it()
+	}
+	return it
+}
+
+// The number of items less than i.
+func (s *Roaring) Rank(i int) int {
+	if i <= 0 {
+		return 0
+	} else if uint64(i) > math.MaxUint32 {
+		return s.size
+	}
+	hi, lo := uint16(i>>16), uint16(i)
+	rank := 0
+	for c, key := range s.keys {
+		if key > hi {
+			break
+		} else if key == hi {
+			rank += s.containers[c].rank(lo)
+			break
+		}
+		rank += s.containers[c].card
+	}
+	return rank
+}
+
+// The item with rank r, that is the r'th smallest item counting from 0.
+func (s *Roaring) Select(r int) (types.Int, error) {
+	if r < 0 || r >= s.size {
+		return 0, errors.Errorf("Select(%v) out of range [0, %v)", r, s.size)
+	}
+	for c, rc := range s.containers {
+		if r < rc.card {
+			return types.Int(int(s.keys[c])<<16 | int(rc.sel(r))), nil
+		}
+		r -= rc.card
+	}
+	panic(errors.Errorf("Roaring size is wrong"))
+}
+
+// Is b a set with the same items?
+func (s *Roaring) Equals(b types.Equatable) bool {
+	o, ok := b.(types.Set)
+	if !ok || o.Size() != s.Size() {
+		return false
+	}
+	return s.Subset(o)
+}
+
+func (s *Roaring) String() string {
+	items := make([]string, 0, s.Size())
+	for item, next := s.Items()(); next != nil; item, next = next() {
+		items = append(items, fmt.Sprintf("%v", item))
+	}
+	return "{" + strings.Join(items, ", ") + "}"
+}
+
+// Merges the containers of s and o. keep says which items are in the result
+// and op does the same to the words of two bitmaps.
+func (s *Roaring) combine(o *Roaring, keep func(inA, inB bool) bool, op func(a, b uint64) uint64) *Roaring {
+	c := NewRoaring()
+	push := func(key uint16, rc *roaring_container) {
+		if rc != nil && rc.card > 0 {
+			c.keys = append(c.keys, key)
+			c.containers = append(c.containers, rc)
+			c.size += rc.card
+		}
+	}
+	i, j := 0, 0
+	for i < len(s.keys) || j < len(o.keys) {
+		if j >= len(o.keys) || (i < len(s.keys) && s.keys[i] < o.keys[j]) {
+			if keep(true, false) {
+				push(s.keys[i], s.containers[i].copy())
+			}
+			i++
+		} else if i >= len(s.keys) || o.keys[j] < s.keys[i] {
+			if keep(false, true) {
+				push(o.keys[j], o.containers[j].copy())
+			}
+			j++
+		} else {
+			push(s.keys[i], s.containers[i].combine(o.containers[j], keep, op))
+			i++
+			j++
+		}
+	}
+	return c
+}
+
+func (s *Roaring) roaring(other types.Set) (*Roaring, error) {
+	if o, ok := other.(*Roaring); ok {
+		return o, nil
+	}
+	o := NewRoaring()
+	if err := o.Extend(other.Items()); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// Unions s with o and returns a new Roaring. o must only have integers in
+// [0, 2^32).
+func (s *Roaring) Union(other types.Set) (types.Set, error) {
+	o, err := s.roaring(other)
+	if err != nil {
+		return nil, err
+	}
+	return s.combine(o,
+		func(inA, inB bool) bool { return true },
+		func(a, b uint64) uint64 { return a | b }), nil
+}
+
+// Intersects s with o and returns a new Roaring.
+func (s *Roaring) Intersect(other types.Set) (types.Set, error) {
+	if o, ok := other.(*Roaring); ok {
+		return s.combine(o,
+			func(inA, inB bool) bool { return inA && inB },
+			func(a, b uint64) uint64 { return a & b }), nil
+	}
+	return s.filter(func(item types.Hashable) bool { return other.Has(item) }), nil
+}
+
+// Subtracts o from s and returns a new Roaring.
+func (s *Roaring) Subtract(other types.Set) (types.Set, error) {
+	if o, ok := other.(*Roaring); ok {
+		return s.combine(o,
+			func(inA, inB bool) bool { return inA && !inB },
+			func(a, b uint64) uint64 { return a &^ b }), nil
+	}
+	return s.filter(func(item types.Hashable) bool { return !other.Has(item) }), nil
+}
+
+// The items in exactly one of s and o as a new Roaring. o must only have
+// integers in [0, 2^32).
+func (s *Roaring) SymmetricDifference(other types.Set) (types.Set, error) {
+	o, err := s.roaring(other)
+	if err != nil {
+		return nil, err
+	}
+	return s.combine(o,
+		func(inA, inB bool) bool { return inA != inB },
+		func(a, b uint64) uint64 { return a ^ b }), nil
+}
+
+func (s *Roaring) filter(keep func(types.Hashable) bool) *Roaring {
+	c := NewRoaring()
+	for item, next := s.Items()(); next != nil; item, next = next() {
+		if keep(item) {
+			c.Add(item)
+		}
+	}
+	return c
+}
+
+// Do s and o have no items in common? With another Roaring it stops at the
+// first container which overlaps.
+func (s *Roaring) Disjoint(other types.Set) bool {
+	o, ok := other.(*Roaring)
+	if !ok {
+		return Disjoint(s, other)
+	}
+	i, j := 0, 0
+	for i < len(s.keys) && j < len(o.keys) {
+		if s.keys[i] < o.keys[j] {
+			i++
+		} else if o.keys[j] < s.keys[i] {
+			j++
+		} else if s.containers[i].any(o.containers[j], true) {
+			return false
+		} else {
+			i++
+			j++
+		}
+	}
+	return true
+}
+
+// Is s a subset of o? With another Roaring it stops at the first container
+// of s which o does not cover.
+func (s *Roaring) Subset(other types.Set) bool {
+	o, ok := other.(*Roaring)
+	if !ok {
+		return Subset(s, other)
+	} else if s.size > o.size {
+		return false
+	}
+	j := 0
+	for i, key := range s.keys {
+		for j < len(o.keys) && o.keys[j] < key {
+			j++
+		}
+		if j >= len(o.keys) || o.keys[j] != key || s.containers[i].any(o.containers[j], false) {
+			return false
+		}
+	}
+	return true
+}
+
+// Is s a proper subset of o?
+func (s *Roaring) ProperSubset(o types.Set) bool {
+	return s.Size() < o.Size() && s.Subset(o)
+}
+
+// Is s a superset of o?
+func (s *Roaring) Superset(o types.Set) bool {
+	return o.Subset(s)
+}
+
+// Is s a proper superset of o?
+func (s *Roaring) ProperSuperset(o types.Set) bool {
+	return o.ProperSubset(s)
+}
+
+// The number of containers then for each its key, its item count and its
+// items (a uint16 each) or bitmap (1024 uint64s), whichever it is. All little
+// endian.
+func (s *Roaring) MarshalBinary() ([]byte, error) {
+	n := 4
+	for _, rc := range s.containers {
+		n += 6 + rc.marshalled_size()
+	}
+	bytes := make([]byte, n)
+	binary.LittleEndian.PutUint32(bytes, uint32(len(s.keys)))
+	b := bytes[4:]
+	for c, rc := range s.containers {
+		binary.LittleEndian.PutUint16(b, s.keys[c])
+		binary.LittleEndian.PutUint32(b[2:], uint32(rc.card))
+		b = b[6:]
+		if rc.bitmap == nil {
+			for i, x := range rc.array {
+				binary.LittleEndian.PutUint16(b[2*i:], x)
+			}
+		} else {
+			for i, w := range rc.bitmap {
+				binary.LittleEndian.PutUint64(b[8*i:], w)
+			}
+		}
+		b = b[rc.marshalled_size():]
+	}
+	return bytes, nil
+}
+
+func (s *Roaring) UnmarshalBinary(bytes []byte) error {
+	short := errors.Errorf("A marshalled Roaring is too short")
+	if len(bytes) < 4 {
+		return short
+	}
+	n := int(binary.LittleEndian.Uint32(bytes))
+	b := bytes[4:]
+	u := NewRoaring()
+	for c := 0; c < n; c++ {
+		if len(b) < 6 {
+			return short
+		}
+		key := binary.LittleEndian.Uint16(b)
+		rc := &roaring_container{card: int(binary.LittleEndian.Uint32(b[2:]))}
+		b = b[6:]
+		if rc.card <= 0 || rc.card > 1<<16 || (c > 0 && key <= u.keys[c-1]) {
+			return errors.Errorf("A marshalled Roaring has a bad container %v", c)
+		} else if len(b) < rc.marshalled_size() {
+			return short
+		}
+		if rc.card <= roaring_array_max {
+			rc.array = make([]uint16, rc.card)
+			for i := range rc.array {
+				rc.array[i] = binary.LittleEndian.Uint16(b[2*i:])
+				if i > 0 && rc.array[i] <= rc.array[i-1] {
+					return errors.Errorf("A marshalled Roaring has an unsorted container %v", c)
+				}
+			}
+		} else {
+			rc.bitmap = make([]uint64, roaring_words)
+			count := 0
+			for i := range rc.bitmap {
+				rc.bitmap[i] = binary.LittleEndian.Uint64(b[8*i:])
+				count += bits.OnesCount64(rc.bitmap[i])
+			}
+			if count != rc.card {
+				return errors.Errorf("A marshalled Roaring has a bad container %v", c)
+			}
+		}
+		b = b[rc.marshalled_size():]
+		u.keys = append(u.keys, key)
+		u.containers = append(u.containers, rc)
+		u.size += rc.card
+	}
+	if len(b) != 0 {
+		return errors.Errorf("A marshalled Roaring has %v extra bytes", len(b))
+	}
+	*s = *u
+	return nil
+}
+
+func (rc *roaring_container) copy() *roaring_container {
+	c := &roaring_container{card: rc.card}
+	if rc.bitmap != nil {
+		c.bitmap = make([]uint64, roaring_words)
+		copy(c.bitmap, rc.bitmap)
+	} else {
+		c.array = make([]uint16, len(rc.array))
+		copy(c.array, rc.array)
+	}
+	return c
+}
+
+func (rc *roaring_container) marshalled_size() int {
+	if rc.card <= roaring_array_max {
+		return 2 * rc.card
+	}
+	return 8 * roaring_words
+}
+
+func (rc *roaring_container) search(x uint16) (int, bool) {
+	i := sort.Search(len(rc.array), func(i int) bool { return rc.array[i] >= x })
+	return i, i < len(rc.array) && rc.array[i] == x
+}
+
+func (rc *roaring_container) has(x uint16) bool {
+	if rc.bitmap != nil {
+		return rc.bitmap[x/64]&(1<<(x%64)) != 0
+	}
+	_, has := rc.search(x)
+	return has
+}
+
+func (rc *roaring_container) add(x uint16) bool {
+	if rc.bitmap != nil {
+		if rc.bitmap[x/64]&(1<<(x%64)) != 0 {
+			return false
+		}
+		rc.bitmap[x/64] |= 1 << (x % 64)
+		rc.card++
+		return true
+	}
+	i, has := rc.search(x)
+	if has {
+		return false
+	}
+	rc.array = append(rc.array, 0)
+	copy(rc.array[i+1:], rc.array[i:])
+	rc.array[i] = x
+	rc.card++
+	rc.normalize()
+	return true
+}
+
+func (rc *roaring_container) remove(x uint16) bool {
+	if rc.bitmap != nil {
+		if rc.bitmap[x/64]&(1<<(x%64)) == 0 {
+			return false
+		}
+		rc.bitmap[x/64] &^= 1 << (x % 64)
+		rc.card--
+		rc.normalize()
+		return true
+	}
+	i, has := rc.search(x)
+	if !has {
+		return false
+	}
+	rc.array = append(rc.array[:i], rc.array[i+1:]...)
+	rc.card--
+	return true
+}
+
+// switches to a bitmap past roaring_array_max items and back to an array at
+// or below it
+func (rc *roaring_container) normalize() {
+	if rc.bitmap == nil && rc.card > roaring_array_max {
+		rc.bitmap = rc.to_bitmap()
+		rc.array = nil
+	} else if rc.bitmap != nil && rc.card <= roaring_array_max {
+		array := make([]uint16, 0, rc.card)
+		for lo, next := rc.items()(); next != nil; lo, next = next() {
+			array = append(array, uint16(lo.(types.Int)))
+		}
+		rc.array = array
+		rc.bitmap = nil
+	}
+}
+
+func (rc *roaring_container) to_bitmap() []uint64 {
+	if rc.bitmap != nil {
+		return rc.bitmap
+	}
+	bitmap := make([]uint64, roaring_words)
+	for _, x := range rc.array {
+		bitmap[x/64] |= 1 << (x % 64)
+	}
+	return bitmap
+}
+
+// the low 16 bits of the items as types.Int
+func (rc *roaring_container) items() (it types.KIterator) {
+	if rc.bitmap == nil {
+		array := rc.array
+		i := -1
+		it = func() (types.Hashable, types.KIterator) {
+			i++
+			if i >= len(array) {
+				return nil, nil
+			}
+			return types.Int(array[i]), it
+		}
+		return it
+	}
+	bitmap := rc.bitmap
+	w := 0
+	word := bitmap[0]
+	it = func() (types.Hashable, types.KIterator) {
+		for word == 0 {
+			w++
+			if w >= len(bitmap) {
+				return nil, nil
+			}
+			word = bitmap[w]
+		}
+		b := bits.TrailingZeros64(word)
+		word &^= 1 << uint(b)
+		return types.Int(w*64 + b), it
+	}
+	return it
+}
+
+// the number of items less than x
+func (rc *roaring_container) rank(x uint16) int {
+	if rc.bitmap == nil {
+		i, _ := rc.search(x)
+		return i
+	}
+	rank := bits.OnesCount64(rc.bitmap[x/64] & (1<<(x%64) - 1))
+	for _, word := range rc.bitmap[:x/64] {
+		rank += bits.OnesCount64(word)
+	}
+	return rank
+}
+
+func (rc *roaring_container) sel(r int) uint16 {
+	if rc.bitmap == nil {
+		return rc.array[r]
+	}
+	for w, word := range rc.bitmap {
+		c := bits.OnesCount64(word)
+		if r < c {
+			return uint16(w*64 + select_word(word, r))
+		}
+		r -= c
+	}
+	panic(errors.Errorf("Roaring container count is wrong"))
+}
+
+// Has rc an item which is in o (or which is not in o if in_o is false)? It
+// stops at the first one.
+func (rc *roaring_container) any(o *roaring_container, in_o bool) bool {
+	if rc.bitmap != nil && o.bitmap != nil {
+		for i, word := range rc.bitmap {
+			if in_o && word&o.bitmap[i] != 0 || !in_o && word&^o.bitmap[i] != 0 {
+				return true
+			}
+		}
+		return false
+	} else if rc.bitmap == nil {
+		for _, x := range rc.array {
+			if o.has(x) == in_o {
+				return true
+			}
+		}
+		return false
+	} else if !in_o && rc.card > o.card {
+		return true
+	}
+	// an array o is smaller than the bitmap rc so walk o instead
+	found := 0
+	for _, x := range o.array {
+		if rc.has(x) {
+			if in_o {
+				return true
+			}
+			found++
+		}
+	}
+	return !in_o && found < rc.card
+}
+
+func (rc *roaring_container) combine(o *roaring_container, keep func(inA, inB bool) bool, op func(a, b uint64) uint64) *roaring_container {
+	c := &roaring_container{}
+	if rc.bitmap == nil && o.bitmap == nil {
+		c.array = make([]uint16, 0, len(rc.array))
+		i, j := 0, 0
+		for i < len(rc.array) || j < len(o.array) {
+			if j >= len(o.array) || (i < len(rc.array) && rc.array[i] < o.array[j]) {
+				if keep(true, false) {
+					c.array = append(c.array, rc.array[i])
+				}
+				i++
+			} else if i >= len(rc.array) || o.array[j] < rc.array[i] {
+				if keep(false, true) {
+					c.array = append(c.array, o.array[j])
+				}
+				j++
+			} else {
+				if keep(true, true) {
+					c.array = append(c.array, rc.array[i])
+				}
+				i++
+				j++
+			}
+		}
+		c.card = len(c.array)
+	} else {
+		a, b := rc.to_bitmap(), o.to_bitmap()
+		c.bitmap = make([]uint64, roaring_words)
+		for i := range c.bitmap {
+			c.bitmap[i] = op(a[i], b[i])
+			c.card += bits.OnesCount64(c.bitmap[i])
+		}
+	}
+	c.normalize()
+	return c
+}
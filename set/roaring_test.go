@@ -0,0 +1,117 @@
+package set
+
+import "testing"
+
+import (
+	"fmt"
+)
+
+import (
+	"github.com/timtadh/data-structures/types"
+)
+
+func TestRoaringRandom(x *testing.T) {
+	t := (*T)(x)
+	// enough items in each container to switch between arrays and bitmaps
+	set := NewRoaring()
+	check_int_set(t, set, 3*65536, 50000)
+	for _, rc := range set.containers {
+		t.assert("containers are normalized", (rc.bitmap == nil) == (rc.card <= roaring_array_max))
+	}
+	t.assert("negative items are not allowed", set.Add(types.Int(-1)) != nil)
+	t.assert("items past 2^32 are not allowed", set.Add(types.Int(1<<32)) != nil)
+	t.assert_nil(set.Add(types.UInt32(1<<32 - 1)))
+	t.assert("the largest item", set.Has(types.Int(1<<32-1)) && set.Rank(1<<32) == set.Size())
+	// and a sparse one
+	check_int_set(t, NewRoaring(), 1<<20, 200)
+}
+
+func TestRoaringAlgebra(x *testing.T) {
+	t := (*T)(x)
+	a := NewRoaring()
+	b := NewRoaring()
+	// a has a bitmap then an array container, b the other way around
+	for i := 0; i < 131072; i += 2 {
+		if i < 65536 || i%64 == 0 {
+			t.assert_nil(a.Add(types.Int(i)))
+		}
+	}
+	for i := 0; i < 196608; i += 3 {
+		if i >= 65536 || i%99 == 0 {
+			t.assert_nil(b.Add(types.Int(i)))
+		}
+	}
+	inA := func(i int) bool { return i%2 == 0 && (i < 65536 || (i < 131072 && i%64 == 0)) }
+	inB := func(i int) bool { return i%3 == 0 && (i >= 65536 || i%99 == 0) }
+	other := NewBitSet(0)
+	t.assert_nil(other.Extend(b.Items()))
+	for _, o := range []types.Set{b, other} {
+		u := t.assert_set(a.Union(o))
+		n := t.assert_set(a.Intersect(o))
+		d := t.assert_set(a.Subtract(o))
+		s := t.assert_set(a.SymmetricDifference(o))
+		counts := make([]int, 4)
+		for i := 0; i < 196608; i++ {
+			a, b := inA(i), inB(i)
+			t.assert(fmt.Sprintf("%v in a | b", i), u.Has(types.Int(i)) == (a || b))
+			t.assert(fmt.Sprintf("%v in a & b", i), n.Has(types.Int(i)) == (a && b))
+			t.assert(fmt.Sprintf("%v in a - b", i), d.Has(types.Int(i)) == (a && !b))
+			t.assert(fmt.Sprintf("%v in a ^ b", i), s.Has(types.Int(i)) == (a != b))
+			for j, in := range []bool{a || b, a && b, a && !b, a != b} {
+				if in {
+					counts[j]++
+				}
+			}
+		}
+		t.assert(fmt.Sprintf("sizes %v", counts), u.Size() == counts[0] && n.Size() == counts[1] && d.Size() == counts[2] && s.Size() == counts[3])
+		t.assert("a and b overlap", !a.Disjoint(o))
+		t.assert("a & b subset of a", n.Subset(a) && n.ProperSubset(o) && o.Superset(n))
+	}
+	t.assert("b == bitset b", b.Equals(other) && b.Equals(b.Copy()) && !b.Equals(a))
+}
+
+func TestRoaringDisjointSubset(x *testing.T) {
+	t := (*T)(x)
+	roaring := func(from, to, step int) *Roaring {
+		r := NewRoaring()
+		for i := from; i < to; i += step {
+			t.assert_nil(r.Add(types.Int(i)))
+		}
+		return r
+	}
+	// bitmap and array containers in the same and in different chunks
+	sets := []*Roaring{
+		NewRoaring(),
+		roaring(0, 65536, 2),
+		roaring(0, 65536, 4),
+		roaring(1, 65536, 2),
+		roaring(0, 65536, 100),
+		roaring(50, 65536, 100),
+		roaring(65536, 131072, 2),
+		roaring(0, 196608, 3),
+		roaring(0, 196608, 6),
+		roaring(65536, 65540, 1),
+	}
+	for i, a := range sets {
+		for j, b := range sets {
+			t.assert(fmt.Sprintf("%v disjoint %v", i, j), a.Disjoint(b) == Disjoint(a, b))
+			t.assert(fmt.Sprintf("%v subset of %v", i, j), a.Subset(b) == Subset(a, b))
+		}
+	}
+	t.assert("a subset with a bitmap", sets[2].Subset(sets[1]) && !sets[1].Subset(sets[2]))
+	t.assert("disjoint bitmaps", sets[1].Disjoint(sets[3]) && !sets[1].Disjoint(sets[2]))
+}
+
+func TestRoaringMarshal(x *testing.T) {
+	t := (*T)(x)
+	set := NewRoaring()
+	model := check_int_set(t, set, 2*65536, 20000)
+	bytes, err := set.MarshalBinary()
+	t.assert_nil(err)
+	set2 := NewRoaring()
+	t.assert_nil(set2.UnmarshalBinary(bytes))
+	t.assert("unmarshalled set is the same", set2.Equals(set) && set2.Size() == len(model))
+	t.assert("too short", set2.UnmarshalBinary(bytes[:len(bytes)-1]) != nil)
+	t.assert("too long", set2.UnmarshalBinary(append(bytes, 0)) != nil)
+	t.assert("set2 is unchanged by a failed unmarshal", set2.Equals(set))
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/timtadh/data-structures/errors"
+	"github.com/timtadh/data-structures/hashtable"
 	"github.com/timtadh/data-structures/types"
 )
 
@@ -31,9 +32,11 @@ func (s *SetMap) Items() types.KIterator {
 	return s.Keys()
 }
 
-// unimplemented
 func (s *SetMap) Item(item types.Hashable) (types.Hashable, error) {
-	return nil, errors.Errorf("un-implemented")
+	if !s.Has(item) {
+		return nil, errors.NotFound(item)
+	}
+	return item, nil
 }
 
 // unimplemented
@@ -75,6 +78,40 @@ func (s *SetMap) Subtract(other types.Set) (types.Set, error) {
 	return Subtract(s, other)
 }
 
+// The items in exactly one of s and o as a new SetMap (with a LinearHash).
+// Copies the larger set then adds or removes each item of the smaller one.
+func (s *SetMap) SymmetricDifference(other types.Set) (types.Set, error) {
+	var large, small types.Set = s, other
+	if small.Size() > large.Size() {
+		large, small = small, large
+	}
+	c := NewSetMap(hashtable.NewLinearHash())
+	if err := c.Extend(large.Items()); err != nil {
+		return nil, err
+	}
+	for item, next := small.Items()(); next != nil; item, next = next() {
+		var err error
+		if c.Has(item) {
+			err = c.Delete(item)
+		} else {
+			err = c.Add(item)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// Do s and o have no items in common? Looks up the items of the smaller set
+// in the larger one.
+func (s *SetMap) Disjoint(other types.Set) bool {
+	if other.Size() < s.Size() {
+		return Disjoint(other, s)
+	}
+	return Disjoint(s, other)
+}
+
 // Is s a subset of o?
 func (s *SetMap) Subset(o types.Set) bool {
 	return Subset(s, o)
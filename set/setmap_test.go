@@ -16,6 +16,17 @@ func TestNewSetMap(t *testing.T) {
 	t.Log(s.Size())
 }
 
+func TestSetMapItem(x *testing.T) {
+	t := (*T)(x)
+	s := NewSetMap(hashtable.NewLinearHash())
+	t.assert_nil(s.Add(types.Int(1)))
+	item, err := s.Item(types.Int(1))
+	t.assert_nil(err)
+	t.assert(fmt.Sprintf("item %v", item), item.Equals(types.Int(1)))
+	_, err = s.Item(types.Int(2))
+	t.assert("got a missing item", err != nil)
+}
+
 func TestSetMapAddHasDelete(x *testing.T) {
 	t := (*T)(x)
 	SIZE := 100
@@ -39,3 +50,28 @@ func TestSetMapAddHasDelete(x *testing.T) {
 		t.assert(fmt.Sprintf("i %v, !set.Has(item)", i), !set.Has(item))
 	}
 }
+
+func TestSetMapSymmetricDifferenceDisjoint(x *testing.T) {
+	t := (*T)(x)
+	a := NewSetMap(hashtable.NewLinearHash())
+	b := NewSetMap(hashtable.NewLinearHash())
+	c := NewSetMap(hashtable.NewLinearHash())
+	for i := 0; i < 100; i++ {
+		t.assert_nil(a.Add(types.Int(i)))
+	}
+	for i := 50; i < 120; i++ {
+		t.assert_nil(b.Add(types.Int(i)))
+	}
+	for i := 200; i < 210; i++ {
+		t.assert_nil(c.Add(types.Int(i)))
+	}
+	for _, d := range []types.Set{t.assert_set(a.SymmetricDifference(b)), t.assert_set(b.SymmetricDifference(a))} {
+		t.assert("|a ^ b| == 70", d.Size() == 70)
+		for i := 0; i < 120; i++ {
+			t.assert(fmt.Sprintf("%v in a ^ b", i), d.Has(types.Int(i)) == (i < 50 || i >= 100))
+		}
+	}
+	t.assert("a and b overlap", !a.Disjoint(b) && !b.Disjoint(a))
+	t.assert("a and c are disjoint", a.Disjoint(c) && c.Disjoint(a))
+	t.assert("a and c are disjoint", Disjoint(a, c) && !Disjoint(a, b))
+}
@@ -102,58 +102,59 @@ func (s *SortedSet) Union(other types.Set) (types.Set, error) {
 	}
 }
 
-func (s *SortedSet) union(o *SortedSet) (n *SortedSet, err error) {
-	n = NewSortedSet(s.Size() + o.Size() + 10)
-	cs, si := s.Items()()
-	co, oi := o.Items()()
-	for si != nil || oi != nil {
-		var err error
-		if si == nil {
-			err = n.Add(co)
-			co, oi = oi()
-		} else if oi == nil {
-			err = n.Add(cs)
-			cs, si = si()
-		} else if cs.Less(co) {
-			err = n.Add(cs)
-			cs, si = si()
-		} else {
-			err = n.Add(co)
-			co, oi = oi()
-		}
-		if err != nil {
-			return nil, err
-		}
-	}
-	return n, nil
+func (s *SortedSet) union(o *SortedSet) (*SortedSet, error) {
+	return from_sorted(UnionIterator(s.Items(), o.Items()), s.Size()+o.Size())
 }
 
-// Unions s with o and returns a new Sorted Set
+// Intersects s with o and returns a new Sorted Set
 func (s *SortedSet) Intersect(other types.Set) (types.Set, error) {
-	return Intersect(s, other)
+	if o, ok := other.(*SortedSet); ok {
+		return from_sorted(IntersectIterator(s.Items(), o.Items()), s.Size())
+	} else {
+		return Intersect(s, other)
+	}
 }
 
-// Unions s with o and returns a new Sorted Set
+// Subtracts o from s and returns a new Sorted Set
 func (s *SortedSet) Subtract(other types.Set) (types.Set, error) {
-	return Subtract(s, other)
+	if o, ok := other.(*SortedSet); ok {
+		return from_sorted(SubtractIterator(s.Items(), o.Items()), s.Size())
+	} else {
+		return Subtract(s, other)
+	}
+}
+
+// The items in exactly one of s and o as a new Sorted Set. If o is not a
+// SortedSet it is sorted first.
+func (s *SortedSet) SymmetricDifference(other types.Set) (types.Set, error) {
+	o, ok := other.(*SortedSet)
+	if !ok {
+		o = SortedFromSet(other)
+	}
+	return from_sorted(SymmetricDifferenceIterator(s.Items(), o.Items()), s.Size()+o.Size())
+}
+
+// Do s and o have no items in common? Linear when o is a SortedSet.
+func (s *SortedSet) Disjoint(other types.Set) bool {
+	if o, ok := other.(*SortedSet); ok {
+		return !s.Overlap(o)
+	} else {
+		return Disjoint(s, other)
+	}
 }
 
 // Are there any overlapping elements?
 func (s *SortedSet) Overlap(o *SortedSet) bool {
-	cs, si := s.Items()()
-	co, oi := o.Items()()
-	for si != nil && oi != nil {
-		s := cs.(types.Hashable)
-		o := co.(types.Hashable)
-		if s.Equals(o) {
-			return true
-		} else if s.Less(o) {
-			cs, si = si()
-		} else {
-			co, oi = oi()
-		}
+	return Overlapping(s.Items(), o.Items())
+}
+
+// Builds a set from an iterator in ascending order in linear time.
+func from_sorted(items types.KIterator, sizeHint int) (*SortedSet, error) {
+	n := NewSortedSet(sizeHint)
+	if err := n.Extend(items); err != nil {
+		return nil, err
 	}
-	return false
+	return n, nil
 }
 
 // Is s a subset of o?
@@ -8,6 +8,7 @@ import (
 )
 
 import (
+	"github.com/timtadh/data-structures/hashtable"
 	"github.com/timtadh/data-structures/list"
 	"github.com/timtadh/data-structures/types"
 )
@@ -226,3 +227,31 @@ func TestSubsetSuperset(x *testing.T) {
 	t.assert("a superset c", a.ProperSuperset(c))
 	t.assert("b superset c", b.ProperSuperset(c))
 }
+
+func TestSymmetricDifference(x *testing.T) {
+	t := (*T)(x)
+	a := FromSlice([]types.Hashable{types.Int(0), types.Int(1), types.Int(2), types.Int(3)})
+	b := FromSlice([]types.Hashable{types.Int(1), types.Int(2), types.Int(4)})
+	c := FromSlice([]types.Hashable{types.Int(0), types.Int(3), types.Int(4)})
+	e := FromSlice([]types.Hashable{})
+	t.assert("a ^ b == c", t.assert_set(a.SymmetricDifference(b)).Equals(c))
+	t.assert("b ^ a == c", t.assert_set(b.SymmetricDifference(a)).Equals(c))
+	t.assert("a ^ a == e", t.assert_set(a.SymmetricDifference(a)).Equals(e))
+	t.assert("a ^ e == a", t.assert_set(a.SymmetricDifference(e)).Equals(a))
+	m := NewSetMap(hashtable.NewLinearHash())
+	t.assert_nil(m.Extend(b.Items()))
+	t.assert("a ^ map(b) == c", t.assert_set(a.SymmetricDifference(m)).Equals(c))
+}
+
+func TestDisjoint(x *testing.T) {
+	t := (*T)(x)
+	a := FromSlice([]types.Hashable{types.Int(0), types.Int(1), types.Int(2), types.Int(3)})
+	b := FromSlice([]types.Hashable{types.Int(3), types.Int(4)})
+	c := FromSlice([]types.Hashable{types.Int(5), types.Int(4)})
+	t.assert("a and b overlap", !a.Disjoint(b))
+	t.assert("a and c are disjoint", a.Disjoint(c))
+	t.assert("a and the empty set are disjoint", a.Disjoint(NewSortedSet(0)))
+	m := NewSetMap(hashtable.NewLinearHash())
+	t.assert_nil(m.Extend(b.Items()))
+	t.assert("a and map(b) overlap", !a.Disjoint(m))
+}
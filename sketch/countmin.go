@@ -0,0 +1,124 @@
+package sketch
+
+import (
+	"encoding/binary"
+	"math"
+	"math/bits"
+)
+
+import (
+	"github.com/timtadh/data-structures/errors"
+	"github.com/timtadh/data-structures/filter"
+	"github.com/timtadh/data-structures/types"
+)
+
+/* A Count-Min sketch (Cormode and Muthukrishnan) estimates how many times each
+ * item was added. It has depth rows of width counters. An item adds to one
+ * counter in each row and its estimate is the least of those counters, so it
+ * never underestimates. With a width of e/epsilon and a depth of ln(1/delta)
+ * an estimate is over by more than epsilon*Total with probability at most
+ * delta.
+ */
+type CountMin struct {
+	width, depth uint64
+	counters     []uint64 // row major
+	total        uint64
+}
+
+// Makes a Count-Min sketch whose estimates are within epsilon*Total of the
+// true counts with probability 1-delta. Both must be in (0, 1).
+func NewCountMin(epsilon, delta float64) *CountMin {
+	if epsilon <= 0 || epsilon >= 1 || delta <= 0 || delta >= 1 {
+		panic(errors.Errorf("epsilon and delta must be in (0, 1), got %v and %v", epsilon, delta))
+	}
+	return NewCountMinSize(int(math.Ceil(math.E/epsilon)), int(math.Ceil(math.Log(1/delta))))
+}
+
+// Makes a Count-Min sketch with depth rows of width counters.
+func NewCountMinSize(width, depth int) *CountMin {
+	if width < 1 || depth < 1 {
+		panic(errors.Errorf("A Count-Min sketch needs a width and depth of at least 1"))
+	}
+	return &CountMin{
+		width:    uint64(width),
+		depth:    uint64(depth),
+		counters: make([]uint64, width*depth),
+	}
+}
+
+func (c *CountMin) Width() int {
+	return int(c.width)
+}
+
+func (c *CountMin) Depth() int {
+	return int(c.depth)
+}
+
+// The sum of the counts added.
+func (c *CountMin) Total() uint64 {
+	return c.total
+}
+
+func (c *CountMin) Add(item types.Hashable, count uint64) {
+	h1, h2 := filter.Hash(item)
+	for i := uint64(0); i < c.depth; i++ {
+		c.counters[i*c.width+(h1+i*h2)%c.width] += count
+	}
+	c.total += count
+}
+
+// The estimated count of the item. It is at least the true count.
+func (c *CountMin) Count(item types.Hashable) uint64 {
+	h1, h2 := filter.Hash(item)
+	min := uint64(math.MaxUint64)
+	for i := uint64(0); i < c.depth; i++ {
+		if x := c.counters[i*c.width+(h1+i*h2)%c.width]; x < min {
+			min = x
+		}
+	}
+	return min
+}
+
+// Adds the counts of o to c. They must have the same width and depth.
+func (c *CountMin) Merge(o *CountMin) error {
+	if c.width != o.width || c.depth != o.depth {
+		return errors.Errorf("Can not merge Count-Min sketches of different sizes")
+	}
+	for i, x := range o.counters {
+		c.counters[i] += x
+	}
+	c.total += o.total
+	return nil
+}
+
+// The width, depth and total then the counters, little endian.
+func (c *CountMin) MarshalBinary() ([]byte, error) {
+	bytes := make([]byte, 24+8*len(c.counters))
+	binary.LittleEndian.PutUint64(bytes[0:], c.width)
+	binary.LittleEndian.PutUint64(bytes[8:], c.depth)
+	binary.LittleEndian.PutUint64(bytes[16:], c.total)
+	for i, x := range c.counters {
+		binary.LittleEndian.PutUint64(bytes[24+8*i:], x)
+	}
+	return bytes, nil
+}
+
+func (c *CountMin) UnmarshalBinary(bytes []byte) error {
+	if len(bytes) < 24 {
+		return errors.Errorf("A marshalled Count-Min sketch is at least 24 bytes, got %v", len(bytes))
+	}
+	width := binary.LittleEndian.Uint64(bytes[0:])
+	depth := binary.LittleEndian.Uint64(bytes[8:])
+	if hi, size := bits.Mul64(width, depth); width == 0 || depth == 0 || hi != 0 ||
+		uint64(len(bytes)-24)/8 != size || len(bytes)%8 != 0 {
+		return errors.Errorf("A marshalled Count-Min sketch has a bad size")
+	}
+	c.width = width
+	c.depth = depth
+	c.total = binary.LittleEndian.Uint64(bytes[16:])
+	c.counters = make([]uint64, width*depth)
+	for i := range c.counters {
+		c.counters[i] = binary.LittleEndian.Uint64(bytes[24+8*i:])
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+/*
+Package sketch has small summaries of streams of items: HyperLogLog counts the
+distinct items, Count-Min estimates how often each item occurred and
+Space-Saving finds the most frequent items. Each takes types.Hashable items
+(hashed with filter.Hash), uses a fixed amount of memory no matter how long
+the stream is, can Merge a sketch of the same shape made elsewhere (another
+shard or process) and implements MarshalBinary and UnmarshalBinary.
+*/
+package sketch
+
+import (
+	"math"
+	"math/bits"
+)
+
+import (
+	"github.com/timtadh/data-structures/errors"
+	"github.com/timtadh/data-structures/filter"
+	"github.com/timtadh/data-structures/types"
+)
+
+/* A HyperLogLog (Flajolet, Fusy, Gandouet and Meunier) estimates the number of
+ * distinct items added in 2^precision bytes. The first precision bits of an
+ * item's hash pick a register which keeps the longest run of leading zeros
+ * seen in the rest of the hash. The standard error of Count is about
+ * 1.04/sqrt(2^precision), so 1.6% at the default precision of 12 (4KB).
+ */
+type HyperLogLog struct {
+	p         uint
+	registers []uint8
+}
+
+const DefaultPrecision = 12
+
+// Makes a HyperLogLog with 2^precision registers. The precision must be in
+// [4, 18].
+func NewHyperLogLog(precision int) *HyperLogLog {
+	if precision < 4 || precision > 18 {
+		panic(errors.Errorf("HyperLogLog precision must be in [4, 18], got %v", precision))
+	}
+	return &HyperLogLog{
+		p:         uint(precision),
+		registers: make([]uint8, 1<<uint(precision)),
+	}
+}
+
+func (h *HyperLogLog) Add(item types.Hashable) {
+	hash, _ := filter.Hash(item)
+	r := hash >> (64 - h.p)
+	// the rank of the first 1 bit of the rest of the hash, which is at most
+	// 64 - p + 1 when the rest is all 0
+	rank := uint8(bits.LeadingZeros64(hash<<h.p|1<<(h.p-1)) + 1)
+	if rank > h.registers[r] {
+		h.registers[r] = rank
+	}
+}
+
+// The estimated number of distinct items added.
+func (h *HyperLogLog) Count() uint64 {
+	m := float64(len(h.registers))
+	sum := 0.0
+	zeros := 0
+	for _, r := range h.registers {
+		sum += 1 / float64(uint64(1)<<r)
+		if r == 0 {
+			zeros++
+		}
+	}
+	var alpha float64
+	switch len(h.registers) {
+	case 16:
+		alpha = 0.673
+	case 32:
+		alpha = 0.697
+	case 64:
+		alpha = 0.709
+	default:
+		alpha = 0.7213 / (1 + 1.079/m)
+	}
+	estimate := alpha * m * m / sum
+	if estimate <= 2.5*m && zeros > 0 {
+		// linear counting is better for small counts
+		estimate = m * math.Log(m/float64(zeros))
+	}
+	return uint64(estimate + 0.5)
+}
+
+// Adds the items of o to h, so h counts the distinct items added to either.
+// They must have the same precision.
+func (h *HyperLogLog) Merge(o *HyperLogLog) error {
+	if h.p != o.p {
+		return errors.Errorf("Can not merge HyperLogLogs of precision %v and %v", h.p, o.p)
+	}
+	for i, r := range o.registers {
+		if r > h.registers[i] {
+			h.registers[i] = r
+		}
+	}
+	return nil
+}
+
+// The precision then the registers.
+func (h *HyperLogLog) MarshalBinary() ([]byte, error) {
+	bytes := make([]byte, 1+len(h.registers))
+	bytes[0] = byte(h.p)
+	copy(bytes[1:], h.registers)
+	return bytes, nil
+}
+
+func (h *HyperLogLog) UnmarshalBinary(bytes []byte) error {
+	if len(bytes) < 1 || bytes[0] < 4 || bytes[0] > 18 || len(bytes) != 1+1<<bytes[0] {
+		return errors.Errorf("A marshalled HyperLogLog has a bad size")
+	}
+	h.p = uint(bytes[0])
+	h.registers = make([]uint8, 1<<h.p)
+	copy(h.registers, bytes[1:])
+	return nil
+}
@@ -0,0 +1,221 @@
+package sketch
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"testing"
+)
+
+import (
+	"github.com/timtadh/data-structures/types"
+)
+
+func key(i int) types.Hashable {
+	return types.String(fmt.Sprintf("key-%d", i))
+}
+
+func TestHyperLogLog(t *testing.T) {
+	for _, n := range []int{0, 10, 1000, 100000} {
+		h := NewHyperLogLog(DefaultPrecision)
+		for i := 0; i < n; i++ {
+			h.Add(key(i))
+			h.Add(key(i))
+		}
+		if e := math.Abs(float64(h.Count())-float64(n)) / math.Max(1, float64(n)); e > 0.05 {
+			t.Fatalf("counted %v of %v distinct items", h.Count(), n)
+		}
+	}
+}
+
+func TestHyperLogLogMergeMarshal(t *testing.T) {
+	a := NewHyperLogLog(10)
+	b := NewHyperLogLog(10)
+	for i := 0; i < 20000; i++ {
+		a.Add(key(i))
+		b.Add(key(i + 10000))
+	}
+	if err := a.Merge(b); err != nil {
+		t.Fatal(err)
+	}
+	if e := math.Abs(float64(a.Count())-30000) / 30000; e > 0.1 {
+		t.Fatalf("counted %v of 30000 distinct items", a.Count())
+	}
+	if a.Merge(NewHyperLogLog(11)) == nil {
+		t.Fatal("merged HyperLogLogs of different precisions")
+	}
+	bytes, err := a.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	u := new(HyperLogLog)
+	if err := u.UnmarshalBinary(bytes); err != nil {
+		t.Fatal(err)
+	} else if u.Count() != a.Count() {
+		t.Fatalf("unmarshalled count %v, expected %v", u.Count(), a.Count())
+	}
+	if u.UnmarshalBinary(bytes[:len(bytes)-1]) == nil {
+		t.Fatal("unmarshalled a truncated HyperLogLog")
+	}
+}
+
+// key(i) occurs 1000/(i+1) times, and there is a long tail of keys which
+// occur once
+func stream(add func(types.Hashable, uint64), tail int) uint64 {
+	total := uint64(0)
+	for i := 0; i < 100; i++ {
+		add(key(i), uint64(1000/(i+1)))
+		total += uint64(1000 / (i + 1))
+	}
+	for i := 0; i < tail; i++ {
+		add(key(1000+i), 1)
+	}
+	return total + uint64(tail)
+}
+
+func TestCountMin(t *testing.T) {
+	c := NewCountMin(0.001, 0.01)
+	total := stream(c.Add, 10000)
+	if c.Total() != total {
+		t.Fatalf("total is %v, expected %v", c.Total(), total)
+	}
+	for i := 0; i < 100; i++ {
+		count := c.Count(key(i))
+		expected := uint64(1000 / (i + 1))
+		if count < expected || float64(count-expected) > 0.001*float64(total) {
+			t.Fatalf("count of %v is %v, expected %v", key(i), count, expected)
+		}
+	}
+	o := NewCountMinSize(c.Width(), c.Depth())
+	o.Add(key(0), 5)
+	if err := c.Merge(o); err != nil {
+		t.Fatal(err)
+	} else if c.Count(key(0)) < 1005 || c.Total() != total+5 {
+		t.Fatal("merge lost counts")
+	}
+	if c.Merge(NewCountMinSize(3, 3)) == nil {
+		t.Fatal("merged Count-Min sketches of different sizes")
+	}
+	bytes, err := c.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	u := new(CountMin)
+	if err := u.UnmarshalBinary(bytes); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		if u.Count(key(i)) != c.Count(key(i)) {
+			t.Fatalf("unmarshalled count of %v differs", key(i))
+		}
+	}
+	if u.UnmarshalBinary(bytes[:len(bytes)-8]) == nil {
+		t.Fatal("unmarshalled a truncated sketch")
+	}
+	// width*depth overflows to 0
+	header := make([]byte, 24)
+	binary.LittleEndian.PutUint64(header[0:], 1<<63)
+	binary.LittleEndian.PutUint64(header[8:], 2)
+	if u.UnmarshalBinary(header) == nil {
+		t.Fatal("unmarshalled a sketch whose size overflows")
+	}
+}
+
+func check_top(t *testing.T, s *SpaceSaving, n int) {
+	top := s.Top(n)
+	if len(top) != n {
+		t.Fatalf("top %v has %v items", n, len(top))
+	}
+	for i, hh := range top {
+		if !hh.Item.Equals(key(i)) {
+			t.Fatalf("top %v is %v, expected %v", i, hh, key(i))
+		}
+		expected := uint64(1000 / (i + 1))
+		if hh.Count < expected || hh.Count-hh.Error > expected {
+			t.Fatalf("count of %v is %v (error %v), expected %v", key(i), hh.Count, hh.Error, expected)
+		}
+	}
+}
+
+func TestSpaceSaving(t *testing.T) {
+	s := NewSpaceSaving(100)
+	total := stream(s.Add, 1000)
+	if s.Total() != total {
+		t.Fatalf("total is %v, expected %v", s.Total(), total)
+	}
+	// key(i) for i < 10 occurs at least 90 times, more than total/k
+	check_top(t, s, 10)
+	if count, over := s.Count(types.String("never added")); count < over || count > total/100 {
+		t.Fatalf("count of a missing item is %v (error %v)", count, over)
+	}
+}
+
+func TestSpaceSavingMerge(t *testing.T) {
+	a := NewSpaceSaving(100)
+	b := NewSpaceSaving(100)
+	stream(func(item types.Hashable, count uint64) {
+		a.Add(item, count/2)
+		b.Add(item, count-count/2)
+	}, 0)
+	for i := 0; i < 1000; i++ {
+		a.Add(key(1000+i), 1)
+		b.Add(key(2000+i), 1)
+	}
+	if err := a.Merge(b); err != nil {
+		t.Fatal(err)
+	}
+	check_top(t, a, 10)
+	if a.Merge(NewSpaceSaving(10)) == nil {
+		t.Fatal("merged SpaceSavings of different sizes")
+	}
+}
+
+func TestSpaceSavingMarshal(t *testing.T) {
+	s := NewSpaceSaving(100)
+	stream(s.Add, 1000)
+	marshal, unmarshal := types.StringMarshals()
+	bytes, err := NewMSpaceSaving(s, marshal, unmarshal).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	u := &MSpaceSaving{MarshalItem: marshal, UnmarshalItem: unmarshal}
+	if err := u.UnmarshalBinary(bytes); err != nil {
+		t.Fatal(err)
+	}
+	check_top(t, &u.SpaceSaving, 10)
+	if u.Total() != s.Total() {
+		t.Fatal("unmarshalled total differs")
+	}
+	u.Add(key(0), 1)
+	if count, _ := u.Count(key(0)); count != 1001 {
+		t.Fatalf("count after unmarshal and add is %v", count)
+	}
+	if u.UnmarshalBinary(bytes[:len(bytes)-1]) == nil {
+		t.Fatal("unmarshalled a truncated sketch")
+	}
+	// a huge k or counter count is rejected rather than allocated
+	header := make([]byte, 24)
+	binary.LittleEndian.PutUint64(header[0:], 1<<40)
+	if u.UnmarshalBinary(header) == nil {
+		t.Fatal("unmarshalled a sketch with 1<<40 counters")
+	}
+	binary.LittleEndian.PutUint64(header[0:], 100)
+	binary.LittleEndian.PutUint64(header[16:], 100)
+	if u.UnmarshalBinary(header) == nil {
+		t.Fatal("unmarshalled 100 counters from no bytes")
+	}
+}
+
+func TestMSpaceSavingCopies(t *testing.T) {
+	s := NewSpaceSaving(2)
+	s.Add(types.Int(1), 1)
+	m := NewMSpaceSaving(s, nil, nil)
+	m.Add(types.Int(2), 5)
+	s.Add(types.Int(3), 7)
+	if top := m.Top(2); !top[0].Item.Equals(types.Int(2)) || top[0].Count != 5 || !top[1].Item.Equals(types.Int(1)) {
+		t.Fatalf("the copy has %v", top)
+	}
+	if top := s.Top(2); !top[0].Item.Equals(types.Int(3)) || top[0].Count != 7 || !top[1].Item.Equals(types.Int(1)) {
+		t.Fatalf("the original has %v", top)
+	}
+}
@@ -0,0 +1,298 @@
+package sketch
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"sort"
+)
+
+import (
+	"github.com/timtadh/data-structures/errors"
+	"github.com/timtadh/data-structures/hashtable"
+	"github.com/timtadh/data-structures/types"
+)
+
+// An item counted by a SpaceSaving. Its true count is in
+// [Count - Error, Count].
+type HeavyHitter struct {
+	Item  types.Hashable
+	Count uint64
+	Error uint64
+}
+
+type ss_counter struct {
+	HeavyHitter
+	pos int // in the heap
+}
+
+/* Space-Saving (Metwally, Agrawal and El Abbadi) finds the heavy hitters of a
+ * stream with k counters. An item with a counter adds to it. Otherwise the
+ * item takes over the smallest counter, inheriting its count as its error.
+ * Every item which occurred more than Total/k times has a counter.
+ *
+ * The counters are kept in a min heap on their counts and found by item in a
+ * hash table.
+ */
+type SpaceSaving struct {
+	k     int
+	heap  []*ss_counter
+	index *hashtable.Hash
+	total uint64
+}
+
+// Makes a Space-Saving sketch with k counters.
+func NewSpaceSaving(k int) *SpaceSaving {
+	if k < 1 {
+		panic(errors.Errorf("A SpaceSaving needs at least 1 counter, got %v", k))
+	}
+	return &SpaceSaving{
+		k:     k,
+		heap:  make([]*ss_counter, 0, k),
+		index: hashtable.NewHashTable(2 * k),
+	}
+}
+
+func (s *SpaceSaving) K() int {
+	return s.k
+}
+
+// The sum of the counts added.
+func (s *SpaceSaving) Total() uint64 {
+	return s.total
+}
+
+func (s *SpaceSaving) counter(item types.Hashable) *ss_counter {
+	if !s.index.Has(item) {
+		return nil
+	}
+	c, _ := s.index.Get(item)
+	return c.(*ss_counter)
+}
+
+// the count of an item without a counter (at least its true count)
+func (s *SpaceSaving) floor() uint64 {
+	if len(s.heap) < s.k {
+		return 0
+	}
+	return s.heap[0].Count
+}
+
+func (s *SpaceSaving) Add(item types.Hashable, count uint64) {
+	s.total += count
+	if c := s.counter(item); c != nil {
+		c.Count += count
+		s.down(c.pos)
+	} else if len(s.heap) < s.k {
+		c := &ss_counter{HeavyHitter{item, count, 0}, len(s.heap)}
+		s.heap = append(s.heap, c)
+		s.index.Put(item, c)
+		s.up(c.pos)
+	} else {
+		c := s.heap[0]
+		s.index.Remove(c.Item)
+		c.Item, c.Error, c.Count = item, c.Count, c.Count+count
+		s.index.Put(item, c)
+		s.down(0)
+	}
+}
+
+// The estimated count of an item and the most it may be over.
+func (s *SpaceSaving) Count(item types.Hashable) (count, over uint64) {
+	if c := s.counter(item); c != nil {
+		return c.Count, c.Error
+	}
+	f := s.floor()
+	return f, f
+}
+
+// The n items with the highest counts, highest first. Every item which
+// occurred more than Total/k times is in Top(k).
+func (s *SpaceSaving) Top(n int) []HeavyHitter {
+	top := make([]HeavyHitter, 0, len(s.heap))
+	for _, c := range s.heap {
+		top = append(top, c.HeavyHitter)
+	}
+	sort.Slice(top, func(i, j int) bool { return top[i].Count > top[j].Count })
+	if n < len(top) {
+		top = top[:n]
+	}
+	return top
+}
+
+// Merges o into s as in Agarwal et al.'s mergeable summaries: an item gets
+// the sum of its counts in each, counting the smallest count of a full sketch
+// without it, and the k highest are kept. They must have the same k.
+func (s *SpaceSaving) Merge(o *SpaceSaving) error {
+	if s.k != o.k {
+		return errors.Errorf("Can not merge SpaceSavings of %v and %v counters", s.k, o.k)
+	}
+	sf, of := s.floor(), o.floor()
+	merged := make([]HeavyHitter, 0, len(s.heap)+len(o.heap))
+	for _, c := range s.heap {
+		hh := c.HeavyHitter
+		if oc := o.counter(hh.Item); oc != nil {
+			hh.Count += oc.Count
+			hh.Error += oc.Error
+		} else {
+			hh.Count += of
+			hh.Error += of
+		}
+		merged = append(merged, hh)
+	}
+	for _, c := range o.heap {
+		if s.counter(c.Item) == nil {
+			merged = append(merged, HeavyHitter{c.Item, c.Count + sf, c.Error + sf})
+		}
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].Count > merged[j].Count })
+	if len(merged) > s.k {
+		merged = merged[:s.k]
+	}
+	s.load(merged, s.total+o.total)
+	return nil
+}
+
+// A copy of s which shares nothing with it.
+func (s *SpaceSaving) Copy() *SpaceSaving {
+	c := &SpaceSaving{k: s.k, heap: make([]*ss_counter, 0, len(s.heap))}
+	hhs := make([]HeavyHitter, 0, len(s.heap))
+	for _, counter := range s.heap {
+		hhs = append(hhs, counter.HeavyHitter)
+	}
+	c.load(hhs, s.total)
+	return c
+}
+
+// replaces the counters with hhs
+func (s *SpaceSaving) load(hhs []HeavyHitter, total uint64) {
+	s.heap = s.heap[:0]
+	// sized by the counters rather than k, which may be far larger
+	s.index = hashtable.NewHashTable(2*len(hhs) + 1)
+	s.total = total
+	for _, hh := range hhs {
+		c := &ss_counter{hh, len(s.heap)}
+		s.heap = append(s.heap, c)
+		s.index.Put(hh.Item, c)
+		s.up(c.pos)
+	}
+}
+
+func (s *SpaceSaving) swap(i, j int) {
+	s.heap[i], s.heap[j] = s.heap[j], s.heap[i]
+	s.heap[i].pos = i
+	s.heap[j].pos = j
+}
+
+func (s *SpaceSaving) up(i int) {
+	for i > 0 {
+		p := (i - 1) / 2
+		if s.heap[p].Count <= s.heap[i].Count {
+			return
+		}
+		s.swap(i, p)
+		i = p
+	}
+}
+
+func (s *SpaceSaving) down(i int) {
+	for {
+		min := i
+		for _, c := range []int{2*i + 1, 2*i + 2} {
+			if c < len(s.heap) && s.heap[c].Count < s.heap[min].Count {
+				min = c
+			}
+		}
+		if min == i {
+			return
+		}
+		s.swap(i, min)
+		i = min
+	}
+}
+
+/* A SpaceSaving which can be marshalled. Items are marshalled with
+ * MarshalItem and unmarshalled with UnmarshalItem, as in list.MList.
+ */
+type MSpaceSaving struct {
+	SpaceSaving
+	MarshalItem   types.ItemMarshal
+	UnmarshalItem types.ItemUnmarshal
+}
+
+// Makes an MSpaceSaving with a copy of s, so changes to one do not show in
+// the other.
+func NewMSpaceSaving(s *SpaceSaving, marshal types.ItemMarshal, unmarshal types.ItemUnmarshal) *MSpaceSaving {
+	return &MSpaceSaving{
+		SpaceSaving:   *s.Copy(),
+		MarshalItem:   marshal,
+		UnmarshalItem: unmarshal,
+	}
+}
+
+// k, the total and the number of counters, then for each counter its count,
+// error, the length of its item and its item. All little endian.
+func (m *MSpaceSaving) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	header := make([]byte, 24)
+	binary.LittleEndian.PutUint64(header[0:], uint64(m.k))
+	binary.LittleEndian.PutUint64(header[8:], m.total)
+	binary.LittleEndian.PutUint64(header[16:], uint64(len(m.heap)))
+	buf.Write(header)
+	for _, c := range m.heap {
+		item, err := m.MarshalItem(c.Item)
+		if err != nil {
+			return nil, err
+		}
+		counter := make([]byte, 20)
+		binary.LittleEndian.PutUint64(counter[0:], c.Count)
+		binary.LittleEndian.PutUint64(counter[8:], c.Error)
+		binary.LittleEndian.PutUint32(counter[16:], uint32(len(item)))
+		buf.Write(counter)
+		buf.Write(item)
+	}
+	return buf.Bytes(), nil
+}
+
+func (m *MSpaceSaving) UnmarshalBinary(bytes []byte) error {
+	short := errors.Errorf("A marshalled SpaceSaving is too short")
+	if len(bytes) < 24 {
+		return short
+	}
+	k := binary.LittleEndian.Uint64(bytes[0:])
+	total := binary.LittleEndian.Uint64(bytes[8:])
+	n := binary.LittleEndian.Uint64(bytes[16:])
+	b := bytes[24:]
+	// each counter takes at least 20 bytes. The counters (not k) size the
+	// allocations, but a k which does not fit in an int32 is not a sketch
+	// this package made.
+	if k < 1 || k > math.MaxInt32 || n > k || n > uint64(len(b)/20) {
+		return errors.Errorf("A marshalled SpaceSaving has %v of %v counters in %v bytes", n, k, len(b))
+	}
+	hhs := make([]HeavyHitter, 0, n)
+	for i := uint64(0); i < n; i++ {
+		if len(b) < 20 {
+			return short
+		}
+		hh := HeavyHitter{
+			Count: binary.LittleEndian.Uint64(b[0:]),
+			Error: binary.LittleEndian.Uint64(b[8:]),
+		}
+		size := int(binary.LittleEndian.Uint32(b[16:]))
+		b = b[20:]
+		if len(b) < size {
+			return short
+		}
+		item, err := m.UnmarshalItem(b[:size])
+		if err != nil {
+			return err
+		}
+		hh.Item = item
+		hhs = append(hhs, hh)
+		b = b[size:]
+	}
+	m.k = int(k)
+	m.heap = make([]*ss_counter, 0, n)
+	m.load(hhs, total)
+	return nil
+}
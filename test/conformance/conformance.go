@@ -0,0 +1,77 @@
+/*
+Package conformance has test suites for implementations of the interfaces in
+the types package. They check the semantics the structures in this library
+follow so a third party implementation can be used anywhere one of ours can,
+for instance as the backing Map of a set.SetMap. Call a suite from a test with
+a factory which makes a new, empty container:
+
+	func TestMyMap(t *testing.T) {
+		conformance.TestMap(t, func() types.Map { return NewMyMap() })
+	}
+
+The suites use types.Int keys and items. Every check is a subtest so a
+failure names the property which does not hold.
+*/
+package conformance
+
+import (
+	"testing"
+)
+
+import (
+	"github.com/timtadh/data-structures/types"
+)
+
+// The number of keys or items the suites put into a container.
+const N = 200
+
+// A permutation of 0 ... n-1 which does not depend on the seed of math/rand
+// so failures are reproducible.
+func permutation(n int) []int {
+	perm := make([]int, n)
+	for i := range perm {
+		perm[i] = (i*7919 + 13) % n
+	}
+	return perm
+}
+
+func keys(it types.KIterator) []types.Hashable {
+	keys := make([]types.Hashable, 0, N)
+	for k, next := it(); next != nil; k, next = next() {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func values(it types.Iterator) []interface{} {
+	values := make([]interface{}, 0, N)
+	for v, next := it(); next != nil; v, next = next() {
+		values = append(values, v)
+	}
+	return values
+}
+
+// Each key must be a distinct types.Int in [0, n).
+func check_distinct(t *testing.T, keys []types.Hashable, n int) {
+	if len(keys) != n {
+		t.Fatalf("iterated %v keys, expected %v", len(keys), n)
+	}
+	seen := make([]bool, n)
+	for _, k := range keys {
+		i, ok := k.(types.Int)
+		if !ok || int(i) < 0 || int(i) >= n {
+			t.Fatalf("iterated an unexpected key %v", k)
+		} else if seen[i] {
+			t.Fatalf("iterated %v twice", k)
+		}
+		seen[i] = true
+	}
+}
+
+func check_sorted(t *testing.T, keys []types.Hashable) {
+	for i := 1; i < len(keys); i++ {
+		if !keys[i-1].Less(keys[i]) {
+			t.Fatalf("iterated %v before %v", keys[i-1], keys[i])
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package conformance_test
+
+import (
+	"testing"
+)
+
+import (
+	"github.com/timtadh/data-structures/hashtable"
+	"github.com/timtadh/data-structures/heap"
+	"github.com/timtadh/data-structures/linked"
+	"github.com/timtadh/data-structures/list"
+	"github.com/timtadh/data-structures/set"
+	"github.com/timtadh/data-structures/test/conformance"
+	"github.com/timtadh/data-structures/tree/avl"
+	"github.com/timtadh/data-structures/tree/bptree"
+	"github.com/timtadh/data-structures/tree/rbtree"
+	"github.com/timtadh/data-structures/tree/skiplist"
+	"github.com/timtadh/data-structures/tree/treap"
+	"github.com/timtadh/data-structures/types"
+)
+
+// The structures in this library are the reference for the suites so they
+// must all pass.
+
+func TestMaps(t *testing.T) {
+	maps := map[string]func() types.Map{
+		"Hash":       func() types.Map { return hashtable.NewHashTable(4) },
+		"LinearHash": func() types.Map { return hashtable.NewLinearHash() },
+		"MapSet":     func() types.Map { return set.NewMapSet(set.NewSortedSet(4)) },
+	}
+	for name, factory := range maps {
+		t.Run(name, func(t *testing.T) {
+			conformance.TestMap(t, factory)
+		})
+	}
+}
+
+func TestSortedMaps(t *testing.T) {
+	maps := map[string]func() types.Map{
+		"AvlTree":          func() types.Map { return avl.NewAvlTree() },
+		"ImmutableAvlTree": func() types.Map { return avl.NewImmutableAvlTree() },
+		"RbTree":           func() types.Map { return rbtree.NewRbTree() },
+		"Treap":            func() types.Map { return treap.NewTreap() },
+		"SkipList":         func() types.Map { return skiplist.NewSkipList() },
+		"BpMap":            func() types.Map { return bptree.NewBpMap(8) },
+	}
+	for name, factory := range maps {
+		t.Run(name, func(t *testing.T) {
+			conformance.TestSortedMap(t, factory)
+		})
+	}
+}
+
+func TestMultiMaps(t *testing.T) {
+	t.Run("BpTree", func(t *testing.T) {
+		conformance.TestMultiMap(t, func() types.MultiMap { return bptree.NewBpTree(4) })
+	})
+}
+
+func TestSets(t *testing.T) {
+	sets := map[string]func() types.Set{
+		"SortedSet":       func() types.Set { return set.NewSortedSet(4) },
+		"HashSet":         func() types.Set { return set.NewHashSet(4) },
+		"BitSet":          func() types.Set { return set.NewBitSet(0) },
+		"Roaring":         func() types.Set { return set.NewRoaring() },
+		"SetMap(Hash)":    func() types.Set { return set.NewSetMap(hashtable.NewLinearHash()) },
+		"SetMap(AvlTree)": func() types.Set { return set.NewSetMap(avl.NewAvlTree()) },
+	}
+	for name, factory := range sets {
+		t.Run(name, func(t *testing.T) {
+			conformance.TestSet(t, factory)
+		})
+	}
+}
+
+func TestLists(t *testing.T) {
+	t.Run("List", func(t *testing.T) {
+		conformance.TestList(t, func() types.List { return list.New(4) })
+	})
+}
+
+func TestDeques(t *testing.T) {
+	deques := map[string]func() types.Deque{
+		"LinkedList":  func() types.Deque { return linked.New() },
+		"UniqueDeque": func() types.Deque { return linked.NewUniqueDeque() },
+	}
+	for name, factory := range deques {
+		t.Run(name, func(t *testing.T) {
+			conformance.TestDeque(t, factory)
+		})
+	}
+}
+
+func TestPriorityQueues(t *testing.T) {
+	t.Run("MinHeap", func(t *testing.T) {
+		conformance.TestPriorityQueue(t, func() conformance.PriorityQueue { return heap.NewMinHeap(4) }, true)
+	})
+	t.Run("MaxHeap", func(t *testing.T) {
+		conformance.TestPriorityQueue(t, func() conformance.PriorityQueue { return heap.NewMaxHeap(4) }, false)
+	})
+	t.Run("UniquePQ", func(t *testing.T) {
+		conformance.TestPriorityQueue(t, func() conformance.PriorityQueue { return heap.NewUnique(heap.NewMinHeap(4)) }, true)
+	})
+}
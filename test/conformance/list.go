@@ -0,0 +1,216 @@
+package conformance
+
+import (
+	"testing"
+)
+
+import (
+	"github.com/timtadh/data-structures/types"
+)
+
+/* Tests a types.List:
+ *  - Get, Set and Remove need 0 <= i < Size(), Insert needs 0 <= i <= Size()
+ *    and inserting at Size() appends. Out of bounds calls return an error and
+ *    leave the list alone.
+ *  - Insert and Remove shift the items after i
+ *  - Items visits the items in index order
+ */
+func TestList(t *testing.T, factory func() types.List) {
+	// the items of l must be the ints in expected, in order
+	check := func(t *testing.T, l types.List, expected []int) {
+		if l.Size() != len(expected) {
+			t.Fatalf("size is %v, expected %v", l.Size(), len(expected))
+		}
+		items := keys(l.Items())
+		if len(items) != len(expected) {
+			t.Fatalf("iterated %v items, expected %v", len(items), len(expected))
+		}
+		for i, e := range expected {
+			if !items[i].Equals(types.Int(e)) {
+				t.Fatalf("iterated %v at %v, expected %v", items[i], i, e)
+			}
+			if item, err := l.Get(i); err != nil || !item.Equals(types.Int(e)) {
+				t.Fatalf("get(%v) = %v, %v expected %v", i, item, err, e)
+			}
+		}
+	}
+	t.Run("Empty", func(t *testing.T) {
+		l := factory()
+		check(t, l, nil)
+		if l.Has(types.Int(0)) {
+			t.Fatal("empty list has an item")
+		}
+		if _, err := l.Get(0); err == nil {
+			t.Fatal("get(0) of an empty list did not return an error")
+		}
+		if err := l.Set(0, types.Int(0)); err == nil {
+			t.Fatal("set(0) of an empty list did not return an error")
+		}
+		if err := l.Remove(0); err == nil {
+			t.Fatal("remove(0) of an empty list did not return an error")
+		}
+	})
+	t.Run("Append", func(t *testing.T) {
+		l := factory()
+		expected := make([]int, 0, N)
+		for i := 0; i < N; i++ {
+			if err := l.Append(types.Int(i)); err != nil {
+				t.Fatal(err)
+			}
+			expected = append(expected, i)
+		}
+		check(t, l, expected)
+		if !l.Has(types.Int(N/2)) || l.Has(types.Int(N)) {
+			t.Fatal("has disagrees with the items")
+		}
+	})
+	t.Run("Bounds", func(t *testing.T) {
+		l := factory()
+		for i := 0; i < 3; i++ {
+			l.Append(types.Int(i))
+		}
+		for _, i := range []int{-1, 3, 4} {
+			if _, err := l.Get(i); err == nil {
+				t.Fatalf("get(%v) did not return an error", i)
+			}
+			if err := l.Set(i, types.Int(9)); err == nil {
+				t.Fatalf("set(%v) did not return an error", i)
+			}
+			if err := l.Remove(i); err == nil {
+				t.Fatalf("remove(%v) did not return an error", i)
+			}
+		}
+		for _, i := range []int{-1, 4} {
+			if err := l.Insert(i, types.Int(9)); err == nil {
+				t.Fatalf("insert(%v) did not return an error", i)
+			}
+		}
+		check(t, l, []int{0, 1, 2})
+	})
+	t.Run("InsertSetRemove", func(t *testing.T) {
+		l := factory()
+		expected := make([]int, 0, N)
+		for j, i := range permutation(N) {
+			at := i % (len(expected) + 1)
+			if err := l.Insert(at, types.Int(j)); err != nil {
+				t.Fatal(err)
+			}
+			expected = append(expected, 0)
+			copy(expected[at+1:], expected[at:])
+			expected[at] = j
+		}
+		check(t, l, expected)
+		for i := 0; i < N; i += 2 {
+			if err := l.Set(i, types.Int(-i)); err != nil {
+				t.Fatal(err)
+			}
+			expected[i] = -i
+		}
+		check(t, l, expected)
+		for _, i := range permutation(N)[:N/2] {
+			at := i % len(expected)
+			if err := l.Remove(at); err != nil {
+				t.Fatal(err)
+			}
+			expected = append(expected[:at], expected[at+1:]...)
+		}
+		check(t, l, expected)
+	})
+}
+
+/* Tests a types.Deque:
+ *  - items come out of the front in the order they were put in the back and
+ *    the other way around
+ *  - First and Last are nil and DequeFront and DequeBack return an error when
+ *    the deque is empty
+ *  - if the deque is also a types.ListIterable its items are iterated from
+ *    the front to the back
+ */
+func TestDeque(t *testing.T, factory func() types.Deque) {
+	t.Run("Empty", func(t *testing.T) {
+		d := factory()
+		if d.Size() != 0 || d.Has(types.Int(0)) {
+			t.Fatal("a new deque is not empty")
+		}
+		if d.First() != nil || d.Last() != nil {
+			t.Fatal("first and last of an empty deque should be nil")
+		}
+		if _, err := d.DequeFront(); err == nil {
+			t.Fatal("deque front of an empty deque did not return an error")
+		}
+		if _, err := d.DequeBack(); err == nil {
+			t.Fatal("deque back of an empty deque did not return an error")
+		}
+	})
+	t.Run("Queue", func(t *testing.T) {
+		d := factory()
+		for i := 0; i < N; i++ {
+			if err := d.EnqueBack(types.Int(i)); err != nil {
+				t.Fatal(err)
+			}
+			if !d.First().Equals(types.Int(0)) || !d.Last().Equals(types.Int(i)) {
+				t.Fatalf("first, last = %v, %v expected 0, %v", d.First(), d.Last(), i)
+			}
+		}
+		if li, ok := d.(types.ListIterable); ok {
+			items := keys(li.Items())
+			for i := range items {
+				if !items[i].Equals(types.Int(i)) {
+					t.Fatalf("iterated %v at %v", items[i], i)
+				}
+			}
+		}
+		for i := 0; i < N; i++ {
+			if !d.Has(types.Int(i)) {
+				t.Fatalf("deque does not have %v", i)
+			}
+			if item, err := d.DequeFront(); err != nil || !item.Equals(types.Int(i)) {
+				t.Fatalf("deque front = %v, %v expected %v", item, err, i)
+			}
+			if d.Size() != N-i-1 {
+				t.Fatalf("size is %v, expected %v", d.Size(), N-i-1)
+			}
+		}
+	})
+	t.Run("Stack", func(t *testing.T) {
+		d := factory()
+		for i := 0; i < N; i++ {
+			if err := d.EnqueFront(types.Int(i)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		for i := 0; i < N; i++ {
+			if item, err := d.DequeFront(); err != nil || !item.Equals(types.Int(N-i-1)) {
+				t.Fatalf("deque front = %v, %v expected %v", item, err, N-i-1)
+			}
+		}
+		if d.Size() != 0 || d.First() != nil || d.Last() != nil {
+			t.Fatal("deque is not empty")
+		}
+	})
+	t.Run("BothEnds", func(t *testing.T) {
+		d := factory()
+		for i := 0; i < N; i++ {
+			if i%2 == 0 {
+				d.EnqueBack(types.Int(i))
+			} else {
+				d.EnqueFront(types.Int(i))
+			}
+		}
+		// the odd items are at the front in descending order then the evens
+		// in ascending order
+		for i := N - 2; i >= 0; i -= 2 {
+			if item, err := d.DequeBack(); err != nil || !item.Equals(types.Int(i)) {
+				t.Fatalf("deque back = %v, %v expected %v", item, err, i)
+			}
+		}
+		for i := 1; i < N; i += 2 {
+			if item, err := d.DequeBack(); err != nil || !item.Equals(types.Int(i)) {
+				t.Fatalf("deque back = %v, %v expected %v", item, err, i)
+			}
+		}
+		if _, err := d.DequeBack(); err == nil {
+			t.Fatal("deque back of an empty deque did not return an error")
+		}
+	})
+}
@@ -0,0 +1,290 @@
+package conformance
+
+import (
+	"testing"
+)
+
+import (
+	"github.com/timtadh/data-structures/errors"
+	"github.com/timtadh/data-structures/types"
+)
+
+/* Tests a types.Map:
+ *  - Get and Remove of a missing key return an error made by errors.NotFound
+ *  - Put replaces the value of a key which is already in the map
+ *  - nil is a value like any other, Has and Get see a key put with nil
+ *  - Remove returns the value it removed
+ *  - Iterate, Keys and Values visit every entry once and in the same order.
+ *    Iterating again restarts from the beginning.
+ */
+func TestMap(t *testing.T, factory func() types.Map) {
+	t.Run("Empty", func(t *testing.T) {
+		m := factory()
+		if m.Size() != 0 {
+			t.Fatalf("size of a new map is %v", m.Size())
+		}
+		if m.Has(types.Int(1)) {
+			t.Fatal("empty map has a key")
+		}
+		if _, err := m.Get(types.Int(1)); !errors.IsNotFound(err) {
+			t.Fatalf("get of a missing key returned %v, expected NotFound", err)
+		}
+		if _, err := m.Remove(types.Int(1)); !errors.IsNotFound(err) {
+			t.Fatalf("remove of a missing key returned %v, expected NotFound", err)
+		}
+		if _, _, next := m.Iterate()(); next != nil {
+			t.Fatal("iterate of an empty map was not empty")
+		}
+		if _, next := m.Keys()(); next != nil {
+			t.Fatal("keys of an empty map were not empty")
+		}
+		if _, next := m.Values()(); next != nil {
+			t.Fatal("values of an empty map were not empty")
+		}
+	})
+	t.Run("PutGet", func(t *testing.T) {
+		m := factory()
+		for j, i := range permutation(N) {
+			if err := m.Put(types.Int(i), i); err != nil {
+				t.Fatal(err)
+			}
+			if m.Size() != j+1 {
+				t.Fatalf("size is %v, expected %v", m.Size(), j+1)
+			}
+		}
+		for i := 0; i < N; i++ {
+			if !m.Has(types.Int(i)) {
+				t.Fatalf("map does not have %v", i)
+			}
+			if v, err := m.Get(types.Int(i)); err != nil || v != i {
+				t.Fatalf("get(%v) = %v, %v", i, v, err)
+			}
+		}
+		if m.Has(types.Int(N)) {
+			t.Fatal("map has a key which was never put")
+		}
+		if _, err := m.Get(types.Int(N)); !errors.IsNotFound(err) {
+			t.Fatalf("get of a missing key returned %v, expected NotFound", err)
+		}
+	})
+	t.Run("Replace", func(t *testing.T) {
+		m := factory()
+		for i := 0; i < N; i++ {
+			m.Put(types.Int(i%10), i)
+		}
+		if m.Size() != 10 {
+			t.Fatalf("size is %v, expected 10", m.Size())
+		}
+		for i := 0; i < 10; i++ {
+			if v, err := m.Get(types.Int(i)); err != nil || v != N-10+i {
+				t.Fatalf("get(%v) = %v, %v expected the last value put", i, v, err)
+			}
+		}
+	})
+	t.Run("NilValues", func(t *testing.T) {
+		m := factory()
+		if err := m.Put(types.Int(1), nil); err != nil {
+			t.Fatal(err)
+		}
+		if !m.Has(types.Int(1)) || m.Size() != 1 {
+			t.Fatal("a key with a nil value is not in the map")
+		}
+		if v, err := m.Get(types.Int(1)); err != nil || v != nil {
+			t.Fatalf("get = %v, %v expected nil, nil", v, err)
+		}
+		if v, err := m.Remove(types.Int(1)); err != nil || v != nil {
+			t.Fatalf("remove = %v, %v expected nil, nil", v, err)
+		}
+	})
+	t.Run("Remove", func(t *testing.T) {
+		m := factory()
+		for i := 0; i < N; i++ {
+			m.Put(types.Int(i), i)
+		}
+		for j, i := range permutation(N) {
+			if v, err := m.Remove(types.Int(i)); err != nil || v != i {
+				t.Fatalf("remove(%v) = %v, %v", i, v, err)
+			}
+			if m.Has(types.Int(i)) {
+				t.Fatalf("map still has %v", i)
+			}
+			if _, err := m.Remove(types.Int(i)); !errors.IsNotFound(err) {
+				t.Fatalf("second remove(%v) returned %v, expected NotFound", i, err)
+			}
+			if m.Size() != N-j-1 {
+				t.Fatalf("size is %v, expected %v", m.Size(), N-j-1)
+			}
+		}
+	})
+	t.Run("Iterate", func(t *testing.T) {
+		m := factory()
+		for _, i := range permutation(N) {
+			m.Put(types.Int(i), -i)
+		}
+		order := make([]types.Hashable, 0, N)
+		for k, v, next := m.Iterate()(); next != nil; k, v, next = next() {
+			if v != -int(k.(types.Int)) {
+				t.Fatalf("iterated %v with the value %v", k, v)
+			}
+			order = append(order, k)
+		}
+		check_distinct(t, order, N)
+		again := make([]types.Hashable, 0, N)
+		for k, _, next := m.Iterate()(); next != nil; k, _, next = next() {
+			again = append(again, k)
+		}
+		ks := keys(m.Keys())
+		vs := values(m.Values())
+		if len(again) != N || len(ks) != N || len(vs) != N {
+			t.Fatalf("iterated %v, %v keys and %v values, expected %v", len(again), len(ks), len(vs), N)
+		}
+		for i := range order {
+			if !order[i].Equals(again[i]) || !order[i].Equals(ks[i]) || vs[i] != -int(order[i].(types.Int)) {
+				t.Fatalf("iterators disagree at %v: %v %v %v %v", i, order[i], again[i], ks[i], vs[i])
+			}
+		}
+	})
+}
+
+// Tests a sorted types.Map, like the trees, with TestMap. The keys must also
+// be iterated in ascending order.
+func TestSortedMap(t *testing.T, factory func() types.Map) {
+	TestMap(t, factory)
+	t.Run("Sorted", func(t *testing.T) {
+		m := factory()
+		for _, i := range permutation(N) {
+			m.Put(types.Int(i), i)
+		}
+		check_sorted(t, keys(m.Keys()))
+		for i := 0; i < N; i += 3 {
+			m.Remove(types.Int(i))
+		}
+		check_sorted(t, keys(m.Keys()))
+	})
+}
+
+/* Tests a types.MultiMap:
+ *  - Add keeps every value added for a key, Count and Find see all of them
+ *  - Find of a missing key is empty and Has is false
+ *  - RemoveWhere removes exactly the selected values of the key, removing a
+ *    missing key is not an error
+ *  - Replace changes exactly the selected values of the key
+ *  - Iterate visits every entry once, Keys visits each distinct key once
+ */
+func TestMultiMap(t *testing.T, factory func() types.MultiMap) {
+	const K = 10
+	fill := func(t *testing.T) types.MultiMap {
+		m := factory()
+		for _, i := range permutation(N) {
+			if err := m.Add(types.Int(i%K), i); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return m
+	}
+	// counts the values found for k
+	find := func(t *testing.T, m types.MultiMap, k int) map[int]int {
+		found := make(map[int]int)
+		for fk, v, next := m.Find(types.Int(k))(); next != nil; fk, v, next = next() {
+			if !fk.Equals(types.Int(k)) {
+				t.Fatalf("find(%v) returned the key %v", k, fk)
+			}
+			found[v.(int)]++
+		}
+		return found
+	}
+	t.Run("Empty", func(t *testing.T) {
+		m := factory()
+		if m.Size() != 0 || m.Has(types.Int(1)) || m.Count(types.Int(1)) != 0 {
+			t.Fatal("a new multimap is not empty")
+		}
+		if _, _, next := m.Find(types.Int(1))(); next != nil {
+			t.Fatal("find in an empty multimap was not empty")
+		}
+		if _, _, next := m.Iterate()(); next != nil {
+			t.Fatal("iterate of an empty multimap was not empty")
+		}
+		if err := m.RemoveWhere(types.Int(1), func(interface{}) bool { return true }); err != nil {
+			t.Fatalf("remove of a missing key returned %v", err)
+		}
+	})
+	t.Run("AddFind", func(t *testing.T) {
+		m := fill(t)
+		if m.Size() != N {
+			t.Fatalf("size is %v, expected %v", m.Size(), N)
+		}
+		for k := 0; k < K; k++ {
+			if !m.Has(types.Int(k)) || m.Count(types.Int(k)) != N/K {
+				t.Fatalf("count(%v) = %v, expected %v", k, m.Count(types.Int(k)), N/K)
+			}
+			found := find(t, m, k)
+			for i := k; i < N; i += K {
+				if found[i] != 1 {
+					t.Fatalf("find(%v) returned %v %v times", k, i, found[i])
+				}
+			}
+		}
+		if m.Has(types.Int(K)) || m.Count(types.Int(K)) != 0 || len(find(t, m, K)) != 0 {
+			t.Fatal("multimap has a key which was never added")
+		}
+	})
+	t.Run("RemoveWhere", func(t *testing.T) {
+		m := fill(t)
+		even := func(v interface{}) bool { return v.(int)%2 == 0 }
+		for k := 0; k < K; k++ {
+			if err := m.RemoveWhere(types.Int(k), even); err != nil {
+				t.Fatal(err)
+			}
+			for v := range find(t, m, k) {
+				if even(v) {
+					t.Fatalf("remove-where(%v) kept %v", k, v)
+				}
+			}
+		}
+		if m.Size() != N/2 {
+			t.Fatalf("size is %v, expected %v", m.Size(), N/2)
+		}
+		for k := 0; k < K; k++ {
+			m.RemoveWhere(types.Int(k), func(interface{}) bool { return true })
+			if m.Has(types.Int(k)) {
+				t.Fatalf("multimap still has %v", k)
+			}
+		}
+		if m.Size() != 0 {
+			t.Fatalf("size is %v, expected 0", m.Size())
+		}
+	})
+	t.Run("Replace", func(t *testing.T) {
+		m := fill(t)
+		small := func(v interface{}) bool { return v.(int) < N/2 }
+		if err := m.Replace(types.Int(3), small, -1); err != nil {
+			t.Fatal(err)
+		}
+		found := find(t, m, 3)
+		if found[-1] != N/K/2 {
+			t.Fatalf("replace replaced %v values, expected %v", found[-1], N/K/2)
+		}
+		for v := range found {
+			if v != -1 && small(v) {
+				t.Fatalf("replace kept %v", v)
+			}
+		}
+		if m.Count(types.Int(3)) != N/K || m.Size() != N {
+			t.Fatal("replace changed the number of entries")
+		}
+	})
+	t.Run("Iterate", func(t *testing.T) {
+		m := fill(t)
+		seen := make(map[int]bool)
+		for k, v, next := m.Iterate()(); next != nil; k, v, next = next() {
+			if seen[v.(int)] || int(k.(types.Int)) != v.(int)%K {
+				t.Fatalf("iterated %v: %v unexpectedly", k, v)
+			}
+			seen[v.(int)] = true
+		}
+		if len(seen) != N {
+			t.Fatalf("iterated %v entries, expected %v", len(seen), N)
+		}
+		check_distinct(t, keys(m.Keys()), K)
+	})
+}
@@ -0,0 +1,100 @@
+package conformance
+
+import (
+	"testing"
+)
+
+import (
+	"github.com/timtadh/data-structures/types"
+)
+
+// The priority queue interface of the heap package. It is repeated here so
+// the heap package can use these tests without an import cycle.
+type PriorityQueue interface {
+	types.Sized
+	Push(priority int, item interface{})
+	Peek() interface{}
+	Pop() interface{}
+}
+
+/* Tests a PriorityQueue. When min is true lower priorities come out first,
+ * otherwise higher ones do:
+ *  - Pop returns the item Peek returned
+ *  - Peek and Pop return nil when the queue is empty
+ *  - items with equal priorities all come out, in any order
+ */
+func TestPriorityQueue(t *testing.T, factory func() PriorityQueue, min bool) {
+	first := func(a, b int) bool {
+		if min {
+			return a <= b
+		}
+		return a >= b
+	}
+	t.Run("Empty", func(t *testing.T) {
+		q := factory()
+		if q.Size() != 0 {
+			t.Fatalf("size of a new queue is %v", q.Size())
+		}
+		if q.Peek() != nil || q.Pop() != nil {
+			t.Fatal("peek and pop of an empty queue should be nil")
+		}
+		if q.Size() != 0 {
+			t.Fatal("pop of an empty queue changed its size")
+		}
+	})
+	t.Run("Order", func(t *testing.T) {
+		q := factory()
+		for j, i := range permutation(N) {
+			q.Push(i/2, types.Int(i))
+			if q.Size() != j+1 {
+				t.Fatalf("size is %v, expected %v", q.Size(), j+1)
+			}
+		}
+		seen := make([]bool, N)
+		prev := -1
+		for j := 0; j < N; j++ {
+			peek := q.Peek()
+			item, ok := q.Pop().(types.Int)
+			if !ok || peek != types.Hashable(item) {
+				t.Fatalf("pop returned %v, peek returned %v", item, peek)
+			} else if int(item) < 0 || int(item) >= N || seen[item] {
+				t.Fatalf("pop returned an unexpected item %v", item)
+			}
+			if prev >= 0 && !first(prev/2, int(item)/2) {
+				t.Fatalf("popped %v after %v", item, prev)
+			}
+			seen[item] = true
+			prev = int(item)
+			if q.Size() != N-j-1 {
+				t.Fatalf("size is %v, expected %v", q.Size(), N-j-1)
+			}
+		}
+		if q.Pop() != nil {
+			t.Fatal("pop of an emptied queue should be nil")
+		}
+	})
+	t.Run("Interleaved", func(t *testing.T) {
+		q := factory()
+		// the priorities in the queue
+		in := make(map[int]bool)
+		perm := permutation(N)
+		for round := 0; round < 4; round++ {
+			for _, i := range perm[round*N/4 : (round+1)*N/4] {
+				q.Push(i, types.Int(i))
+				in[i] = true
+			}
+			for j := 0; j < N/8; j++ {
+				item := int(q.Pop().(types.Int))
+				for p := range in {
+					if !first(item, p) {
+						t.Fatalf("popped %v while %v was in the queue", item, p)
+					}
+				}
+				delete(in, item)
+			}
+			if q.Size() != len(in) {
+				t.Fatalf("size is %v, expected %v", q.Size(), len(in))
+			}
+		}
+	})
+}
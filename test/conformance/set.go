@@ -0,0 +1,146 @@
+package conformance
+
+import (
+	"testing"
+)
+
+import (
+	"github.com/timtadh/data-structures/types"
+)
+
+/* Tests a types.Set:
+ *  - Add of an item already in the set does nothing and is not an error
+ *  - Item returns the item in the set equal to its argument, Item and Delete
+ *    of a missing item return an error
+ *  - Items visits every item once
+ *  - Union, Intersect and Subtract return new sets and leave their operands
+ *    alone. Subset, Superset and the proper versions agree with the items.
+ */
+func TestSet(t *testing.T, factory func() types.Set) {
+	// makes a set of the ints in [lo, hi)
+	span := func(t *testing.T, lo, hi int) types.Set {
+		s := factory()
+		for i := lo; i < hi; i++ {
+			if err := s.Add(types.Int(i)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return s
+	}
+	// the items of s must be the ints in [lo, hi)
+	check := func(t *testing.T, s types.Set, lo, hi int) {
+		items := keys(s.Items())
+		if s.Size() != len(items) {
+			t.Fatalf("size is %v but iterated %v items", s.Size(), len(items))
+		}
+		shifted := make([]types.Hashable, 0, len(items))
+		for _, item := range items {
+			shifted = append(shifted, types.Int(int(item.(types.Int))-lo))
+		}
+		check_distinct(t, shifted, hi-lo)
+	}
+	t.Run("Empty", func(t *testing.T) {
+		s := factory()
+		if s.Size() != 0 || s.Has(types.Int(1)) {
+			t.Fatal("a new set is not empty")
+		}
+		if _, next := s.Items()(); next != nil {
+			t.Fatal("items of an empty set were not empty")
+		}
+		if _, err := s.Item(types.Int(1)); err == nil {
+			t.Fatal("item of a missing item did not return an error")
+		}
+		if err := s.Delete(types.Int(1)); err == nil {
+			t.Fatal("delete of a missing item did not return an error")
+		}
+	})
+	t.Run("AddHasItem", func(t *testing.T) {
+		s := factory()
+		for j, i := range permutation(N) {
+			if err := s.Add(types.Int(i)); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.Add(types.Int(i)); err != nil {
+				t.Fatalf("second add(%v) returned %v", i, err)
+			}
+			if s.Size() != j+1 {
+				t.Fatalf("size is %v, expected %v", s.Size(), j+1)
+			}
+		}
+		for i := 0; i < N; i++ {
+			if !s.Has(types.Int(i)) {
+				t.Fatalf("set does not have %v", i)
+			}
+			if item, err := s.Item(types.Int(i)); err != nil || !item.Equals(types.Int(i)) {
+				t.Fatalf("item(%v) = %v, %v", i, item, err)
+			}
+		}
+		if s.Has(types.Int(N)) {
+			t.Fatal("set has an item which was never added")
+		}
+		check(t, s, 0, N)
+	})
+	t.Run("Delete", func(t *testing.T) {
+		s := span(t, 0, N)
+		for j, i := range permutation(N) {
+			if err := s.Delete(types.Int(i)); err != nil {
+				t.Fatal(err)
+			}
+			if s.Has(types.Int(i)) {
+				t.Fatalf("set still has %v", i)
+			}
+			if err := s.Delete(types.Int(i)); err == nil {
+				t.Fatalf("second delete(%v) did not return an error", i)
+			}
+			if s.Size() != N-j-1 {
+				t.Fatalf("size is %v, expected %v", s.Size(), N-j-1)
+			}
+		}
+	})
+	t.Run("Extend", func(t *testing.T) {
+		s := span(t, 0, N/2)
+		if err := s.Extend(span(t, N/4, N).Items()); err != nil {
+			t.Fatal(err)
+		}
+		check(t, s, 0, N)
+	})
+	t.Run("Algebra", func(t *testing.T) {
+		a := span(t, 0, N/2)
+		b := span(t, N/4, N)
+		if u, err := a.Union(b); err != nil {
+			t.Fatal(err)
+		} else {
+			check(t, u, 0, N)
+		}
+		if i, err := a.Intersect(b); err != nil {
+			t.Fatal(err)
+		} else {
+			check(t, i, N/4, N/2)
+		}
+		if d, err := a.Subtract(b); err != nil {
+			t.Fatal(err)
+		} else {
+			check(t, d, 0, N/4)
+		}
+		check(t, a, 0, N/2)
+		check(t, b, N/4, N)
+	})
+	t.Run("Subset", func(t *testing.T) {
+		a := span(t, 0, N)
+		b := span(t, N/4, N/2)
+		c := span(t, 0, N)
+		d := span(t, N/2, N+1)
+		if !b.Subset(a) || !b.ProperSubset(a) || !a.Superset(b) || !a.ProperSuperset(b) {
+			t.Fatal("a smaller set of the same items is a proper subset")
+		}
+		if !a.Subset(c) || a.ProperSubset(c) || !a.Superset(c) || a.ProperSuperset(c) {
+			t.Fatal("a set with the same items is a subset but not a proper one")
+		}
+		if a.Subset(b) || d.Subset(a) || a.Superset(d) {
+			t.Fatal("a set with an item which is not in the other is not a subset")
+		}
+		if !factory().Subset(a) || !a.Superset(factory()) {
+			t.Fatal("the empty set is a subset of every set")
+		}
+	})
+}
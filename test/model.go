@@ -0,0 +1,379 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/timtadh/data-structures/types"
+)
+
+// The model checkers below decode a byte string into a sequence of
+// operations, apply each operation both to the container under test and to a
+// simple reference model, and fail as soon as the two disagree. Every
+// operation takes two bytes: the first picks the operation and the second is
+// turned into a key (or an index) by a KeyMaker. This makes them a natural
+// fit for fuzz targets:
+//
+//	func FuzzAvlTree(f *testing.F) {
+//		test.AddSeeds(f)
+//		f.Fuzz(func(t *testing.T, ops []byte) {
+//			test.CheckMap(t, NewAvlTree(), ops, test.IntKey)
+//		})
+//	}
+//
+// If the container has a Verify() error method it is called after every
+// operation.
+type KeyMaker func(b byte) types.Hashable
+
+// Makes keys from a small range of ints so operations often hit the same
+// key.
+func IntKey(b byte) types.Hashable {
+	return types.Int(b % 32)
+}
+
+// Makes short, non-empty keys over the alphabet "abcd" so keys often collide
+// and share prefixes.
+func ByteSliceKey(b byte) types.Hashable {
+	key := make(types.ByteSlice, int(b%3)+1)
+	for i := range key {
+		key[i] = 'a' + (b>>uint(2+2*i))&3
+	}
+	return key
+}
+
+// Makes the same keys as ByteSliceKey but as a types.String.
+func StringKey(b byte) types.Hashable {
+	return types.String(ByteSliceKey(b).(types.ByteSlice))
+}
+
+type verifiable interface {
+	Verify() error
+}
+
+func verify(t testing.TB, c interface{}, step int, op string) {
+	if v, ok := c.(verifiable); ok {
+		if err := v.Verify(); err != nil {
+			t.Fatalf("step %v (%v): verify failed: %v", step, op, err)
+		}
+	}
+}
+
+type entry struct {
+	key   types.Hashable
+	value interface{}
+}
+
+// The reference model for maps and multimaps. It is a plain slice so it only
+// relies on Equals.
+type entries []entry
+
+func (m entries) find(key types.Hashable) int {
+	for i, e := range m {
+		if e.key.Equals(key) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (m entries) values(key types.Hashable) []interface{} {
+	values := make([]interface{}, 0, 2)
+	for _, e := range m {
+		if e.key.Equals(key) {
+			values = append(values, e.value)
+		}
+	}
+	return values
+}
+
+func (m entries) remove(i int) entries {
+	return append(m[:i], m[i+1:]...)
+}
+
+// Are a and b the same multiset?
+func same_values(a, b []interface{}) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	used := make([]bool, len(b))
+outer:
+	for _, x := range a {
+		for j, y := range b {
+			if !used[j] && x == y {
+				used[j] = true
+				continue outer
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func check_contents(t testing.TB, m entries, it types.KVIterator) {
+	found := make(entries, 0, len(m))
+	for k, v, next := it(); next != nil; k, v, next = next() {
+		found = append(found, entry{k, v})
+	}
+	if len(found) != len(m) {
+		t.Fatalf("iterated %v entries, expected %v", len(found), len(m))
+	}
+	for _, e := range m {
+		if !same_values(found.values(e.key), m.values(e.key)) {
+			t.Fatalf("iterated %v for key %v, expected %v", found.values(e.key), e.key, m.values(e.key))
+		}
+	}
+}
+
+var mapOps = []string{"put", "get", "has", "remove"}
+
+// Checks a types.Map against a reference model. The value put at step i is i.
+func CheckMap(t testing.TB, c types.Map, ops []byte, key KeyMaker) {
+	m := make(entries, 0, len(ops)/2)
+	for i := 0; i+1 < len(ops); i += 2 {
+		op, k := mapOps[int(ops[i])%len(mapOps)], key(ops[i+1])
+		j := m.find(k)
+		switch op {
+		case "put":
+			if err := c.Put(k, i); err != nil {
+				t.Fatalf("step %v: put(%v): %v", i, k, err)
+			}
+			if j >= 0 {
+				m[j].value = i
+			} else {
+				m = append(m, entry{k, i})
+			}
+		case "get":
+			v, err := c.Get(k)
+			if j < 0 && err == nil {
+				t.Fatalf("step %v: get(%v) found a missing key, value %v", i, k, v)
+			} else if j >= 0 && (err != nil || v != m[j].value) {
+				t.Fatalf("step %v: get(%v) = %v, %v expected %v", i, k, v, err, m[j].value)
+			}
+		case "has":
+			if has := c.Has(k); has != (j >= 0) {
+				t.Fatalf("step %v: has(%v) = %v", i, k, has)
+			}
+		case "remove":
+			v, err := c.Remove(k)
+			if j < 0 {
+				if err == nil {
+					t.Fatalf("step %v: remove(%v) removed a missing key, value %v", i, k, v)
+				}
+			} else if err != nil || v != m[j].value {
+				t.Fatalf("step %v: remove(%v) = %v, %v expected %v", i, k, v, err, m[j].value)
+			} else {
+				m = m.remove(j)
+			}
+		}
+		if c.Size() != len(m) {
+			t.Fatalf("step %v (%v %v): size is %v, expected %v", i, op, k, c.Size(), len(m))
+		}
+		verify(t, c, i, op)
+	}
+	check_contents(t, m, c.Iterate())
+}
+
+var multiMapOps = []string{"add", "count", "has", "find", "remove-where", "replace"}
+
+// Checks a types.MultiMap against a reference model. The value added at step
+// i is i. RemoveWhere and Replace select the odd or even values of a key
+// depending on the op byte. Removing a key which is not present may return an
+// error but does not have to.
+func CheckMultiMap(t testing.TB, c types.MultiMap, ops []byte, key KeyMaker) {
+	m := make(entries, 0, len(ops)/2)
+	for i := 0; i+1 < len(ops); i += 2 {
+		op, k := multiMapOps[int(ops[i])%len(multiMapOps)], key(ops[i+1])
+		parity := int(ops[i]) / len(multiMapOps) % 2
+		// the values are step numbers which are always even
+		where := func(v interface{}) bool {
+			return v.(int)/2%2 == parity
+		}
+		switch op {
+		case "add":
+			if err := c.Add(k, i); err != nil {
+				t.Fatalf("step %v: add(%v): %v", i, k, err)
+			}
+			m = append(m, entry{k, i})
+		case "count":
+			if count := c.Count(k); count != len(m.values(k)) {
+				t.Fatalf("step %v: count(%v) = %v, expected %v", i, k, count, len(m.values(k)))
+			}
+		case "has":
+			if has := c.Has(k); has != (m.find(k) >= 0) {
+				t.Fatalf("step %v: has(%v) = %v", i, k, has)
+			}
+		case "find":
+			found := make([]interface{}, 0, 2)
+			for fk, v, next := c.Find(k)(); next != nil; fk, v, next = next() {
+				if !fk.Equals(k) {
+					t.Fatalf("step %v: find(%v) returned key %v", i, k, fk)
+				}
+				found = append(found, v)
+			}
+			if !same_values(found, m.values(k)) {
+				t.Fatalf("step %v: find(%v) = %v, expected %v", i, k, found, m.values(k))
+			}
+		case "remove-where":
+			kept := make(entries, 0, len(m))
+			for _, e := range m {
+				if !e.key.Equals(k) || !where(e.value) {
+					kept = append(kept, e)
+				}
+			}
+			if err := c.RemoveWhere(k, where); err != nil && len(kept) != len(m) {
+				t.Fatalf("step %v: remove-where(%v): %v", i, k, err)
+			}
+			m = kept
+		case "replace":
+			if err := c.Replace(k, where, i); err != nil {
+				t.Fatalf("step %v: replace(%v): %v", i, k, err)
+			}
+			for j := range m {
+				if m[j].key.Equals(k) && where(m[j].value) {
+					m[j].value = i
+				}
+			}
+		}
+		if c.Size() != len(m) {
+			t.Fatalf("step %v (%v %v): size is %v, expected %v", i, op, k, c.Size(), len(m))
+		}
+		verify(t, c, i, op)
+	}
+	check_contents(t, m, c.Iterate())
+}
+
+var setOps = []string{"add", "has", "item", "delete"}
+
+// Checks a types.Set against a reference model. Adding an item which is
+// already in the set does nothing. Getting or deleting an item which is not in
+// the set is an error.
+func CheckSet(t testing.TB, c types.Set, ops []byte, key KeyMaker) {
+	m := make([]types.Hashable, 0, len(ops)/2)
+	find := func(item types.Hashable) int {
+		for i, x := range m {
+			if x.Equals(item) {
+				return i
+			}
+		}
+		return -1
+	}
+	for i := 0; i+1 < len(ops); i += 2 {
+		op, k := setOps[int(ops[i])%len(setOps)], key(ops[i+1])
+		j := find(k)
+		switch op {
+		case "add":
+			if err := c.Add(k); err != nil {
+				t.Fatalf("step %v: add(%v): %v", i, k, err)
+			}
+			if j < 0 {
+				m = append(m, k)
+			}
+		case "has":
+			if has := c.Has(k); has != (j >= 0) {
+				t.Fatalf("step %v: has(%v) = %v", i, k, has)
+			}
+		case "item":
+			item, err := c.Item(k)
+			if j < 0 && err == nil {
+				t.Fatalf("step %v: item(%v) found a missing item %v", i, k, item)
+			} else if j >= 0 && (err != nil || !item.Equals(k)) {
+				t.Fatalf("step %v: item(%v) = %v, %v", i, k, item, err)
+			}
+		case "delete":
+			err := c.Delete(k)
+			if j < 0 && err == nil {
+				t.Fatalf("step %v: delete(%v) deleted a missing item", i, k)
+			} else if j >= 0 {
+				if err != nil {
+					t.Fatalf("step %v: delete(%v): %v", i, k, err)
+				}
+				m = append(m[:j], m[j+1:]...)
+			}
+		}
+		if c.Size() != len(m) {
+			t.Fatalf("step %v (%v %v): size is %v, expected %v", i, op, k, c.Size(), len(m))
+		}
+		verify(t, c, i, op)
+	}
+	count := 0
+	for item, next := c.Items()(); next != nil; item, next = next() {
+		if find(item) < 0 {
+			t.Fatalf("iterated %v which is not in the set", item)
+		}
+		count++
+	}
+	if count != len(m) {
+		t.Fatalf("iterated %v items, expected %v", count, len(m))
+	}
+}
+
+var listOps = []string{"append", "get", "set", "insert", "remove", "has"}
+
+// Checks a types.List against a slice. The index of an operation is the key
+// byte modulo len+2 so out of bounds accesses are tried as well, they must
+// return an error and leave the list alone.
+func CheckList(t testing.TB, c types.List, ops []byte, key KeyMaker) {
+	m := make([]types.Hashable, 0, len(ops)/2)
+	for i := 0; i+1 < len(ops); i += 2 {
+		op, item := listOps[int(ops[i])%len(listOps)], key(ops[i+1])
+		idx := int(ops[i+1]) % (len(m) + 2)
+		var err error
+		var in_bounds bool
+		switch op {
+		case "append":
+			in_bounds = true
+			if err = c.Append(item); err == nil {
+				m = append(m, item)
+			}
+		case "get":
+			in_bounds = idx < len(m)
+			var got types.Hashable
+			got, err = c.Get(idx)
+			if in_bounds && err == nil && !got.Equals(m[idx]) {
+				t.Fatalf("step %v: get(%v) = %v expected %v", i, idx, got, m[idx])
+			}
+		case "set":
+			in_bounds = idx < len(m)
+			if err = c.Set(idx, item); err == nil && in_bounds {
+				m[idx] = item
+			}
+		case "insert":
+			in_bounds = idx <= len(m)
+			if err = c.Insert(idx, item); err == nil && in_bounds {
+				m = append(m, nil)
+				copy(m[idx+1:], m[idx:])
+				m[idx] = item
+			}
+		case "remove":
+			in_bounds = idx < len(m)
+			if err = c.Remove(idx); err == nil && in_bounds {
+				m = append(m[:idx], m[idx+1:]...)
+			}
+		case "has":
+			in_bounds = true
+			has := false
+			for _, x := range m {
+				has = has || x.Equals(item)
+			}
+			if c.Has(item) != has {
+				t.Fatalf("step %v: has(%v) = %v", i, item, !has)
+			}
+		}
+		if in_bounds != (err == nil) {
+			t.Fatalf("step %v: %v(%v, %v) returned %v", i, op, idx, item, err)
+		}
+		if c.Size() != len(m) {
+			t.Fatalf("step %v (%v %v): size is %v, expected %v", i, op, idx, c.Size(), len(m))
+		}
+		verify(t, c, i, op)
+	}
+	j := 0
+	for item, next := c.Items()(); next != nil; item, next = next() {
+		if j >= len(m) || !item.Equals(m[j]) {
+			t.Fatalf("iterated %v at %v", item, j)
+		}
+		j++
+	}
+	if j != len(m) {
+		t.Fatalf("iterated %v items, expected %v", j, len(m))
+	}
+}
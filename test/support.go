@@ -61,3 +61,21 @@ func RandHex(length int) string {
 func RandStr(length int) string {
 	return string(RandSlice(length))
 }
+
+// Adds an empty op string, a run of ops on a single key and a few op strings
+// of increasing length to the seed corpus of a fuzz target. The seeds are the
+// same on every run so a failure on one of them can be reproduced.
+func AddSeeds(f *testing.F) {
+	f.Add([]byte{})
+	same := make([]byte, 64)
+	for i := 0; i < len(same); i += 2 {
+		same[i] = byte(i / 2)
+		same[i+1] = 7
+	}
+	f.Add(same)
+	for _, n := range []int{16, 256, 2048} {
+		ops := make([]byte, n)
+		mrand.New(mrand.NewSource(int64(n))).Read(ops)
+		f.Add(ops)
+	}
+}
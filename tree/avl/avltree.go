@@ -6,13 +6,6 @@ import (
 	"github.com/timtadh/data-structures/types"
 )
 
-func abs(i int) int {
-	if i < 0 {
-		return -i
-	}
-	return i
-}
-
 func max(a, b int) int {
 	if a > b {
 		return a
@@ -58,6 +51,12 @@ func (self *AvlTree) Remove(key types.Hashable) (value interface{}, err error) {
 	return value, nil
 }
 
+// Verify the heights, balance and key order of the tree.
+func (self *AvlTree) Verify() error {
+	_, err := self.root.verify(nil, nil)
+	return err
+}
+
 func (self *AvlTree) Iterate() types.KVIterator {
 	return self.root.Iterate()
 }
@@ -108,102 +107,36 @@ func (self *AvlNode) Get(key types.Hashable) (value interface{}, err error) {
 	}
 }
 
-func (self *AvlNode) pop_node(node *AvlNode) *AvlNode {
-	if node == nil {
-		panic("node can't be nil")
-	} else if node.left != nil && node.right != nil {
-		panic("node must not have both left and right")
-	}
-
-	if self == nil {
-		return nil
-	} else if self == node {
-		var n *AvlNode
-		if node.left != nil {
-			n = node.left
-		} else if node.right != nil {
-			n = node.right
-		} else {
-			n = nil
-		}
-		node.left = nil
-		node.right = nil
-		return n
-	}
-
-	if node.key.Less(self.key) {
-		self.left = self.left.pop_node(node)
-	} else {
-		self.right = self.right.pop_node(node)
-	}
-
+func (self *AvlNode) fix_height() {
 	self.height = max(self.left.Height(), self.right.Height()) + 1
-	return self
 }
 
-func (self *AvlNode) push_node(node *AvlNode) *AvlNode {
-	if node == nil {
-		panic("node can't be nil")
-	} else if node.left != nil || node.right != nil {
-		panic("node now be a leaf")
+func (self *AvlNode) child(i int) avl_node {
+	if i == 0 {
+		return self.left
 	}
-
-	if self == nil {
-		node.height = 1
-		return node
-	} else if node.key.Less(self.key) {
-		self.left = self.left.push_node(node)
-	} else {
-		self.right = self.right.push_node(node)
-	}
-	self.height = max(self.left.Height(), self.right.Height()) + 1
-	return self
+	return self.right
 }
 
-func (self *AvlNode) rotate_right() *AvlNode {
-	if self == nil {
-		return self
-	}
-	if self.left == nil {
-		return self
+func (self *AvlNode) set_child(i int, n avl_node) {
+	if i == 0 {
+		self.left = n.(*AvlNode)
+	} else {
+		self.right = n.(*AvlNode)
 	}
-	new_root := self.left.rmd()
-	self = self.pop_node(new_root)
-	new_root.left = self.left
-	new_root.right = self.right
-	self.left = nil
-	self.right = nil
-	return new_root.push_node(self)
 }
 
-func (self *AvlNode) rotate_left() *AvlNode {
-	if self == nil {
-		return self
-	}
-	if self.right == nil {
-		return self
-	}
-	new_root := self.right.lmd()
-	self = self.pop_node(new_root)
-	new_root.left = self.left
-	new_root.right = self.right
-	self.left = nil
-	self.right = nil
-	return new_root.push_node(self)
+func (self *AvlNode) mutable() avl_node {
+	return self
 }
 
+// restores the height of this node and, if the heights of its subtrees differ
+// by more than one, rotates to bring them back into balance.
 func (self *AvlNode) balance() *AvlNode {
 	if self == nil {
 		return self
 	}
-	for abs(self.left.Height()-self.right.Height()) > 2 {
-		if self.left.Height() > self.right.Height() {
-			self = self.rotate_right()
-		} else {
-			self = self.rotate_left()
-		}
-	}
-	return self
+	return balance(self).(*AvlNode)
 }
 
 func (self *AvlNode) Put(key types.Hashable, value interface{}) (_ *AvlNode, updated bool) {
@@ -222,7 +155,6 @@ func (self *AvlNode) Put(key types.Hashable, value interface{}) (_ *AvlNode, upd
 		self.right, updated = self.right.Put(key, value)
 	}
 	if !updated {
-		self.height += 1
 		return self.balance(), updated
 	}
 	return self, updated
@@ -234,23 +166,15 @@ func (self *AvlNode) Remove(key types.Hashable) (_ *AvlNode, value interface{},
 	}
 
 	if self.key.Equals(key) {
-		if self.left != nil && self.right != nil {
-			if self.left.Size() < self.right.Size() {
-				lmd := self.right.lmd()
-				lmd.left = self.left
-				return self.right, self.value, nil
-			} else {
-				rmd := self.left.rmd()
-				rmd.right = self.right
-				return self.left, self.value, nil
-			}
-		} else if self.left == nil {
+		if self.left == nil {
 			return self.right, self.value, nil
 		} else if self.right == nil {
 			return self.left, self.value, nil
-		} else {
-			return nil, self.value, nil
 		}
+		new_root := self.right.lmd()
+		new_root.right = self.right.remove_min()
+		new_root.left = self.left
+		return new_root.balance(), self.value, nil
 	}
 	if key.Less(self.key) {
 		self.left, value, err = self.left.Remove(key)
@@ -258,9 +182,43 @@ func (self *AvlNode) Remove(key types.Hashable) (_ *AvlNode, value interface{},
 		self.right, value, err = self.right.Remove(key)
 	}
 	if err != nil {
-		return self.balance(), value, err
+		return self, value, err
+	}
+	return self.balance(), value, err
+}
+
+func (self *AvlNode) remove_min() *AvlNode {
+	if self.left == nil {
+		return self.right
+	}
+	self.left = self.left.remove_min()
+	return self.balance()
+}
+
+// checks the subtree, every key must be in (lo, hi), and returns its height
+func (self *AvlNode) verify(lo, hi types.Hashable) (height int, err error) {
+	if self == nil {
+		return 0, nil
+	}
+	if lo != nil && !lo.Less(self.key) {
+		return 0, errors.Errorf("key %v is out of order, it should be greater than %v", self.key, lo)
+	} else if hi != nil && !self.key.Less(hi) {
+		return 0, errors.Errorf("key %v is out of order, it should be less than %v", self.key, hi)
+	}
+	lh, err := self.left.verify(lo, self.key)
+	if err != nil {
+		return 0, err
+	}
+	rh, err := self.right.verify(self.key, hi)
+	if err != nil {
+		return 0, err
+	}
+	if self.height != max(lh, rh)+1 {
+		return 0, errors.Errorf("node %v has height %v but should have height %v", self.key, self.height, max(lh, rh)+1)
+	} else if lh-rh > 1 || rh-lh > 1 {
+		return 0, errors.Errorf("node %v is unbalanced, left height %v, right height %v", self.key, lh, rh)
 	}
-	return self, value, err
+	return self.height, nil
 }
 
 func (self *AvlNode) Height() int {
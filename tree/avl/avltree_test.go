@@ -277,3 +277,37 @@ func BenchmarkImmutableAvlTree(b *testing.B) {
 		}
 	}
 }
+
+func TestVerify(t *testing.T) {
+	mutable, immutable, keys := randTree(300, 1000)
+	if err := mutable.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	if err := immutable.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	for k := range keys {
+		mutable.Remove(types.Int(k))
+		immutable.Remove(types.Int(k))
+		if err := mutable.Verify(); err != nil {
+			t.Fatal(err)
+		}
+		if err := immutable.Verify(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	bad := NewAvlTree()
+	for i := 0; i < 10; i++ {
+		bad.Put(types.Int(i), i)
+	}
+	bad.root.height++
+	if err := bad.Verify(); err == nil {
+		t.Error("expected a height error")
+	}
+	bad.root.height--
+	bad.root.key, bad.root.left.key = bad.root.left.key, bad.root.key
+	if err := bad.Verify(); err == nil {
+		t.Error("expected an ordering error")
+	}
+}
@@ -0,0 +1,45 @@
+package avl
+
+/* The nodes of the AVL trees in this package (AvlNode, ImmutableAvlNode and
+ * IntervalNode) are balanced by the same rotations. They reach the nodes
+ * through this interface. A nil child is a typed nil pointer, so Height works
+ * on it.
+ */
+type avl_node interface {
+	Height() int
+	// 0 is the left child and 1 the right
+	child(i int) avl_node
+	set_child(i int, n avl_node)
+	// restores the height, and anything else kept from the children
+	fix_height()
+	// a node which can be changed: a copy if nodes are shared
+	mutable() avl_node
+}
+
+// rotates the child on side i up to replace n and returns it
+func rotate(n avl_node, i int) avl_node {
+	n = n.mutable()
+	new_root := n.child(i).mutable()
+	n.set_child(i, new_root.child(1-i))
+	n.fix_height()
+	new_root.set_child(1-i, n)
+	new_root.fix_height()
+	return new_root
+}
+
+// restores the height of n and, if the heights of its subtrees differ by more
+// than one, rotates to bring them back into balance. n must not be shared.
+func balance(n avl_node) avl_node {
+	n.fix_height()
+	heavy := 0
+	if diff := n.child(0).Height() - n.child(1).Height(); diff < -1 {
+		heavy = 1
+	} else if diff <= 1 {
+		return n
+	}
+	c := n.child(heavy)
+	if c.child(heavy).Height() < c.child(1-heavy).Height() {
+		n.set_child(heavy, rotate(c, 1-heavy))
+	}
+	return rotate(n, heavy)
+}
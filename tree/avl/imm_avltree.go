@@ -44,6 +44,12 @@ func (self *ImmutableAvlTree) Remove(key types.Hashable) (value interface{}, err
 	return value, nil
 }
 
+// Verify the heights, balance and key order of the tree.
+func (self *ImmutableAvlTree) Verify() error {
+	_, err := self.root.verify(nil, nil)
+	return err
+}
+
 func (self *ImmutableAvlTree) Iterate() types.KVIterator {
 	return self.root.Iterate()
 }
@@ -107,104 +113,37 @@ func (self *ImmutableAvlNode) Get(key types.Hashable) (value interface{}, err er
 	}
 }
 
-func (self *ImmutableAvlNode) pop_node(node *ImmutableAvlNode) (new_self, new_node *ImmutableAvlNode) {
-	if node == nil {
-		panic("node can't be nil")
-	} else if node.left != nil && node.right != nil {
-		panic("node must not have both left and right")
-	}
-
-	if self == nil {
-		return nil, node.Copy()
-	} else if self == node {
-		var n *ImmutableAvlNode
-		if node.left != nil {
-			n = node.left
-		} else if node.right != nil {
-			n = node.right
-		} else {
-			n = nil
-		}
-		node = node.Copy()
-		node.left = nil
-		node.right = nil
-		return n, node
-	}
-
-	self = self.Copy()
-
-	if node.key.Less(self.key) {
-		self.left, node = self.left.pop_node(node)
-	} else {
-		self.right, node = self.right.pop_node(node)
-	}
-
-	self.height = max(self.left.Height(), self.right.Height()) + 1
-	return self, node
-}
-
-func (self *ImmutableAvlNode) push_node(node *ImmutableAvlNode) *ImmutableAvlNode {
-	if node == nil {
-		panic("node can't be nil")
-	} else if node.left != nil || node.right != nil {
-		panic("node must now be a leaf")
-	}
-
-	self = self.Copy()
-
-	if self == nil {
-		node.height = 1
-		return node
-	} else if node.key.Less(self.key) {
-		self.left = self.left.push_node(node)
-	} else {
-		self.right = self.right.push_node(node)
-	}
+func (self *ImmutableAvlNode) fix_height() {
 	self.height = max(self.left.Height(), self.right.Height()) + 1
-	return self
 }
 
-func (self *ImmutableAvlNode) rotate_right() *ImmutableAvlNode {
-	if self == nil {
-		return self
-	}
-	if self.left == nil {
-		return self
+func (self *ImmutableAvlNode) child(i int) avl_node {
+	if i == 0 {
+		return self.left
 	}
-	return self.rotate(self.left.rmd)
+	return self.right
 }
 
-func (self *ImmutableAvlNode) rotate_left() *ImmutableAvlNode {
-	if self == nil {
-		return self
-	}
-	if self.right == nil {
-		return self
+func (self *ImmutableAvlNode) set_child(i int, n avl_node) {
+	if i == 0 {
+		self.left = n.(*ImmutableAvlNode)
+	} else {
+		self.right = n.(*ImmutableAvlNode)
 	}
-	return self.rotate(self.right.lmd)
 }
 
-func (self *ImmutableAvlNode) rotate(get_new_root func() *ImmutableAvlNode) *ImmutableAvlNode {
-	self, new_root := self.pop_node(get_new_root())
-	new_root.left = self.left
-	new_root.right = self.right
-	self.left = nil
-	self.right = nil
-	return new_root.push_node(self)
+func (self *ImmutableAvlNode) mutable() avl_node {
+	return self.Copy()
 }
 
+// restores the height of this node and, if the heights of its subtrees differ
+// by more than one, rotates to bring them back into balance. This node must
+// be a fresh copy as its height is updated in place.
 func (self *ImmutableAvlNode) balance() *ImmutableAvlNode {
 	if self == nil {
 		return self
 	}
-	for abs(self.left.Height()-self.right.Height()) > 2 {
-		if self.left.Height() > self.right.Height() {
-			self = self.rotate_right()
-		} else {
-			self = self.rotate_left()
-		}
-	}
-	return self
+	return balance(self).(*ImmutableAvlNode)
 }
 
 func (self *ImmutableAvlNode) Put(key types.Hashable, value interface{}) (_ *ImmutableAvlNode, updated bool) {
@@ -224,10 +163,7 @@ func (self *ImmutableAvlNode) Put(key types.Hashable, value interface{}) (_ *Imm
 	} else {
 		self.right, updated = self.right.Put(key, value)
 	}
-	self.height = max(self.left.Height(), self.right.Height()) + 1
-
 	if !updated {
-		self.height += 1
 		return self.balance(), updated
 	}
 	return self, updated
@@ -239,23 +175,15 @@ func (self *ImmutableAvlNode) Remove(key types.Hashable) (_ *ImmutableAvlNode, v
 	}
 
 	if self.key.Equals(key) {
-		if self.left != nil && self.right != nil {
-			var new_root *ImmutableAvlNode
-			if self.left.Size() < self.right.Size() {
-				self, new_root = self.pop_node(self.right.lmd())
-			} else {
-				self, new_root = self.pop_node(self.left.rmd())
-			}
-			new_root.left = self.left
-			new_root.right = self.right
-			return new_root, self.value, nil
-		} else if self.left == nil {
+		if self.left == nil {
 			return self.right, self.value, nil
 		} else if self.right == nil {
 			return self.left, self.value, nil
-		} else {
-			return nil, self.value, nil
 		}
+		new_root := self.right.lmd().Copy()
+		new_root.right = self.right.remove_min()
+		new_root.left = self.left
+		return new_root.balance(), self.value, nil
 	}
 
 	self = self.Copy()
@@ -265,11 +193,45 @@ func (self *ImmutableAvlNode) Remove(key types.Hashable) (_ *ImmutableAvlNode, v
 	} else {
 		self.right, value, err = self.right.Remove(key)
 	}
-	self.height = max(self.left.Height(), self.right.Height()) + 1
 	if err != nil {
-		return self.balance(), value, err
+		return self, value, err
+	}
+	return self.balance(), value, err
+}
+
+func (self *ImmutableAvlNode) remove_min() *ImmutableAvlNode {
+	if self.left == nil {
+		return self.right
+	}
+	self = self.Copy()
+	self.left = self.left.remove_min()
+	return self.balance()
+}
+
+// checks the subtree, every key must be in (lo, hi), and returns its height
+func (self *ImmutableAvlNode) verify(lo, hi types.Hashable) (height int, err error) {
+	if self == nil {
+		return 0, nil
+	}
+	if lo != nil && !lo.Less(self.key) {
+		return 0, errors.Errorf("key %v is out of order, it should be greater than %v", self.key, lo)
+	} else if hi != nil && !self.key.Less(hi) {
+		return 0, errors.Errorf("key %v is out of order, it should be less than %v", self.key, hi)
+	}
+	lh, err := self.left.verify(lo, self.key)
+	if err != nil {
+		return 0, err
+	}
+	rh, err := self.right.verify(self.key, hi)
+	if err != nil {
+		return 0, err
+	}
+	if self.height != max(lh, rh)+1 {
+		return 0, errors.Errorf("node %v has height %v but should have height %v", self.key, self.height, max(lh, rh)+1)
+	} else if lh-rh > 1 || rh-lh > 1 {
+		return 0, errors.Errorf("node %v is unbalanced, left height %v, right height %v", self.key, lh, rh)
 	}
-	return self, value, err
+	return self.height, nil
 }
 
 func (self *ImmutableAvlNode) Height() int {
@@ -0,0 +1,339 @@
+package avl
+
+import (
+	"fmt"
+)
+
+import (
+	"github.com/timtadh/data-structures/errors"
+	"github.com/timtadh/data-structures/tree"
+	"github.com/timtadh/data-structures/types"
+)
+
+// A closed interval [Lo, Hi]. Intervals are ordered by Lo and then by Hi.
+type Interval struct {
+	Lo, Hi types.Sortable
+}
+
+func (self *Interval) Equals(other types.Equatable) bool {
+	if o, ok := other.(*Interval); ok {
+		return self.Lo.Equals(o.Lo) && self.Hi.Equals(o.Hi)
+	}
+	return false
+}
+
+func (self *Interval) Less(other types.Sortable) bool {
+	if o, ok := other.(*Interval); ok {
+		if self.Lo.Equals(o.Lo) {
+			return self.Hi.Less(o.Hi)
+		}
+		return self.Lo.Less(o.Lo)
+	}
+	return false
+}
+
+// If the end points are types.Hashable the hash is computed from them,
+// otherwise it is 0.
+func (self *Interval) Hash() int {
+	lo, lok := self.Lo.(types.Hashable)
+	hi, hok := self.Hi.(types.Hashable)
+	if !lok || !hok {
+		return 0
+	}
+	return lo.Hash()*31 + hi.Hash()
+}
+
+// Does this interval share at least one point with [lo, hi]?
+func (self *Interval) Overlaps(lo, hi types.Sortable) bool {
+	return !self.Hi.Less(lo) && !hi.Less(self.Lo)
+}
+
+func (self *Interval) String() string {
+	return fmt.Sprintf("[%v, %v]", self.Lo, self.Hi)
+}
+
+func max_sortable(a, b types.Sortable) types.Sortable {
+	if a.Less(b) {
+		return b
+	}
+	return a
+}
+
+/* An IntervalTree is an AVL tree of intervals ordered by their low end points
+ * where each node also tracks the largest high end point in its subtree. The
+ * augmentation allows whole subtrees which end before a query to be skipped,
+ * so reporting the k intervals which overlap a query costs O(k log(n)).
+ * Each distinct interval is stored once, inserting it again replaces the
+ * value.
+ */
+type IntervalTree struct {
+	root *IntervalNode
+	size int
+}
+
+func NewIntervalTree() *IntervalTree {
+	return &IntervalTree{}
+}
+
+func (self *IntervalTree) Root() types.TreeNode {
+	return self.root
+}
+
+func (self *IntervalTree) Size() int {
+	return self.size
+}
+
+func (self *IntervalTree) Insert(lo, hi types.Sortable, value interface{}) (err error) {
+	if hi.Less(lo) {
+		return errors.Errorf("Invalid interval, hi (%v) < lo (%v)", hi, lo)
+	}
+	var updated bool
+	self.root, updated = self.root.insert(&Interval{lo, hi}, value)
+	if !updated {
+		self.size++
+	}
+	return nil
+}
+
+func (self *IntervalTree) Has(lo, hi types.Sortable) bool {
+	_, err := self.Get(lo, hi)
+	return err == nil
+}
+
+func (self *IntervalTree) Get(lo, hi types.Sortable) (value interface{}, err error) {
+	n := self.root.find(&Interval{lo, hi})
+	if n == nil {
+		return nil, errors.NotFound(&Interval{lo, hi})
+	}
+	return n.value, nil
+}
+
+func (self *IntervalTree) Remove(lo, hi types.Sortable) (value interface{}, err error) {
+	new_root, value, err := self.root.remove(&Interval{lo, hi})
+	if err != nil {
+		return nil, err
+	}
+	self.root = new_root
+	self.size--
+	return value, nil
+}
+
+// Iterate over the intervals which overlap [lo, hi] in sorted order. The keys
+// are *Interval.
+func (self *IntervalTree) Overlapping(lo, hi types.Sortable) (kvi types.KVIterator) {
+	stack := make([]*IntervalNode, 0, 10)
+	cur := self.root
+	kvi = func() (key types.Hashable, value interface{}, next types.KVIterator) {
+		for {
+			// subtrees whose intervals all end before lo are skipped
+			for cur != nil && !cur.max.Less(lo) {
+				stack = append(stack, cur)
+				cur = cur.left
+			}
+			if len(stack) == 0 {
+				return nil, nil, nil
+			}
+			n := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if hi.Less(n.interval.Lo) {
+				// every remaining interval starts after hi
+				stack = stack[:0]
+				cur = nil
+				return nil, nil, nil
+			}
+			cur = n.right
+			if n.interval.Overlaps(lo, hi) {
+				return n.interval, n.value, kvi
+			}
+		}
+	}
+	return kvi
+}
+
+// Iterate over the intervals which contain the point.
+func (self *IntervalTree) Stabbing(point types.Sortable) types.KVIterator {
+	return self.Overlapping(point, point)
+}
+
+func (self *IntervalTree) Iterate() types.KVIterator {
+	return self.root.Iterate()
+}
+
+func (self *IntervalTree) Items() (vi types.KIterator) {
+	return types.MakeItemsIterator(self)
+}
+
+func (self *IntervalTree) Values() types.Iterator {
+	return types.MakeValuesIterator(self)
+}
+
+func (self *IntervalTree) Keys() types.KIterator {
+	return types.MakeKeysIterator(self)
+}
+
+type IntervalNode struct {
+	interval *Interval
+	value    interface{}
+	max      types.Sortable
+	height   int
+	left     *IntervalNode
+	right    *IntervalNode
+}
+
+func (self *IntervalNode) find(interval *Interval) *IntervalNode {
+	for self != nil {
+		if self.interval.Equals(interval) {
+			return self
+		} else if interval.Less(self.interval) {
+			self = self.left
+		} else {
+			self = self.right
+		}
+	}
+	return nil
+}
+
+// restores the height and the max end point of this node from its children
+func (self *IntervalNode) fix_height() {
+	self.height = max(self.left.Height(), self.right.Height()) + 1
+	self.max = self.interval.Hi
+	if self.left != nil {
+		self.max = max_sortable(self.max, self.left.max)
+	}
+	if self.right != nil {
+		self.max = max_sortable(self.max, self.right.max)
+	}
+}
+
+func (self *IntervalNode) child(i int) avl_node {
+	if i == 0 {
+		return self.left
+	}
+	return self.right
+}
+
+func (self *IntervalNode) set_child(i int, n avl_node) {
+	if i == 0 {
+		self.left = n.(*IntervalNode)
+	} else {
+		self.right = n.(*IntervalNode)
+	}
+}
+
+func (self *IntervalNode) mutable() avl_node {
+	return self
+}
+
+func (self *IntervalNode) balance() *IntervalNode {
+	return balance(self).(*IntervalNode)
+}
+
+func (self *IntervalNode) insert(interval *Interval, value interface{}) (_ *IntervalNode, updated bool) {
+	if self == nil {
+		return &IntervalNode{interval: interval, value: value, max: interval.Hi, height: 1}, false
+	}
+	if self.interval.Equals(interval) {
+		self.value = value
+		return self, true
+	} else if interval.Less(self.interval) {
+		self.left, updated = self.left.insert(interval, value)
+	} else {
+		self.right, updated = self.right.insert(interval, value)
+	}
+	return self.balance(), updated
+}
+
+func (self *IntervalNode) remove(interval *Interval) (_ *IntervalNode, value interface{}, err error) {
+	if self == nil {
+		return nil, nil, errors.NotFound(interval)
+	}
+	if self.interval.Equals(interval) {
+		if self.left == nil {
+			return self.right, self.value, nil
+		} else if self.right == nil {
+			return self.left, self.value, nil
+		}
+		new_root := self.right.lmd()
+		new_root.right = self.right.remove_min()
+		new_root.left = self.left
+		return new_root.balance(), self.value, nil
+	} else if interval.Less(self.interval) {
+		self.left, value, err = self.left.remove(interval)
+	} else {
+		self.right, value, err = self.right.remove(interval)
+	}
+	if err != nil {
+		return self, nil, err
+	}
+	return self.balance(), value, nil
+}
+
+func (self *IntervalNode) remove_min() *IntervalNode {
+	if self.left == nil {
+		return self.right
+	}
+	self.left = self.left.remove_min()
+	return self.balance()
+}
+
+func (self *IntervalNode) lmd() *IntervalNode {
+	for self.left != nil {
+		self = self.left
+	}
+	return self
+}
+
+func (self *IntervalNode) Height() int {
+	if self == nil {
+		return 0
+	}
+	return self.height
+}
+
+// The largest high end point of the intervals in this subtree.
+func (self *IntervalNode) Max() types.Sortable {
+	return self.max
+}
+
+func (self *IntervalNode) Interval() *Interval {
+	return self.interval
+}
+
+func (self *IntervalNode) Key() types.Hashable {
+	return self.interval
+}
+
+func (self *IntervalNode) Value() interface{} {
+	return self.value
+}
+
+func (self *IntervalNode) Left() types.BinaryTreeNode {
+	if self.left == nil {
+		return nil
+	}
+	return self.left
+}
+
+func (self *IntervalNode) Right() types.BinaryTreeNode {
+	if self.right == nil {
+		return nil
+	}
+	return self.right
+}
+
+func (self *IntervalNode) GetChild(i int) types.TreeNode {
+	return types.DoGetChild(self, i)
+}
+
+func (self *IntervalNode) ChildCount() int {
+	return types.DoChildCount(self)
+}
+
+func (self *IntervalNode) Children() types.TreeNodeIterator {
+	return types.MakeChildrenIterator(self)
+}
+
+func (self *IntervalNode) Iterate() types.KVIterator {
+	tni := tree.TraverseBinaryTreeInOrder(self)
+	return types.MakeKVIteratorFromTreeNodeIterator(tni)
+}
@@ -0,0 +1,133 @@
+package avl
+
+import (
+	"testing"
+
+	"github.com/timtadh/data-structures/types"
+)
+
+func checkInterval(t *testing.T, n *IntervalNode) {
+	if n == nil {
+		return
+	}
+	checkInterval(t, n.left)
+	checkInterval(t, n.right)
+	if n.height != max(n.left.Height(), n.right.Height())+1 {
+		t.Fatal("bad height at", n.interval)
+	}
+	if d := n.left.Height() - n.right.Height(); d > 1 || d < -1 {
+		t.Fatal("unbalanced at", n.interval)
+	}
+	m := n.interval.Hi
+	if n.left != nil {
+		m = max_sortable(m, n.left.max)
+	}
+	if n.right != nil {
+		m = max_sortable(m, n.right.max)
+	}
+	if !m.Equals(n.max) {
+		t.Fatal("bad max at", n.interval, n.max, m)
+	}
+}
+
+func TestIntervalTreeOverlapping(t *testing.T) {
+	tree := NewIntervalTree()
+	intervals := [][2]int{{15, 20}, {10, 30}, {17, 19}, {5, 20}, {12, 15}, {30, 40}}
+	for i, iv := range intervals {
+		if err := tree.Insert(types.Int(iv[0]), types.Int(iv[1]), i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tree.Insert(types.Int(3), types.Int(1), nil); err == nil {
+		t.Error("inserted an invalid interval")
+	}
+	checkInterval(t, tree.root)
+
+	expected := []int{3, 1, 4}
+	i := 0
+	for k, v, next := tree.Overlapping(types.Int(6), types.Int(14))(); next != nil; k, v, next = next() {
+		if v.(int) != expected[i] {
+			t.Error("wrong interval", k, v, expected[i])
+		}
+		i++
+	}
+	if i != len(expected) {
+		t.Error("wrong number of overlapping intervals", i)
+	}
+
+	expected = []int{1, 5}
+	i = 0
+	for k, v, next := tree.Stabbing(types.Int(30))(); next != nil; k, v, next = next() {
+		if v.(int) != expected[i] {
+			t.Error("wrong interval", k, v, expected[i])
+		}
+		i++
+	}
+	if i != len(expected) {
+		t.Error("wrong number of stabbed intervals", i)
+	}
+
+	if v, err := tree.Remove(types.Int(10), types.Int(30)); err != nil || v.(int) != 1 {
+		t.Error("wrong value removed", v, err)
+	}
+	if _, err := tree.Remove(types.Int(10), types.Int(30)); err == nil {
+		t.Error("removed a missing interval")
+	}
+	checkInterval(t, tree.root)
+	if tree.Size() != len(intervals)-1 {
+		t.Error("wrong size", tree.Size())
+	}
+}
+
+func TestIntervalTreeRandom(t *testing.T) {
+	type iv struct{ lo, hi int }
+	tree := NewIntervalTree()
+	model := make(map[iv]int)
+	for i := 0; i < 500; i++ {
+		lo := rand.Intn(1000)
+		x := iv{lo, lo + rand.Intn(50)}
+		model[x] = i
+		if err := tree.Insert(types.Int(x.lo), types.Int(x.hi), i); err != nil {
+			t.Fatal(err)
+		}
+		if i%4 == 0 {
+			for k := range model {
+				if _, err := tree.Remove(types.Int(k.lo), types.Int(k.hi)); err != nil {
+					t.Fatal(err)
+				}
+				delete(model, k)
+				break
+			}
+		}
+	}
+	checkInterval(t, tree.root)
+	if tree.Size() != len(model) {
+		t.Fatal("wrong size", tree.Size(), len(model))
+	}
+	for j := 0; j < 100; j++ {
+		lo := rand.Intn(1100)
+		hi := lo + rand.Intn(30)
+		count := 0
+		for x := range model {
+			if x.lo <= hi && lo <= x.hi {
+				count++
+			}
+		}
+		found := 0
+		var prev types.Hashable
+		for k, v, next := tree.Overlapping(types.Int(lo), types.Int(hi))(); next != nil; k, v, next = next() {
+			x := iv{int(k.(*Interval).Lo.(types.Int)), int(k.(*Interval).Hi.(types.Int))}
+			if model[x] != v.(int) {
+				t.Error("wrong value", k, v)
+			}
+			if prev != nil && !prev.Less(k) {
+				t.Error("intervals out of order", prev, k)
+			}
+			prev = k
+			found++
+		}
+		if found != count {
+			t.Error("wrong number of overlapping intervals", lo, hi, found, count)
+		}
+	}
+}
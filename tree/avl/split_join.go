@@ -0,0 +1,264 @@
+package avl
+
+import (
+	"github.com/timtadh/data-structures/errors"
+	"github.com/timtadh/data-structures/types"
+)
+
+/* Split and join are the primitives for bulk operations on AVL trees. Joining
+ * two trees around a middle node walks down the spine of the taller tree until
+ * it finds a subtree of matching height, so it costs O(|h(l) - h(r)|). A split
+ * performs one join per level of the tree so it is O(log(n)). The set
+ * operations are built on top of them and run in O(m log(n/m + 1)) where
+ * m <= n are the sizes of the two trees.
+ *
+ * The mutable versions reuse (and so consume) the nodes of their arguments.
+ * The immutable versions copy every node they change.
+ */
+
+// Split the tree into a tree with the keys less than key and a tree with the
+// keys greater than or equal to key. The tree is left empty.
+func (self *AvlTree) Split(key types.Hashable) (left, right *AvlTree) {
+	l, mid, r := self.root.split(key)
+	if mid != nil {
+		r = join(nil, mid, r)
+	}
+	self.root = nil
+	return &AvlTree{root: l}, &AvlTree{root: r}
+}
+
+// Join two trees where every key in left is less than every key in right. Both
+// trees are left empty.
+func Join(left, right *AvlTree) (*AvlTree, error) {
+	if left.root != nil && right.root != nil {
+		if !left.root.rmd().key.Less(right.root.lmd().key) {
+			return nil, errors.Errorf("Join requires every key in left to be less than every key in right")
+		}
+	}
+	root := join2(left.root, right.root)
+	left.root = nil
+	right.root = nil
+	return &AvlTree{root: root}, nil
+}
+
+// A tree with the keys in either tree. When both trees have a key the value
+// from this tree is kept. Both trees are left empty.
+func (self *AvlTree) Union(other *AvlTree) *AvlTree {
+	root := union(self.root, other.root)
+	self.root = nil
+	other.root = nil
+	return &AvlTree{root: root}
+}
+
+// A tree with the keys in both trees (with the values from this tree). Both
+// trees are left empty.
+func (self *AvlTree) Intersect(other *AvlTree) *AvlTree {
+	root := intersect(self.root, other.root)
+	self.root = nil
+	other.root = nil
+	return &AvlTree{root: root}
+}
+
+// A tree with the keys in this tree which are not in the other tree. Both
+// trees are left empty.
+func (self *AvlTree) Difference(other *AvlTree) *AvlTree {
+	root := difference(self.root, other.root)
+	self.root = nil
+	other.root = nil
+	return &AvlTree{root: root}
+}
+
+// mid must be detached, every key in left < mid.key < every key in right
+func join(left, mid, right *AvlNode) *AvlNode {
+	if left.Height() > right.Height()+1 {
+		left.right = join(left.right, mid, right)
+		return left.balance()
+	} else if right.Height() > left.Height()+1 {
+		right.left = join(left, mid, right.left)
+		return right.balance()
+	}
+	mid.left = left
+	mid.right = right
+	mid.fix_height()
+	return mid
+}
+
+func join2(left, right *AvlNode) *AvlNode {
+	if left == nil {
+		return right
+	} else if right == nil {
+		return left
+	}
+	mid := right.lmd()
+	right = right.remove_min()
+	return join(left, mid, right)
+}
+
+// returns the keys less than key, the node with key (or nil) and the keys
+// greater than key
+func (self *AvlNode) split(key types.Hashable) (left, mid, right *AvlNode) {
+	if self == nil {
+		return nil, nil, nil
+	}
+	l, r := self.left, self.right
+	if self.key.Equals(key) {
+		self.left = nil
+		self.right = nil
+		self.height = 1
+		return l, self, r
+	} else if key.Less(self.key) {
+		ll, m, lr := l.split(key)
+		return ll, m, join(lr, self, r)
+	} else {
+		rl, m, rr := r.split(key)
+		return join(l, self, rl), m, rr
+	}
+}
+
+func union(a, b *AvlNode) *AvlNode {
+	if a == nil {
+		return b
+	} else if b == nil {
+		return a
+	}
+	l, r := a.left, a.right
+	bl, _, br := b.split(a.key)
+	return join(union(l, bl), a, union(r, br))
+}
+
+func intersect(a, b *AvlNode) *AvlNode {
+	if a == nil || b == nil {
+		return nil
+	}
+	l, r := a.left, a.right
+	bl, mid, br := b.split(a.key)
+	left := intersect(l, bl)
+	right := intersect(r, br)
+	if mid != nil {
+		return join(left, a, right)
+	}
+	return join2(left, right)
+}
+
+func difference(a, b *AvlNode) *AvlNode {
+	if a == nil || b == nil {
+		return a
+	}
+	l, r := b.left, b.right
+	al, _, ar := a.split(b.key)
+	return join2(difference(al, l), difference(ar, r))
+}
+
+// Split the tree into a tree with the keys less than key and a tree with the
+// keys greater than or equal to key. The tree is not changed.
+func (self *ImmutableAvlTree) Split(key types.Hashable) (left, right *ImmutableAvlTree) {
+	l, mid, r := self.root.split(key)
+	if mid != nil {
+		r = imm_join(nil, mid, r)
+	}
+	return &ImmutableAvlTree{root: l}, &ImmutableAvlTree{root: r}
+}
+
+// Join two trees where every key in left is less than every key in right.
+// Neither tree is changed.
+func JoinImmutable(left, right *ImmutableAvlTree) (*ImmutableAvlTree, error) {
+	if left.root != nil && right.root != nil {
+		if !left.root.rmd().key.Less(right.root.lmd().key) {
+			return nil, errors.Errorf("Join requires every key in left to be less than every key in right")
+		}
+	}
+	return &ImmutableAvlTree{root: imm_join2(left.root, right.root)}, nil
+}
+
+// A tree with the keys in either tree. When both trees have a key the value
+// from this tree is kept.
+func (self *ImmutableAvlTree) Union(other *ImmutableAvlTree) *ImmutableAvlTree {
+	return &ImmutableAvlTree{root: imm_union(self.root, other.root)}
+}
+
+// A tree with the keys in both trees (with the values from this tree).
+func (self *ImmutableAvlTree) Intersect(other *ImmutableAvlTree) *ImmutableAvlTree {
+	return &ImmutableAvlTree{root: imm_intersect(self.root, other.root)}
+}
+
+// A tree with the keys in this tree which are not in the other tree.
+func (self *ImmutableAvlTree) Difference(other *ImmutableAvlTree) *ImmutableAvlTree {
+	return &ImmutableAvlTree{root: imm_difference(self.root, other.root)}
+}
+
+// every key in left < mid.key < every key in right
+func imm_join(left, mid, right *ImmutableAvlNode) *ImmutableAvlNode {
+	if left.Height() > right.Height()+1 {
+		left = left.Copy()
+		left.right = imm_join(left.right, mid, right)
+		return left.balance()
+	} else if right.Height() > left.Height()+1 {
+		right = right.Copy()
+		right.left = imm_join(left, mid, right.left)
+		return right.balance()
+	}
+	mid = mid.Copy()
+	mid.left = left
+	mid.right = right
+	mid.fix_height()
+	return mid
+}
+
+func imm_join2(left, right *ImmutableAvlNode) *ImmutableAvlNode {
+	if left == nil {
+		return right
+	} else if right == nil {
+		return left
+	}
+	return imm_join(left, right.lmd(), right.remove_min())
+}
+
+func (self *ImmutableAvlNode) split(key types.Hashable) (left, mid, right *ImmutableAvlNode) {
+	if self == nil {
+		return nil, nil, nil
+	}
+	if self.key.Equals(key) {
+		mid = self.Copy()
+		mid.left = nil
+		mid.right = nil
+		mid.height = 1
+		return self.left, mid, self.right
+	} else if key.Less(self.key) {
+		ll, m, lr := self.left.split(key)
+		return ll, m, imm_join(lr, self, self.right)
+	} else {
+		rl, m, rr := self.right.split(key)
+		return imm_join(self.left, self, rl), m, rr
+	}
+}
+
+func imm_union(a, b *ImmutableAvlNode) *ImmutableAvlNode {
+	if a == nil {
+		return b
+	} else if b == nil {
+		return a
+	}
+	bl, _, br := b.split(a.key)
+	return imm_join(imm_union(a.left, bl), a, imm_union(a.right, br))
+}
+
+func imm_intersect(a, b *ImmutableAvlNode) *ImmutableAvlNode {
+	if a == nil || b == nil {
+		return nil
+	}
+	bl, mid, br := b.split(a.key)
+	left := imm_intersect(a.left, bl)
+	right := imm_intersect(a.right, br)
+	if mid != nil {
+		return imm_join(left, a, right)
+	}
+	return imm_join2(left, right)
+}
+
+func imm_difference(a, b *ImmutableAvlNode) *ImmutableAvlNode {
+	if a == nil || b == nil {
+		return a
+	}
+	al, _, ar := a.split(b.key)
+	return imm_join2(imm_difference(al, b.left), imm_difference(ar, b.right))
+}
@@ -0,0 +1,155 @@
+package avl
+
+import (
+	"testing"
+
+	"github.com/timtadh/data-structures/types"
+)
+
+// checks the ordering, heights and balance of the subtree and returns its keys
+func checkAvl(t *testing.T, n types.BinaryTreeNode) []int {
+	if types.IsNil(n) {
+		return nil
+	}
+	var height, lh, rh int
+	switch x := n.(type) {
+	case *AvlNode:
+		height, lh, rh = x.height, x.left.Height(), x.right.Height()
+	case *ImmutableAvlNode:
+		height, lh, rh = x.height, x.left.Height(), x.right.Height()
+	}
+	if height != max(lh, rh)+1 {
+		t.Fatal("bad height at", n.Key(), height, lh, rh)
+	}
+	if lh-rh > 1 || rh-lh > 1 {
+		t.Fatal("unbalanced at", n.Key(), lh, rh)
+	}
+	keys := checkAvl(t, n.Left())
+	keys = append(keys, int(n.Key().(types.Int)))
+	keys = append(keys, checkAvl(t, n.Right())...)
+	for i := 1; i < len(keys); i++ {
+		if keys[i-1] >= keys[i] {
+			t.Fatal("keys out of order at", n.Key())
+		}
+	}
+	return keys
+}
+
+func sameKeys(t *testing.T, keys []int, expected map[int]bool) {
+	if len(keys) != len(expected) {
+		t.Fatal("wrong number of keys", len(keys), len(expected))
+	}
+	for _, k := range keys {
+		if !expected[k] {
+			t.Fatal("unexpected key", k)
+		}
+	}
+}
+
+func randTree(n, keyspace int) (*AvlTree, *ImmutableAvlTree, map[int]bool) {
+	keys := make(map[int]bool)
+	mutable := NewAvlTree()
+	immutable := NewImmutableAvlTree()
+	for i := 0; i < n; i++ {
+		k := rand.Intn(keyspace)
+		keys[k] = true
+		mutable.Put(types.Int(k), k)
+		immutable.Put(types.Int(k), k)
+	}
+	return mutable, immutable, keys
+}
+
+func TestAvlBalanced(t *testing.T) {
+	mutable, immutable, keys := randTree(500, 1000)
+	sameKeys(t, checkAvl(t, mutable.root), keys)
+	sameKeys(t, checkAvl(t, immutable.root), keys)
+	for k := range keys {
+		if k%3 == 0 {
+			mutable.Remove(types.Int(k))
+			immutable.Remove(types.Int(k))
+			delete(keys, k)
+		}
+	}
+	sameKeys(t, checkAvl(t, mutable.root), keys)
+	sameKeys(t, checkAvl(t, immutable.root), keys)
+}
+
+func TestSplitJoin(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		mutable, immutable, keys := randTree(rand.Intn(300), 500)
+		pivot := rand.Intn(500)
+		left, right := map[int]bool{}, map[int]bool{}
+		for k := range keys {
+			if k < pivot {
+				left[k] = true
+			} else {
+				right[k] = true
+			}
+		}
+
+		ml, mr := mutable.Split(types.Int(pivot))
+		if mutable.Size() != 0 {
+			t.Error("split should have emptied the tree")
+		}
+		sameKeys(t, checkAvl(t, ml.root), left)
+		sameKeys(t, checkAvl(t, mr.root), right)
+		if ml.Size() > 0 && mr.Size() > 0 {
+			if _, err := Join(mr, ml); err == nil {
+				t.Error("joined overlapping trees")
+			}
+		}
+		mj, err := Join(ml, mr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sameKeys(t, checkAvl(t, mj.root), keys)
+
+		il, ir := immutable.Split(types.Int(pivot))
+		sameKeys(t, checkAvl(t, immutable.root), keys)
+		sameKeys(t, checkAvl(t, il.root), left)
+		sameKeys(t, checkAvl(t, ir.root), right)
+		ij, err := JoinImmutable(il, ir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sameKeys(t, checkAvl(t, ij.root), keys)
+		sameKeys(t, checkAvl(t, il.root), left)
+		sameKeys(t, checkAvl(t, ir.root), right)
+	}
+}
+
+func TestSetOperations(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		ma, ia, a := randTree(rand.Intn(200), 300)
+		mb, ib, b := randTree(rand.Intn(200), 300)
+		union, intersect, difference := map[int]bool{}, map[int]bool{}, map[int]bool{}
+		for k := range a {
+			union[k] = true
+			if b[k] {
+				intersect[k] = true
+			} else {
+				difference[k] = true
+			}
+		}
+		for k := range b {
+			union[k] = true
+		}
+
+		sameKeys(t, checkAvl(t, ia.Union(ib).root), union)
+		sameKeys(t, checkAvl(t, ia.Intersect(ib).root), intersect)
+		sameKeys(t, checkAvl(t, ia.Difference(ib).root), difference)
+		sameKeys(t, checkAvl(t, ia.root), a)
+		sameKeys(t, checkAvl(t, ib.root), b)
+
+		copy := func(x *ImmutableAvlTree) *AvlTree {
+			m := NewAvlTree()
+			for k, v, next := x.Iterate()(); next != nil; k, v, next = next() {
+				m.Put(k, v)
+			}
+			return m
+		}
+		sameKeys(t, checkAvl(t, copy(ia).Union(copy(ib)).root), union)
+		sameKeys(t, checkAvl(t, copy(ia).Intersect(copy(ib)).root), intersect)
+		sameKeys(t, checkAvl(t, ma.Difference(mb).root), difference)
+	}
+}
@@ -1,6 +1,10 @@
 package avl
 
-import "testing"
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
 
 import (
 	"github.com/timtadh/data-structures/tree"
@@ -56,3 +60,117 @@ func TestTraversals(t *testing.T) {
 	test(NewAvlTree())
 	test(NewImmutableAvlTree())
 }
+
+func TestLevelOrderAndWalk(t *testing.T) {
+	T := NewAvlTree()
+	for _, k := range []int{17, 5, 19, 7, 12, 20, 13, 18, 1, 9} {
+		T.Put(types.Int(k), k)
+	}
+
+	reverse := []int{20, 19, 18, 17, 13, 12, 9, 7, 5, 1}
+	j := 0
+	for tn, next := tree.TraverseBinaryTreeReverseInOrder(T.root)(); next != nil; tn, next = next() {
+		if int(tn.Key().(types.Int)) != reverse[j] {
+			t.Error("key in wrong spot reverse-in-order", tn.Key(), reverse[j])
+		}
+		j += 1
+	}
+	if j != len(reverse) {
+		t.Error("wrong number of nodes reverse-in-order", j)
+	}
+
+	levelorder := []int{17, 7, 19, 5, 12, 18, 20, 1, 9, 13}
+	depths := []int{0, 1, 1, 2, 2, 2, 2, 3, 3, 3}
+	j = 0
+	for tn, depth, next := tree.TraverseTreeLevelOrder(T.Root())(); next != nil; tn, depth, next = next() {
+		if int(tn.Key().(types.Int)) != levelorder[j] || depth != depths[j] {
+			t.Error("key in wrong spot level-order", tn.Key(), depth)
+		}
+		j += 1
+	}
+	if j != len(levelorder) {
+		t.Error("wrong number of nodes level-order", j)
+	}
+
+	walk := func(action func(k, depth int) tree.WalkAction) (visited []int, completed bool) {
+		completed = tree.Walk(T.Root(), func(tn types.TreeNode, depth int) tree.WalkAction {
+			k := int(tn.Key().(types.Int))
+			visited = append(visited, k)
+			return action(k, depth)
+		})
+		return visited, completed
+	}
+	same := func(a, b []int) bool {
+		if len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if a[i] != b[i] {
+				return false
+			}
+		}
+		return true
+	}
+	if visited, done := walk(func(int, int) tree.WalkAction { return tree.Continue }); !done || !same(visited, []int{17, 7, 5, 1, 12, 9, 13, 19, 18, 20}) {
+		t.Error("wrong walk", visited, done)
+	}
+	pruned := func(k, depth int) tree.WalkAction {
+		if k == 7 {
+			return tree.SkipChildren
+		}
+		return tree.Continue
+	}
+	if visited, done := walk(pruned); !done || !same(visited, []int{17, 7, 19, 18, 20}) {
+		t.Error("wrong pruned walk", visited, done)
+	}
+	stopped := func(k, depth int) tree.WalkAction {
+		if k == 12 {
+			return tree.Stop
+		}
+		return tree.Continue
+	}
+	if visited, done := walk(stopped); done || !same(visited, []int{17, 7, 5, 1, 12}) {
+		t.Error("wrong stopped walk", visited, done)
+	}
+
+	empty := NewAvlTree()
+	if _, next := tree.TraverseTreePreOrder(empty.Root())(); next != nil {
+		t.Error("pre-order of an empty tree should be empty")
+	}
+	if _, next := tree.TraverseTreePostOrder(empty.Root())(); next != nil {
+		t.Error("post-order of an empty tree should be empty")
+	}
+	if _, _, next := tree.TraverseTreeLevelOrder(empty.Root())(); next != nil {
+		t.Error("level-order of an empty tree should be empty")
+	}
+	if !tree.Walk(empty.Root(), func(types.TreeNode, int) tree.WalkAction { return tree.Stop }) {
+		t.Error("walk of an empty tree should not visit anything")
+	}
+}
+
+func TestPrettyAndDot(t *testing.T) {
+	T := NewAvlTree()
+	for _, k := range []int{2, 1, 3, 4} {
+		T.Put(types.Int(k), nil)
+	}
+	expected := "2\n" +
+		"|-- 1\n" +
+		"`-- 3\n" +
+		"    |-- -\n" +
+		"    `-- 4\n"
+	if s := tree.Pretty(T.Root()); s != expected {
+		t.Errorf("wrong pretty tree\n%v", s)
+	}
+	if s := tree.Pretty(NewAvlTree().Root()); s != "" {
+		t.Errorf("an empty tree should be blank, got %q", s)
+	}
+
+	var buf bytes.Buffer
+	if err := tree.ToDot(T.Root(), &buf); err != nil {
+		t.Fatal(err)
+	}
+	dot := buf.String()
+	if !strings.HasPrefix(dot, "digraph tree {") || strings.Count(dot, "->") != 3 || strings.Count(dot, "[label=") != 4 {
+		t.Errorf("wrong dot output\n%v", dot)
+	}
+}
@@ -39,6 +39,9 @@ func (self *BpMap) Put(key types.Hashable, value interface{}) (err error) {
 }
 
 func (self *BpMap) Get(key types.Hashable) (value interface{}, err error) {
+	if len(self.root.keys) == 0 {
+		return nil, errors.NotFound(key)
+	}
 	j, l := self.root.get_start(key)
 	if l.keys[j].Equals(key) {
 		return l.values[j], nil
@@ -65,6 +68,11 @@ func (self *BpMap) Remove(key types.Hashable) (value interface{}, err error) {
 	return value, nil
 }
 
+// Iterate over the keys in [from, to], backwards if from > to.
+func (self *BpMap) Range(from, to types.Hashable) (kvi types.KVIterator) {
+	return (*BpTree)(self).Range(from, to)
+}
+
 func (self *BpMap) Keys() (ki types.KIterator) {
 	return (*BpTree)(self).Keys()
 }
@@ -76,3 +84,8 @@ func (self *BpMap) Values() (vi types.Iterator) {
 func (self *BpMap) Iterate() (kvi types.KVIterator) {
 	return (*BpTree)(self).Iterate()
 }
+
+// Verify the structure of the tree, see BpTree.Verify.
+func (self *BpMap) Verify() error {
+	return (*BpTree)(self).Verify()
+}
@@ -1,6 +1,7 @@
 package bptree
 
 import (
+	"github.com/timtadh/data-structures/errors"
 	"github.com/timtadh/data-structures/types"
 )
 
@@ -22,6 +23,10 @@ func NewBpTree(node_size int) *BpTree {
 	}
 }
 
+func (self *BpTree) Root() types.TreeNode {
+	return self.root
+}
+
 func (self *BpTree) Size() int {
 	return self.size
 }
@@ -91,9 +96,59 @@ func (self *BpTree) Range(from, to types.Hashable) (kvi types.KVIterator) {
 	return kvi
 }
 
+// Returns at most n-1 distinct keys in (from, to), in order, which split the
+// keys in [from, to] into about n equal parts: [from, keys[0]), [keys[0],
+// keys[1]), ..., [keys[len(keys)-1], to]. The keys are taken from the
+// internal nodes so the parts are only as even as the tree is. A tree which
+// is a single leaf is not split.
+func (self *BpTree) SplitKeys(from, to types.Hashable, n int) []types.Hashable {
+	if n < 2 || to.Less(from) {
+		return nil
+	}
+	var keys []types.Hashable
+	level := []*BpNode{self.root}
+	// a level has the keys of the levels above it (the key of a child is its
+	// first key) so go down until there are enough
+	for len(level) > 0 && level[0].Internal() {
+		keys = keys[:0]
+		next := make([]*BpNode, 0, len(level))
+		for _, node := range level {
+			for i, key := range node.keys {
+				if from.Less(key) && key.Less(to) &&
+					(len(keys) == 0 || !keys[len(keys)-1].Equals(key)) {
+					keys = append(keys, key)
+				}
+				// child i has the keys in [key, node.keys[i+1])
+				if !to.Less(key) && (i+1 == len(node.keys) || from.Less(node.keys[i+1])) {
+					next = append(next, node.pointers[i])
+				}
+			}
+		}
+		if len(keys) >= n-1 {
+			break
+		}
+		level = next
+	}
+	if len(keys) <= n-1 {
+		return keys
+	}
+	split := make([]types.Hashable, 0, n-1)
+	for i := 1; i < n; i++ {
+		split = append(split, keys[i*len(keys)/n])
+	}
+	return split
+}
+
 func (self *BpTree) RemoveWhere(key types.Hashable, where types.WhereFunc) (err error) {
 	ns := self.root.NodeSize()
-	new_root, err := self.root.remove(key, where)
+	removed := 0
+	new_root, err := self.root.remove(key, func(value interface{}) bool {
+		if where(value) {
+			removed++
+			return true
+		}
+		return false
+	})
 	if err != nil {
 		return err
 	}
@@ -102,7 +157,7 @@ func (self *BpTree) RemoveWhere(key types.Hashable, where types.WhereFunc) (err
 	} else {
 		self.root = new_root
 	}
-	self.size -= 1
+	self.size -= removed
 	return nil
 }
 
@@ -160,3 +215,55 @@ func (self *BpTree) Backward() (kvi types.KVIterator) {
 	}
 	return kvi
 }
+
+/* Verify the structure of the tree:
+ *  - every node has the tree's node size and at most that many keys
+ *  - every node but the root has at least 1 key. Removes do not merge nodes
+ *    so this is the only lower bound
+ *  - the keys of internal nodes are the first keys of their children and
+ *    separate the key ranges of the children
+ *  - all of the leaves are at the same depth
+ *  - the leaves form a doubly linked list in key order. The only leaves not
+ *    referenced by an internal node are the (pure) overflow blocks of a run of
+ *    a duplicated key
+ *  - the size is the number of entries in the leaves
+ */
+func (self *BpTree) Verify() error {
+	leaves := make([]*BpNode, 0, 10)
+	leaf_depth := -1
+	if err := self.root.verify(nil, nil, self.root.NodeSize(), 0, &leaf_depth, &leaves); err != nil {
+		return err
+	}
+	if leaves[0].prev != nil {
+		return errors.BpTreeError("the left most leaf has a prev pointer")
+	}
+	var prev types.Hashable
+	count := 0
+	i := 0
+	for l := leaves[0]; l != nil; l = l.next {
+		if l.next != nil && l.next.prev != l {
+			return errors.BpTreeError("leaf %v is not linked back from the next leaf", l)
+		}
+		if i < len(leaves) && l == leaves[i] {
+			i++
+		} else if len(l.keys) == 0 || !l.Pure() || l.prev == nil || !l.prev.Pure() || !l.prev.keys[0].Equals(l.keys[0]) {
+			return errors.BpTreeError("leaf %v is not in the tree and is not part of a pure run", l)
+		}
+		for _, k := range l.keys {
+			if prev != nil && k.Less(prev) {
+				return errors.BpTreeError("key %v is out of order in the leaves", k)
+			} else if l.no_dup && prev != nil && k.Equals(prev) {
+				return errors.BpTreeError("duplicate key %v in a tree without duplicates", k)
+			}
+			prev = k
+			count++
+		}
+	}
+	if i != len(leaves) {
+		return errors.BpTreeError("%v leaves in the tree are not in the linked list", len(leaves)-i)
+	}
+	if count != self.size {
+		return errors.BpTreeError("size is %v but the tree has %v entries", self.size, count)
+	}
+	return nil
+}
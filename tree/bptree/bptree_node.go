@@ -1,5 +1,10 @@
 package bptree
 
+import (
+	"fmt"
+	"strings"
+)
+
 import (
 	"github.com/timtadh/data-structures/errors"
 	"github.com/timtadh/data-structures/types"
@@ -290,6 +295,11 @@ func (self *BpNode) leaf_insert(key types.Hashable, value interface{}) (a, b *Bp
 	}
 	if self.Full() {
 		return self.leaf_split(key, value)
+	} else if len(self.keys) > 0 && self.Pure() && !key.Equals(self.keys[0]) && self.next != nil && self.next.keys[0].Equals(self.keys[0]) {
+		// removals can leave room in a pure block which still has a run of
+		// overflow blocks after it. The block has to stay pure so another
+		// key goes in a new block before or after the run.
+		return self.pure_leaf_split(key, value)
 	} else {
 		if err := self.put_kv(key, value); err != nil {
 			return nil, nil, err
@@ -465,7 +475,7 @@ func (self *BpNode) remove(key types.Hashable, where types.WhereFunc) (a *BpNode
 	if self.Internal() {
 		return self.internal_remove(key, nil, where)
 	} else {
-		return self.leaf_remove(key, self.keys[len(self.keys)-1], where)
+		return self.leaf_remove(key, nil, where)
 	}
 }
 
@@ -514,32 +524,52 @@ func (self *BpNode) internal_remove(key types.Hashable, sibling *BpNode, where t
 	return self, nil
 }
 
+/* removes the entries of key selected by where from this leaf and from the
+ * pure overflow blocks which follow it. stop is the first key of the next leaf
+ * in the tree (nil if there is none). If this leaf becomes empty the next leaf
+ * which is not in the tree takes its place, otherwise nil is returned.
+ */
 func (self *BpNode) leaf_remove(key, stop types.Hashable, where types.WhereFunc) (a *BpNode, err error) {
 	if self.Internal() {
 		return nil, errors.BpTreeError("Expected a leaf node")
 	}
 	a = self
-	for j, l, next := self.forward(key, key)(); next != nil; j, l, next = next() {
-		if where(l.values[j]) {
-			if err := l.remove_key_at(j); err != nil {
-				return nil, err
-			}
-			if err := l.remove_value_at(j); err != nil {
-				return nil, err
+	l := self
+	i, _ := self.find(key)
+	for l != nil {
+		n := i
+		end := false
+		for ; i < len(l.keys); i++ {
+			if !end && l.keys[i].Equals(key) && where(l.values[i]) {
+				continue
 			}
+			end = end || !l.keys[i].Equals(key)
+			l.keys[n] = l.keys[i]
+			l.values[n] = l.values[i]
+			n++
 		}
+		for j := n; j < len(l.keys); j++ {
+			l.keys[j] = nil
+			l.values[j] = nil
+		}
+		l.keys = l.keys[:n]
+		l.values = l.values[:n]
+		next := l.next
 		if len(l.keys) == 0 {
 			remove_linked_list_node(l)
-			if l.next == nil {
-				a = nil
-			} else if stop == nil {
-				a = nil
-			} else if !l.next.keys[0].Equals(stop) {
-				a = l.next
-			} else {
-				a = nil
+			if l == a {
+				a = next
 			}
 		}
+		if end || next == nil || !next.keys[0].Equals(key) {
+			break
+		}
+		l, i = next, 0
+	}
+	if a == self {
+		return self, nil
+	} else if a == nil || (stop != nil && a.keys[0].Equals(stop)) {
+		return nil, nil
 	}
 	return a, nil
 }
@@ -649,7 +679,8 @@ func (self *BpNode) forward(from, to types.Hashable) (li loc_iterator) {
 	j--
 	li = func() (i int, leaf *BpNode, next loc_iterator) {
 		j, l, end = next_location(j, l)
-		if end || to.Less(l.keys[j]) {
+		// get_start gives the last key when from is past the end of the tree
+		if end || to.Less(l.keys[j]) || l.keys[j].Less(from) {
 			return -1, nil, nil
 		}
 		return j, l, li
@@ -658,8 +689,15 @@ func (self *BpNode) forward(from, to types.Hashable) (li loc_iterator) {
 }
 
 func (self *BpNode) backward(from, to types.Hashable) (li loc_iterator) {
+	if len(self.keys) == 0 {
+		return func() (int, *BpNode, loc_iterator) { return -1, nil, nil }
+	}
 	j, l := self.get_end(from)
 	end := false
+	if from.Less(l.keys[j]) {
+		// get_end gives the first key greater than from when from is missing
+		j, l, end = prev_location(j, l)
+	}
 	li = func() (i int, leaf *BpNode, next loc_iterator) {
 		if end || l.keys[j].Less(to) {
 			return -1, nil, nil
@@ -672,6 +710,58 @@ func (self *BpNode) backward(from, to types.Hashable) (li loc_iterator) {
 	return li
 }
 
+/* checks the subtree rooted at this node, every key must be in [lo, hi). The
+ * leaves are appended to leaves in order.
+ */
+// node_size is the tree's NodeSize. Removes do not merge nodes and splits
+// keep runs of a duplicated key together, so the least a node other than the
+// root may hold is 1 key.
+func (self *BpNode) verify(lo, hi types.Hashable, node_size, depth int, leaf_depth *int, leaves *[]*BpNode) error {
+	if len(self.keys) > node_size || cap(self.keys) != node_size {
+		return errors.BpTreeError("node %v has %v keys and a capacity of %v in a tree of node size %v", self, len(self.keys), cap(self.keys), node_size)
+	} else if len(self.keys) < 1 && (depth > 0 || self.Internal()) {
+		return errors.BpTreeError("node at depth %v is empty", depth)
+	}
+	for i := 1; i < len(self.keys); i++ {
+		if self.keys[i].Less(self.keys[i-1]) || (self.Internal() && self.keys[i].Equals(self.keys[i-1])) {
+			return errors.BpTreeError("keys out of order in node %v", self)
+		}
+	}
+	if len(self.keys) > 0 {
+		if lo != nil && self.keys[0].Less(lo) {
+			return errors.BpTreeError("key %v is less than the lower bound %v", self.keys[0], lo)
+		} else if hi != nil && !self.keys[len(self.keys)-1].Less(hi) {
+			return errors.BpTreeError("key %v is not less than the upper bound %v", self.keys[len(self.keys)-1], hi)
+		}
+	}
+	if !self.Internal() {
+		if len(self.values) != len(self.keys) {
+			return errors.BpTreeError("leaf has %v keys and %v values", len(self.keys), len(self.values))
+		} else if *leaf_depth >= 0 && *leaf_depth != depth {
+			return errors.BpTreeError("leaves at depths %v and %v", *leaf_depth, depth)
+		}
+		*leaf_depth = depth
+		*leaves = append(*leaves, self)
+		return nil
+	}
+	if len(self.pointers) != len(self.keys) {
+		return errors.BpTreeError("internal node has %v keys and %v pointers", len(self.keys), len(self.pointers))
+	}
+	for i, child := range self.pointers {
+		if len(child.keys) == 0 || !child.keys[0].Equals(self.keys[i]) {
+			return errors.BpTreeError("key %v does not match the first key of its child", self.keys[i])
+		}
+		child_hi := hi
+		if i+1 < len(self.keys) {
+			child_hi = self.keys[i+1]
+		}
+		if err := child.verify(self.keys[i], child_hi, node_size, depth+1, leaf_depth, leaves); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func insert_linked_list_node(n, prev, next *BpNode) {
 	if (prev != nil && prev.next != next) || (next != nil && next.prev != prev) {
 		panic(errors.BpTreeError("prev and next not hooked up"))
@@ -755,3 +845,46 @@ func balance_nodes(a, b *BpNode) {
 		a.pointers = a.pointers[:m]
 	}
 }
+
+// The first key in the node (or nil if it is empty).
+func (self *BpNode) Key() types.Hashable {
+	if len(self.keys) == 0 {
+		return nil
+	}
+	return self.keys[0]
+}
+
+// Always nil, the values of a leaf are available from Iterate on the tree.
+func (self *BpNode) Value() interface{} {
+	return nil
+}
+
+func (self *BpNode) Children() types.TreeNodeIterator {
+	i := 0
+	var tn_iterator types.TreeNodeIterator
+	tn_iterator = func() (types.TreeNode, types.TreeNodeIterator) {
+		if i >= len(self.pointers) {
+			return nil, nil
+		}
+		i++
+		return self.pointers[i-1], tn_iterator
+	}
+	return tn_iterator
+}
+
+func (self *BpNode) GetChild(i int) types.TreeNode {
+	return self.pointers[i]
+}
+
+func (self *BpNode) ChildCount() int {
+	return len(self.pointers)
+}
+
+// The keys in the node, for tree.ToDot and tree.Pretty.
+func (self *BpNode) Label() string {
+	keys := make([]string, 0, len(self.keys))
+	for _, k := range self.keys {
+		keys = append(keys, fmt.Sprint(k))
+	}
+	return "[" + strings.Join(keys, " ") + "]"
+}
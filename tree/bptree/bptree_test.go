@@ -1,7 +1,9 @@
 package bptree
 
 import (
+	"bytes"
 	"sort"
+	"strings"
 	"testing"
 
 	crand "crypto/rand"
@@ -10,6 +12,7 @@ import (
 
 	trand "github.com/timtadh/data-structures/rand"
 	"github.com/timtadh/data-structures/test"
+	"github.com/timtadh/data-structures/tree"
 	"github.com/timtadh/data-structures/types"
 )
 
@@ -283,6 +286,119 @@ func TestBpMap(t *testing.T) {
 	test(NewBpMap(23))
 }
 
+func TestBpMapGetEmpty(t *testing.T) {
+	m := NewBpMap(4)
+	if _, err := m.Get(types.Int(1)); err == nil {
+		t.Error("got a key from an empty map")
+	}
+	m.Put(types.Int(1), 1)
+	m.Remove(types.Int(1))
+	if _, err := m.Get(types.Int(1)); err == nil {
+		t.Error("got a removed key")
+	}
+}
+
+func TestRemoveWhereDuplicates(t *testing.T) {
+	bpt := NewBpTree(4)
+	if err := bpt.RemoveWhere(types.Int(1), func(interface{}) bool { return true }); err != nil {
+		t.Fatal(err)
+	}
+	// key 1 fills a leaf and a run of overflow blocks after it
+	bpt.Add(types.Int(0), 0)
+	for i := 0; i < 20; i++ {
+		bpt.Add(types.Int(1), i)
+	}
+	bpt.Add(types.Int(2), 0)
+	even := func(v interface{}) bool { return v.(int)%2 == 0 }
+	if err := bpt.RemoveWhere(types.Int(1), even); err != nil {
+		t.Fatal(err)
+	}
+	if err := bpt.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	if c := bpt.Count(types.Int(1)); c != 10 || bpt.Size() != 12 {
+		t.Fatalf("%v left of key 1 and %v in the tree", c, bpt.Size())
+	}
+	for _, v, next := bpt.Range(types.Int(1), types.Int(1))(); next != nil; _, v, next = next() {
+		if even(v) {
+			t.Fatalf("value %v was not removed", v)
+		}
+	}
+	if err := bpt.RemoveWhere(types.Int(1), func(interface{}) bool { return true }); err != nil {
+		t.Fatal(err)
+	}
+	if err := bpt.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	if bpt.Has(types.Int(1)) || bpt.Size() != 2 {
+		t.Fatalf("key 1 is left in a tree of %v", bpt.Size())
+	}
+}
+
+func TestInsertIntoEmptiedPureLeaf(t *testing.T) {
+	bpt := NewBpTree(4)
+	for i := 0; i < 12; i++ {
+		bpt.Add(types.Int(1), i)
+	}
+	// leaves room in the pure leaf at the head of the run
+	if err := bpt.RemoveWhere(types.Int(1), func(v interface{}) bool { return v.(int) < 2 }); err != nil {
+		t.Fatal(err)
+	}
+	bpt.Add(types.Int(2), 0)
+	bpt.Add(types.Int(0), 0)
+	if err := bpt.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	var prev types.Hashable
+	for k, _, next := bpt.Iterate()(); next != nil; k, _, next = next() {
+		if prev != nil && k.Less(prev) {
+			t.Fatalf("key %v after %v", k, prev)
+		}
+		prev = k
+	}
+	if bpt.Size() != 12 {
+		t.Fatalf("size %v", bpt.Size())
+	}
+}
+
+func TestRangeMissingEnds(t *testing.T) {
+	keys := func(bpt *BpTree, from, to int) (got []int) {
+		for k, _, next := bpt.Range(types.Int(from), types.Int(to))(); next != nil; k, _, next = next() {
+			got = append(got, int(k.(types.Int)))
+		}
+		return got
+	}
+	bpt := NewBpTree(4)
+	if got := keys(bpt, 5, 1); len(got) != 0 {
+		t.Fatalf("an empty tree has %v", got)
+	}
+	for i := 0; i < 10; i += 2 {
+		bpt.Add(types.Int(i), i)
+	}
+	for _, c := range []struct {
+		from, to int
+		want     []int
+	}{
+		{20, 30, nil},
+		{9, 20, nil},
+		{9, 9, nil},
+		{3, 7, []int{4, 6}},
+		{7, 3, []int{6, 4}},
+		{20, 5, []int{8, 6}},
+		{-1, -5, nil},
+	} {
+		got := keys(bpt, c.from, c.to)
+		if len(got) != len(c.want) {
+			t.Fatalf("range %v to %v is %v, expected %v", c.from, c.to, got, c.want)
+		}
+		for i := range got {
+			if got[i] != c.want[i] {
+				t.Fatalf("range %v to %v is %v, expected %v", c.from, c.to, got, c.want)
+			}
+		}
+	}
+}
+
 func Test_get_start(t *testing.T) {
 	root := NewLeaf(2, false)
 	root, err := root.put(types.Int(1), 1)
@@ -1348,3 +1464,70 @@ func Test_balance_internal_nodes(t *testing.T) {
 	t.Log(a)
 	t.Log(b)
 }
+
+func TestBpTreeNodesAsTreeNodes(t *testing.T) {
+	bpt := NewBpTree(3)
+	for i := 0; i < 20; i++ {
+		bpt.Add(types.Int(i), i)
+	}
+	leaves, keys := 0, 0
+	for tn, next := tree.TraverseTreePreOrder(bpt.Root())(); next != nil; tn, next = next() {
+		n := tn.(*BpNode)
+		if tn.ChildCount() == 0 {
+			leaves++
+			keys += len(n.keys)
+		}
+		if !n.keys[0].Equals(tn.Key()) {
+			t.Error("wrong key", tn.Key())
+		}
+	}
+	if keys != 20 {
+		t.Error("wrong number of keys in the leaves", keys)
+	}
+	lines := strings.Split(strings.TrimSpace(tree.Pretty(bpt.Root())), "\n")
+	if lines[0] != bpt.root.Label() || len(lines) < leaves+1 {
+		t.Error("wrong pretty tree\n", tree.Pretty(bpt.Root()))
+	}
+	var buf bytes.Buffer
+	if err := tree.ToDot(bpt.Root(), &buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `[label="[0 1]"]`) {
+		t.Errorf("missing first leaf in dot output\n%v", buf.String())
+	}
+}
+
+func TestSplitKeys(t *testing.T) {
+	bpt := NewBpTree(4)
+	if keys := bpt.SplitKeys(types.Int(0), types.Int(100), 4); len(keys) != 0 {
+		t.Error("split an empty tree", keys)
+	}
+	for i := 0; i < 1000; i++ {
+		bpt.Add(types.Int(i%500), i)
+	}
+	for _, n := range []int{1, 2, 4, 7, 16, 1000} {
+		from, to := types.Int(100), types.Int(400)
+		keys := bpt.SplitKeys(from, to, n)
+		if len(keys) > n-1 || (n > 1 && len(keys) == 0) {
+			t.Fatalf("%v split keys for %v parts", len(keys), n)
+		}
+		bounds := append(append([]types.Hashable{from}, keys...), to)
+		count := 0
+		for i := 1; i < len(bounds); i++ {
+			if !bounds[i-1].Less(bounds[i]) {
+				t.Fatalf("split keys are out of order %v", keys)
+			}
+			for k, _, next := bpt.Range(bounds[i-1], bounds[i])(); next != nil; k, _, next = next() {
+				if i == len(bounds)-1 || k.Less(bounds[i]) {
+					count++
+				}
+			}
+		}
+		if count != 602 {
+			t.Errorf("the %v parts have %v entries", n, count)
+		}
+	}
+	if keys := bpt.SplitKeys(types.Int(400), types.Int(100), 4); len(keys) != 0 {
+		t.Error("split a backward range", keys)
+	}
+}
@@ -0,0 +1,25 @@
+package bptree
+
+import (
+	"testing"
+)
+
+import (
+	"github.com/timtadh/data-structures/test"
+)
+
+// Small nodes so that a short op string splits nodes and builds runs of
+// duplicate keys spanning several leaves. Removes never merge nodes.
+func FuzzBpTree(f *testing.F) {
+	test.AddSeeds(f)
+	f.Fuzz(func(t *testing.T, ops []byte) {
+		test.CheckMultiMap(t, NewBpTree(3), ops, test.IntKey)
+	})
+}
+
+func FuzzBpMap(f *testing.F) {
+	test.AddSeeds(f)
+	f.Fuzz(func(t *testing.T, ops []byte) {
+		test.CheckMap(t, NewBpMap(3), ops, test.IntKey)
+	})
+}
@@ -0,0 +1,96 @@
+package bptree
+
+import (
+	"testing"
+
+	"github.com/timtadh/data-structures/types"
+)
+
+func TestVerify(t *testing.T) {
+	for _, size := range []int{2, 3, 4, 7, 16} {
+		bpt := NewBpTree(size)
+		if err := bpt.Verify(); err != nil {
+			t.Fatal(err)
+		}
+		counts := make(map[int]int)
+		for i := 0; i < 500; i++ {
+			// a small key space gives long runs of duplicates
+			k := rand.Intn(40)
+			counts[k]++
+			if err := bpt.Add(types.Int(k), i); err != nil {
+				t.Fatal(err)
+			}
+			if err := bpt.Verify(); err != nil {
+				t.Fatal(size, i, err)
+			}
+		}
+		for k := range counts {
+			if k%2 == 0 {
+				continue
+			}
+			if err := bpt.RemoveWhere(types.Int(k), func(v interface{}) bool { return v.(int)%2 == 0 }); err != nil {
+				t.Fatal(err)
+			}
+			if err := bpt.Verify(); err != nil {
+				t.Fatal(size, k, err)
+			}
+		}
+		count := 0
+		for _, _, next := bpt.Iterate()(); next != nil; _, _, next = next() {
+			count++
+		}
+		if count != bpt.Size() {
+			t.Fatal("wrong size", bpt.Size(), count)
+		}
+
+		m := NewBpMap(size)
+		for i := 0; i < 200; i++ {
+			m.Put(types.Int(rand.Intn(100)), i)
+			if err := m.Verify(); err != nil {
+				t.Fatal(size, i, err)
+			}
+		}
+	}
+}
+
+func TestVerifyFindsCorruption(t *testing.T) {
+	bpt := NewBpTree(4)
+	for i := 0; i < 50; i++ {
+		bpt.Add(types.Int(i), i)
+	}
+	bpt.size++
+	if err := bpt.Verify(); err == nil {
+		t.Error("expected a size error")
+	}
+	bpt.size--
+	leaf := bpt.root.left_most_leaf()
+	leaf.next.prev = nil
+	if err := bpt.Verify(); err == nil {
+		t.Error("expected a linked list error")
+	}
+	leaf.next.prev = leaf
+	leaf.keys[0], leaf.keys[1] = leaf.keys[1], leaf.keys[0]
+	if err := bpt.Verify(); err == nil {
+		t.Error("expected an ordering error")
+	}
+	leaf.keys[0], leaf.keys[1] = leaf.keys[1], leaf.keys[0]
+	if err := bpt.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	keys, values := leaf.keys, leaf.values
+	leaf.keys = append(make([]types.Hashable, 0, 5), keys...)
+	leaf.values = append(make([]interface{}, 0, 5), values...)
+	for len(leaf.keys) < 5 {
+		last := leaf.keys[len(leaf.keys)-1].(types.Int)
+		leaf.keys = append(leaf.keys, last)
+		leaf.values = append(leaf.values, int(last))
+	}
+	if err := bpt.Verify(); err == nil {
+		t.Error("expected a capacity error")
+	}
+	leaf.keys = make([]types.Hashable, 0, 4)
+	leaf.values = values[:0]
+	if err := bpt.Verify(); err == nil {
+		t.Error("expected an occupancy error")
+	}
+}
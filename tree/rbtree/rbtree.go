@@ -0,0 +1,284 @@
+package rbtree
+
+import (
+	"github.com/timtadh/data-structures/errors"
+	"github.com/timtadh/data-structures/tree"
+	"github.com/timtadh/data-structures/types"
+)
+
+const (
+	red   = true
+	black = false
+)
+
+/* A RbTree is a left leaning red-black tree (Sedgewick 2008). Every path from
+ * the root to a leaf has the same number of black links and red links always
+ * lean left. Insertion, retrieval and removal are O(log(n)).
+ */
+type RbTree struct {
+	root *RbNode
+	size int
+}
+
+func NewRbTree() *RbTree {
+	return &RbTree{}
+}
+
+func (self *RbTree) Root() types.TreeNode {
+	return self.root
+}
+
+func (self *RbTree) Size() int {
+	return self.size
+}
+
+func (self *RbTree) Has(key types.Hashable) bool {
+	return self.root.Has(key)
+}
+
+func (self *RbTree) Put(key types.Hashable, value interface{}) (err error) {
+	var updated bool
+	self.root, updated = self.root.put(key, value)
+	self.root.color = black
+	if !updated {
+		self.size++
+	}
+	return nil
+}
+
+func (self *RbTree) Get(key types.Hashable) (value interface{}, err error) {
+	return self.root.Get(key)
+}
+
+func (self *RbTree) Remove(key types.Hashable) (value interface{}, err error) {
+	value, err = self.root.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	if !self.root.left.isRed() && !self.root.right.isRed() {
+		self.root.color = red
+	}
+	self.root = self.root.remove(key)
+	if self.root != nil {
+		self.root.color = black
+	}
+	self.size--
+	return value, nil
+}
+
+func (self *RbTree) Iterate() types.KVIterator {
+	return self.root.Iterate()
+}
+
+func (self *RbTree) Items() (vi types.KIterator) {
+	return types.MakeItemsIterator(self)
+}
+
+func (self *RbTree) Values() types.Iterator {
+	return self.root.Values()
+}
+
+func (self *RbTree) Keys() types.KIterator {
+	return self.root.Keys()
+}
+
+type RbNode struct {
+	key   types.Hashable
+	value interface{}
+	color bool
+	left  *RbNode
+	right *RbNode
+}
+
+func (self *RbNode) isRed() bool {
+	return self != nil && self.color == red
+}
+
+func (self *RbNode) Has(key types.Hashable) (has bool) {
+	_, err := self.Get(key)
+	return err == nil
+}
+
+func (self *RbNode) Get(key types.Hashable) (value interface{}, err error) {
+	for self != nil {
+		if self.key.Equals(key) {
+			return self.value, nil
+		} else if key.Less(self.key) {
+			self = self.left
+		} else {
+			self = self.right
+		}
+	}
+	return nil, errors.NotFound(key)
+}
+
+func (self *RbNode) rotate_left() *RbNode {
+	x := self.right
+	self.right = x.left
+	x.left = self
+	x.color = self.color
+	self.color = red
+	return x
+}
+
+func (self *RbNode) rotate_right() *RbNode {
+	x := self.left
+	self.left = x.right
+	x.right = self
+	x.color = self.color
+	self.color = red
+	return x
+}
+
+func (self *RbNode) flip_colors() {
+	self.color = !self.color
+	self.left.color = !self.left.color
+	self.right.color = !self.right.color
+}
+
+// restore the left leaning invariants on the way back up the tree
+func (self *RbNode) fix_up() *RbNode {
+	if self.right.isRed() && !self.left.isRed() {
+		self = self.rotate_left()
+	}
+	if self.left.isRed() && self.left.left.isRed() {
+		self = self.rotate_right()
+	}
+	if self.left.isRed() && self.right.isRed() {
+		self.flip_colors()
+	}
+	return self
+}
+
+func (self *RbNode) put(key types.Hashable, value interface{}) (_ *RbNode, updated bool) {
+	if self == nil {
+		return &RbNode{key: key, value: value, color: red}, false
+	}
+	if self.key.Equals(key) {
+		self.value = value
+		return self, true
+	} else if key.Less(self.key) {
+		self.left, updated = self.left.put(key, value)
+	} else {
+		self.right, updated = self.right.put(key, value)
+	}
+	return self.fix_up(), updated
+}
+
+func (self *RbNode) move_red_left() *RbNode {
+	self.flip_colors()
+	if self.right.left.isRed() {
+		self.right = self.right.rotate_right()
+		self = self.rotate_left()
+		self.flip_colors()
+	}
+	return self
+}
+
+func (self *RbNode) move_red_right() *RbNode {
+	self.flip_colors()
+	if self.left.left.isRed() {
+		self = self.rotate_right()
+		self.flip_colors()
+	}
+	return self
+}
+
+func (self *RbNode) remove_min() *RbNode {
+	if self.left == nil {
+		return nil
+	}
+	if !self.left.isRed() && !self.left.left.isRed() {
+		self = self.move_red_left()
+	}
+	self.left = self.left.remove_min()
+	return self.fix_up()
+}
+
+// the key must be in the tree
+func (self *RbNode) remove(key types.Hashable) *RbNode {
+	if key.Less(self.key) {
+		if !self.left.isRed() && !self.left.left.isRed() {
+			self = self.move_red_left()
+		}
+		self.left = self.left.remove(key)
+	} else {
+		if self.left.isRed() {
+			self = self.rotate_right()
+		}
+		if self.key.Equals(key) && self.right == nil {
+			return nil
+		}
+		if !self.right.isRed() && !self.right.left.isRed() {
+			self = self.move_red_right()
+		}
+		if self.key.Equals(key) {
+			min := self.right.lmd()
+			self.key = min.key
+			self.value = min.value
+			self.right = self.right.remove_min()
+		} else {
+			self.right = self.right.remove(key)
+		}
+	}
+	return self.fix_up()
+}
+
+func (self *RbNode) lmd() *RbNode {
+	for self.left != nil {
+		self = self.left
+	}
+	return self
+}
+
+// Is the link from this node's parent to this node red?
+func (self *RbNode) Red() bool {
+	return self.isRed()
+}
+
+func (self *RbNode) Key() types.Hashable {
+	return self.key
+}
+
+func (self *RbNode) Value() interface{} {
+	return self.value
+}
+
+func (self *RbNode) Left() types.BinaryTreeNode {
+	if self.left == nil {
+		return nil
+	}
+	return self.left
+}
+
+func (self *RbNode) Right() types.BinaryTreeNode {
+	if self.right == nil {
+		return nil
+	}
+	return self.right
+}
+
+func (self *RbNode) GetChild(i int) types.TreeNode {
+	return types.DoGetChild(self, i)
+}
+
+func (self *RbNode) ChildCount() int {
+	return types.DoChildCount(self)
+}
+
+func (self *RbNode) Children() types.TreeNodeIterator {
+	return types.MakeChildrenIterator(self)
+}
+
+func (self *RbNode) Iterate() types.KVIterator {
+	tni := tree.TraverseBinaryTreeInOrder(self)
+	return types.MakeKVIteratorFromTreeNodeIterator(tni)
+}
+
+func (self *RbNode) Keys() types.KIterator {
+	return types.MakeKeysIterator(self)
+}
+
+func (self *RbNode) Values() types.Iterator {
+	return types.MakeValuesIterator(self)
+}
@@ -0,0 +1,172 @@
+package rbtree
+
+import (
+	"testing"
+
+	crand "crypto/rand"
+	"encoding/binary"
+	mrand "math/rand"
+
+	trand "github.com/timtadh/data-structures/rand"
+	"github.com/timtadh/data-structures/test"
+	"github.com/timtadh/data-structures/types"
+)
+
+var rand *mrand.Rand
+
+func init() {
+	seed := make([]byte, 8)
+	if _, err := crand.Read(seed); err == nil {
+		rand = trand.ThreadSafeRand(int64(binary.BigEndian.Uint64(seed)))
+	} else {
+		panic(err)
+	}
+}
+
+func randstr(length int) types.String {
+	return types.String(test.RandStr(length))
+}
+
+// returns the black height of the tree rooted at n
+func check(t *testing.T, n *RbNode) int {
+	if n == nil {
+		return 1
+	}
+	if n.right.isRed() {
+		t.Fatal("red right link at", n.key)
+	}
+	if n.isRed() && n.left.isRed() {
+		t.Fatal("two red links in a row at", n.key)
+	}
+	if n.left != nil && !n.left.key.Less(n.key) {
+		t.Fatal("keys out of order at", n.key)
+	}
+	if n.right != nil && !n.key.Less(n.right.key) {
+		t.Fatal("keys out of order at", n.key)
+	}
+	l := check(t, n.left)
+	r := check(t, n.right)
+	if l != r {
+		t.Fatal("black height mismatch at", n.key, l, r)
+	}
+	if !n.isRed() {
+		return l + 1
+	}
+	return l
+}
+
+func TestRbPutHasGetRemove(t *testing.T) {
+
+	type record struct {
+		key   types.String
+		value types.String
+	}
+
+	records := make([]*record, 400)
+	tree := NewRbTree()
+
+	ranrec := func() *record {
+		return &record{randstr(20), randstr(20)}
+	}
+
+	for i := range records {
+		r := ranrec()
+		records[i] = r
+		if err := tree.Put(r.key, types.String("")); err != nil {
+			t.Error(err)
+		}
+		if err := tree.Put(r.key, r.value); err != nil {
+			t.Error(err)
+		}
+		if tree.Size() != (i + 1) {
+			t.Error("size was wrong", tree.Size(), i+1)
+		}
+		check(t, tree.root)
+	}
+
+	for _, r := range records {
+		if has := tree.Has(r.key); !has {
+			t.Error("Missing key")
+		}
+		if has := tree.Has(randstr(12)); has {
+			t.Error("Table has extra key")
+		}
+		if val, err := tree.Get(r.key); err != nil {
+			t.Error(err)
+		} else if !(val.(types.String)).Equals(r.value) {
+			t.Error("wrong value")
+		}
+	}
+
+	for i, x := range records {
+		if val, err := tree.Remove(x.key); err != nil {
+			t.Error(err)
+		} else if !(val.(types.String)).Equals(x.value) {
+			t.Error("wrong value")
+		}
+		if _, err := tree.Remove(x.key); err == nil {
+			t.Error("removed a missing key")
+		}
+		check(t, tree.root)
+		for _, r := range records[i+1:] {
+			if has := tree.Has(r.key); !has {
+				t.Error("Missing key")
+			}
+		}
+		if tree.Size() != (len(records) - (i + 1)) {
+			t.Error("size was wrong", tree.Size(), (len(records) - (i + 1)))
+		}
+	}
+}
+
+func TestRbIterators(t *testing.T) {
+	tree := NewRbTree()
+	for _, i := range rand.Perm(100) {
+		if err := tree.Put(types.Int(i), i); err != nil {
+			t.Error(err)
+		}
+	}
+	j := 0
+	for k, v, next := tree.Iterate()(); next != nil; k, v, next = next() {
+		if !k.Equals(types.Int(j)) {
+			t.Error("Wrong key", k, j)
+		}
+		if v.(int) != j {
+			t.Error("Wrong value", v, j)
+		}
+		j++
+	}
+	if j != 100 {
+		t.Error("wrong number of items", j)
+	}
+}
+
+func BenchmarkRbTree(b *testing.B) {
+	b.StopTimer()
+
+	type record struct {
+		key   types.String
+		value types.String
+	}
+
+	records := make([]*record, 100)
+
+	ranrec := func() *record {
+		return &record{randstr(20), randstr(20)}
+	}
+
+	for i := range records {
+		records[i] = ranrec()
+	}
+
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		t := NewRbTree()
+		for _, r := range records {
+			t.Put(r.key, r.value)
+		}
+		for _, r := range records {
+			t.Remove(r.key)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package tree
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+import (
+	"github.com/timtadh/data-structures/types"
+)
+
+// A node type may implement Labeled to control how it is shown by ToDot and
+// Pretty. Otherwise the key (and the value if it is not nil) is shown.
+type Labeled interface {
+	Label() string
+}
+
+func Label(node types.TreeNode) string {
+	if l, ok := node.(Labeled); ok {
+		return l.Label()
+	} else if v := node.Value(); v != nil {
+		return fmt.Sprintf("%v: %v", node.Key(), v)
+	}
+	return fmt.Sprint(node.Key())
+}
+
+func dot_escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+// Writes the tree as a Graphviz digraph. Render it with
+//
+//	dot -Tpng -o tree.png tree.dot
+func ToDot(root types.TreeNode, w io.Writer) error {
+	type entry struct {
+		tn types.TreeNode
+		id int
+	}
+	out := bufio.NewWriter(w)
+	fmt.Fprintln(out, "digraph tree {")
+	fmt.Fprintln(out, "  node [shape=box];")
+	ids := 0
+	stack := make([]entry, 0, 10)
+	if tn := tn_expose_nil(root); tn != nil {
+		stack = append(stack, entry{tn, ids})
+		ids++
+	}
+	for len(stack) > 0 {
+		e := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		fmt.Fprintf(out, "  n%d [label=\"%s\"];\n", e.id, dot_escape(Label(e.tn)))
+		for child, next := e.tn.Children()(); next != nil; child, next = next() {
+			if kid := tn_expose_nil(child); kid != nil {
+				fmt.Fprintf(out, "  n%d -> n%d;\n", e.id, ids)
+				stack = append(stack, entry{kid, ids})
+				ids++
+			}
+		}
+	}
+	fmt.Fprintln(out, "}")
+	return out.Flush()
+}
+
+// Draws the tree with one node per line, children are indented under their
+// parent. When a binary tree node has only one child the missing side is
+// drawn as "-" so left and right children can be told apart.
+func Pretty(root types.TreeNode) string {
+	var buf strings.Builder
+	var pretty func(tn types.TreeNode, prefix, branch, indent string)
+	pretty = func(tn types.TreeNode, prefix, branch, indent string) {
+		if tn == nil {
+			buf.WriteString(prefix + branch + "-\n")
+			return
+		}
+		buf.WriteString(prefix + branch + strings.Replace(Label(tn), "\n", " ", -1) + "\n")
+		kids := make([]types.TreeNode, 0, 2)
+		if btn, ok := tn.(types.BinaryTreeNode); ok {
+			l, r := tn_expose_nil(btn.Left()), tn_expose_nil(btn.Right())
+			if l != nil || r != nil {
+				kids = append(kids, l, r)
+			}
+		} else {
+			for child, next := tn.Children()(); next != nil; child, next = next() {
+				if kid := tn_expose_nil(child); kid != nil {
+					kids = append(kids, kid)
+				}
+			}
+		}
+		for i, kid := range kids {
+			if i == len(kids)-1 {
+				pretty(kid, prefix+indent, "`-- ", "    ")
+			} else {
+				pretty(kid, prefix+indent, "|-- ", "|   ")
+			}
+		}
+	}
+	if tn := tn_expose_nil(root); tn != nil {
+		pretty(tn, "", "", "")
+	}
+	return buf.String()
+}
@@ -0,0 +1,93 @@
+package segment
+
+import (
+	"github.com/timtadh/data-structures/errors"
+)
+
+/* A Fenwick (or binary indexed) tree stores an array of integers so that a
+ * single item can be changed and the sum of any prefix can be computed in
+ * O(log(n)). Indexes are 0 based like list.List.
+ */
+type Fenwick struct {
+	tree []int
+}
+
+// A Fenwick tree over n zeros.
+func NewFenwick(n int) *Fenwick {
+	return &Fenwick{tree: make([]int, n)}
+}
+
+// Build a Fenwick tree over the items in O(n).
+func FenwickFromSlice(items []int) *Fenwick {
+	tree := make([]int, len(items))
+	copy(tree, items)
+	for i := range tree {
+		if p := i | (i + 1); p < len(tree) {
+			tree[p] += tree[i]
+		}
+	}
+	return &Fenwick{tree: tree}
+}
+
+func (self *Fenwick) Size() int {
+	return len(self.tree)
+}
+
+func (self *Fenwick) check(i int) error {
+	if i < 0 || i >= len(self.tree) {
+		return errors.Errorf("Access out of bounds. len(*Fenwick) = %v, idx = %v", len(self.tree), i)
+	}
+	return nil
+}
+
+// Add delta to the item at i.
+func (self *Fenwick) Add(i, delta int) error {
+	if err := self.check(i); err != nil {
+		return err
+	}
+	for ; i < len(self.tree); i |= i + 1 {
+		self.tree[i] += delta
+	}
+	return nil
+}
+
+// The item at i.
+func (self *Fenwick) Get(i int) (int, error) {
+	return self.RangeSum(i, i)
+}
+
+// Set the item at i to value.
+func (self *Fenwick) Set(i, value int) error {
+	old, err := self.Get(i)
+	if err != nil {
+		return err
+	}
+	return self.Add(i, value-old)
+}
+
+// The sum of the items at 0 through i (inclusive).
+func (self *Fenwick) PrefixSum(i int) (int, error) {
+	if err := self.check(i); err != nil {
+		return 0, err
+	}
+	return self.prefix(i), nil
+}
+
+// The sum of the items at i through j (inclusive).
+func (self *Fenwick) RangeSum(i, j int) (int, error) {
+	if err := self.check(i); err != nil {
+		return 0, err
+	} else if err := self.check(j); err != nil {
+		return 0, err
+	} else if j < i {
+		return 0, errors.Errorf("Invalid range [%v, %v]", i, j)
+	}
+	return self.prefix(j) - self.prefix(i-1), nil
+}
+
+func (self *Fenwick) prefix(i int) (sum int) {
+	for ; i >= 0; i = (i & (i + 1)) - 1 {
+		sum += self.tree[i]
+	}
+	return sum
+}
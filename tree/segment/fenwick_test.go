@@ -0,0 +1,84 @@
+package segment
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	mrand "math/rand"
+	"testing"
+
+	trand "github.com/timtadh/data-structures/rand"
+)
+
+var rand *mrand.Rand
+
+func init() {
+	seed := make([]byte, 8)
+	if _, err := crand.Read(seed); err == nil {
+		rand = trand.ThreadSafeRand(int64(binary.BigEndian.Uint64(seed)))
+	} else {
+		panic(err)
+	}
+}
+
+func randItems(n int) []int {
+	items := make([]int, n)
+	for i := range items {
+		items[i] = rand.Intn(200) - 100
+	}
+	return items
+}
+
+func TestFenwick(t *testing.T) {
+	items := randItems(100)
+	f := FenwickFromSlice(items)
+	g := NewFenwick(len(items))
+	for i, v := range items {
+		if err := g.Add(i, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for round := 0; round < 500; round++ {
+		i := rand.Intn(len(items))
+		d := rand.Intn(20) - 10
+		items[i] += d
+		f.Add(i, d)
+		g.Add(i, d)
+		if round%7 == 0 {
+			items[i] = rand.Intn(100)
+			f.Set(i, items[i])
+			g.Set(i, items[i])
+		}
+		lo := rand.Intn(len(items))
+		hi := lo + rand.Intn(len(items)-lo)
+		sum := 0
+		for _, v := range items[lo : hi+1] {
+			sum += v
+		}
+		if s, err := f.RangeSum(lo, hi); err != nil || s != sum {
+			t.Fatal("wrong range sum", lo, hi, s, sum, err)
+		}
+		if s, err := g.RangeSum(lo, hi); err != nil || s != sum {
+			t.Fatal("wrong range sum", lo, hi, s, sum, err)
+		}
+	}
+	sum := 0
+	for i, v := range items {
+		sum += v
+		if s, err := f.PrefixSum(i); err != nil || s != sum {
+			t.Fatal("wrong prefix sum", i, s, sum, err)
+		}
+	}
+}
+
+func TestFenwickOutOfBounds(t *testing.T) {
+	f := NewFenwick(10)
+	if err := f.Add(10, 1); err == nil {
+		t.Error("expected an error")
+	}
+	if _, err := f.PrefixSum(-1); err == nil {
+		t.Error("expected an error")
+	}
+	if _, err := f.RangeSum(5, 4); err == nil {
+		t.Error("expected an error")
+	}
+}
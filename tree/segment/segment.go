@@ -0,0 +1,211 @@
+package segment
+
+import (
+	"github.com/timtadh/data-structures/errors"
+	"github.com/timtadh/data-structures/types"
+)
+
+// An associative function used to summarize a range of items.
+type Combine func(a, b int) int
+
+// The combination of n copies of v. For a sum this is v*n, for an idempotent
+// combination such as min or max it is just v.
+type Repeat func(v, n int) int
+
+// pending updates which have not been pushed down to the children of a node.
+// An assignment always happens before the increment.
+type tag struct {
+	assign   bool
+	value    int
+	increase int
+}
+
+/* A SegmentTree summarizes an array of integers with a user supplied
+ * associative Combine function. Querying the combination of a range, updating
+ * a single item and lazily assigning or incrementing a whole range are all
+ * O(log(n)). Indexes are 0 based like list.List and ranges are inclusive.
+ *
+ * Increment adds repeat(delta, n) to the summary of n items so it is only
+ * correct for combinations, like sum, min and max, where that holds.
+ */
+type SegmentTree struct {
+	size    int
+	tree    []int
+	tags    []tag
+	combine Combine
+	repeat  Repeat
+}
+
+func New(items []int, combine Combine, repeat Repeat) *SegmentTree {
+	self := &SegmentTree{
+		size:    len(items),
+		tree:    make([]int, 4*len(items)),
+		tags:    make([]tag, 4*len(items)),
+		combine: combine,
+		repeat:  repeat,
+	}
+	if len(items) > 0 {
+		self.build(1, 0, len(items)-1, items)
+	}
+	return self
+}
+
+func NewSum(items []int) *SegmentTree {
+	return New(items,
+		func(a, b int) int { return a + b },
+		func(v, n int) int { return v * n })
+}
+
+func NewMin(items []int) *SegmentTree {
+	return New(items,
+		func(a, b int) int {
+			if b < a {
+				return b
+			}
+			return a
+		},
+		func(v, n int) int { return v })
+}
+
+func NewMax(items []int) *SegmentTree {
+	return New(items,
+		func(a, b int) int {
+			if b > a {
+				return b
+			}
+			return a
+		},
+		func(v, n int) int { return v })
+}
+
+func (self *SegmentTree) Size() int {
+	return self.size
+}
+
+func (self *SegmentTree) check(i, j int) error {
+	if i < 0 || i >= self.size {
+		return errors.Errorf("Access out of bounds. len(*SegmentTree) = %v, idx = %v", self.size, i)
+	} else if j < 0 || j >= self.size {
+		return errors.Errorf("Access out of bounds. len(*SegmentTree) = %v, idx = %v", self.size, j)
+	} else if j < i {
+		return errors.Errorf("Invalid range [%v, %v]", i, j)
+	}
+	return nil
+}
+
+func (self *SegmentTree) Get(i int) (int, error) {
+	return self.Query(i, i)
+}
+
+func (self *SegmentTree) Set(i, value int) error {
+	return self.Assign(i, i, value)
+}
+
+// The combination of the items at i through j (inclusive).
+func (self *SegmentTree) Query(i, j int) (int, error) {
+	if err := self.check(i, j); err != nil {
+		return 0, err
+	}
+	return self.query(1, 0, self.size-1, i, j), nil
+}
+
+// Set every item at i through j (inclusive) to value.
+func (self *SegmentTree) Assign(i, j, value int) error {
+	if err := self.check(i, j); err != nil {
+		return err
+	}
+	self.update(1, 0, self.size-1, i, j, tag{assign: true, value: value})
+	return nil
+}
+
+// Add delta to every item at i through j (inclusive).
+func (self *SegmentTree) Increment(i, j, delta int) error {
+	if err := self.check(i, j); err != nil {
+		return err
+	}
+	self.update(1, 0, self.size-1, i, j, tag{increase: delta})
+	return nil
+}
+
+// Iterate over the items (as types.Int) in index order.
+func (self *SegmentTree) Items() (it types.KIterator) {
+	i := 0
+	it = func() (item types.Hashable, next types.KIterator) {
+		if i >= self.size {
+			return nil, nil
+		}
+		item = types.Int(self.query(1, 0, self.size-1, i, i))
+		i++
+		return item, it
+	}
+	return it
+}
+
+func (self *SegmentTree) build(n, lo, hi int, items []int) {
+	if lo == hi {
+		self.tree[n] = items[lo]
+		return
+	}
+	mid := (lo + hi) / 2
+	self.build(2*n, lo, mid, items)
+	self.build(2*n+1, mid+1, hi, items)
+	self.tree[n] = self.combine(self.tree[2*n], self.tree[2*n+1])
+}
+
+// apply the update t to node n which covers count items
+func (self *SegmentTree) apply(n, count int, t tag) {
+	p := &self.tags[n]
+	if t.assign {
+		self.tree[n] = self.repeat(t.value, count)
+		*p = tag{assign: true, value: t.value}
+	}
+	if t.increase != 0 {
+		self.tree[n] += self.repeat(t.increase, count)
+		if p.assign {
+			p.value += t.increase
+		} else {
+			p.increase += t.increase
+		}
+	}
+}
+
+func (self *SegmentTree) push(n, lo, hi int) {
+	t := self.tags[n]
+	if !t.assign && t.increase == 0 {
+		return
+	}
+	mid := (lo + hi) / 2
+	self.apply(2*n, mid-lo+1, t)
+	self.apply(2*n+1, hi-mid, t)
+	self.tags[n] = tag{}
+}
+
+func (self *SegmentTree) update(n, lo, hi, i, j int, t tag) {
+	if i <= lo && hi <= j {
+		self.apply(n, hi-lo+1, t)
+		return
+	}
+	self.push(n, lo, hi)
+	mid := (lo + hi) / 2
+	if i <= mid {
+		self.update(2*n, lo, mid, i, j, t)
+	}
+	if j > mid {
+		self.update(2*n+1, mid+1, hi, i, j, t)
+	}
+	self.tree[n] = self.combine(self.tree[2*n], self.tree[2*n+1])
+}
+
+func (self *SegmentTree) query(n, lo, hi, i, j int) int {
+	if i <= lo && hi <= j {
+		return self.tree[n]
+	}
+	self.push(n, lo, hi)
+	mid := (lo + hi) / 2
+	if j <= mid {
+		return self.query(2*n, lo, mid, i, j)
+	} else if i > mid {
+		return self.query(2*n+1, mid+1, hi, i, j)
+	}
+	return self.combine(self.query(2*n, lo, mid, i, j), self.query(2*n+1, mid+1, hi, i, j))
+}
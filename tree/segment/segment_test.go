@@ -0,0 +1,102 @@
+package segment
+
+import (
+	"testing"
+
+	"github.com/timtadh/data-structures/types"
+)
+
+func TestSegmentTrees(t *testing.T) {
+	type summary func(items []int) int
+	trees := map[string]struct {
+		make func([]int) *SegmentTree
+		sum  summary
+	}{
+		"sum": {NewSum, func(items []int) (s int) {
+			for _, v := range items {
+				s += v
+			}
+			return s
+		}},
+		"min": {NewMin, func(items []int) int {
+			m := items[0]
+			for _, v := range items {
+				if v < m {
+					m = v
+				}
+			}
+			return m
+		}},
+		"max": {NewMax, func(items []int) int {
+			m := items[0]
+			for _, v := range items {
+				if v > m {
+					m = v
+				}
+			}
+			return m
+		}},
+	}
+	for name, x := range trees {
+		items := randItems(rand.Intn(100) + 1)
+		tree := x.make(items)
+		for round := 0; round < 1000; round++ {
+			lo := rand.Intn(len(items))
+			hi := lo + rand.Intn(len(items)-lo)
+			switch rand.Intn(4) {
+			case 0:
+				v := rand.Intn(100)
+				for i := lo; i <= hi; i++ {
+					items[i] = v
+				}
+				if err := tree.Assign(lo, hi, v); err != nil {
+					t.Fatal(err)
+				}
+			case 1:
+				d := rand.Intn(20) - 10
+				for i := lo; i <= hi; i++ {
+					items[i] += d
+				}
+				if err := tree.Increment(lo, hi, d); err != nil {
+					t.Fatal(err)
+				}
+			case 2:
+				items[lo] = rand.Intn(100)
+				if err := tree.Set(lo, items[lo]); err != nil {
+					t.Fatal(err)
+				}
+			}
+			lo = rand.Intn(len(items))
+			hi = lo + rand.Intn(len(items)-lo)
+			if v, err := tree.Query(lo, hi); err != nil || v != x.sum(items[lo:hi+1]) {
+				t.Fatal(name, "wrong summary", lo, hi, v, x.sum(items[lo:hi+1]), err)
+			}
+		}
+		i := 0
+		for item, next := tree.Items()(); next != nil; item, next = next() {
+			if int(item.(types.Int)) != items[i] {
+				t.Fatal(name, "wrong item", i, item, items[i])
+			}
+			i++
+		}
+		if i != len(items) {
+			t.Fatal(name, "wrong number of items", i)
+		}
+	}
+}
+
+func TestSegmentTreeOutOfBounds(t *testing.T) {
+	tree := NewSum(make([]int, 10))
+	if _, err := tree.Query(0, 10); err == nil {
+		t.Error("expected an error")
+	}
+	if err := tree.Increment(-1, 3, 1); err == nil {
+		t.Error("expected an error")
+	}
+	if err := tree.Assign(3, 2, 1); err == nil {
+		t.Error("expected an error")
+	}
+	if _, err := NewMin(nil).Get(0); err == nil {
+		t.Error("expected an error")
+	}
+}
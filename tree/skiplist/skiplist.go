@@ -0,0 +1,171 @@
+package skiplist
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	mrand "math/rand"
+)
+
+import (
+	"github.com/timtadh/data-structures/errors"
+	trand "github.com/timtadh/data-structures/rand"
+	"github.com/timtadh/data-structures/types"
+)
+
+var rand *mrand.Rand
+
+func init() {
+	seed := make([]byte, 8)
+	if _, err := crand.Read(seed); err == nil {
+		rand = trand.ThreadSafeRand(int64(binary.BigEndian.Uint64(seed)))
+	} else {
+		panic(err)
+	}
+}
+
+const MAX_LEVEL = 32
+
+type node struct {
+	key   types.Hashable
+	value interface{}
+	next  []*node
+}
+
+/* A SkipList is a sorted linked list with express lanes. Each node is promoted
+ * to the next level with probability 1/2 so searches skip over about half of
+ * the remaining nodes at each level. Insertion, retrieval and removal are
+ * expected O(log(n)). It is not a tree so it is a types.Map but not a
+ * types.TreeMap.
+ */
+type SkipList struct {
+	head  *node
+	level int
+	size  int
+}
+
+func NewSkipList() *SkipList {
+	return &SkipList{
+		head:  &node{next: make([]*node, MAX_LEVEL)},
+		level: 1,
+	}
+}
+
+func random_level() int {
+	level := 1
+	for bits := rand.Uint32(); bits&1 == 1 && level < MAX_LEVEL; bits >>= 1 {
+		level++
+	}
+	return level
+}
+
+func (self *SkipList) Size() int {
+	return self.size
+}
+
+/* Fills update with the right most node at each level whose key is less than
+ * the search key and returns the first node at the bottom level whose key is
+ * greater than or equal to the search key (or nil).
+ */
+func (self *SkipList) find(key types.Hashable, update []*node) *node {
+	cur := self.head
+	for i := self.level - 1; i >= 0; i-- {
+		for cur.next[i] != nil && cur.next[i].key.Less(key) {
+			cur = cur.next[i]
+		}
+		if update != nil {
+			update[i] = cur
+		}
+	}
+	return cur.next[0]
+}
+
+func (self *SkipList) Has(key types.Hashable) bool {
+	n := self.find(key, nil)
+	return n != nil && n.key.Equals(key)
+}
+
+func (self *SkipList) Get(key types.Hashable) (value interface{}, err error) {
+	n := self.find(key, nil)
+	if n == nil || !n.key.Equals(key) {
+		return nil, errors.NotFound(key)
+	}
+	return n.value, nil
+}
+
+func (self *SkipList) Put(key types.Hashable, value interface{}) (err error) {
+	update := make([]*node, MAX_LEVEL)
+	n := self.find(key, update)
+	if n != nil && n.key.Equals(key) {
+		n.value = value
+		return nil
+	}
+	level := random_level()
+	for i := self.level; i < level; i++ {
+		update[i] = self.head
+	}
+	if level > self.level {
+		self.level = level
+	}
+	n = &node{key: key, value: value, next: make([]*node, level)}
+	for i := 0; i < level; i++ {
+		n.next[i] = update[i].next[i]
+		update[i].next[i] = n
+	}
+	self.size++
+	return nil
+}
+
+func (self *SkipList) Remove(key types.Hashable) (value interface{}, err error) {
+	update := make([]*node, MAX_LEVEL)
+	n := self.find(key, update)
+	if n == nil || !n.key.Equals(key) {
+		return nil, errors.NotFound(key)
+	}
+	for i := 0; i < len(n.next); i++ {
+		update[i].next[i] = n.next[i]
+	}
+	for self.level > 1 && self.head.next[self.level-1] == nil {
+		self.level--
+	}
+	self.size--
+	return n.value, nil
+}
+
+func (self *SkipList) Iterate() (kvi types.KVIterator) {
+	cur := self.head.next[0]
+	kvi = func() (key types.Hashable, value interface{}, next types.KVIterator) {
+		if cur == nil {
+			return nil, nil, nil
+		}
+		key, value = cur.key, cur.value
+		cur = cur.next[0]
+		return key, value, kvi
+	}
+	return kvi
+}
+
+// Iterate over the keys in [from, to].
+func (self *SkipList) Range(from, to types.Hashable) (kvi types.KVIterator) {
+	cur := self.find(from, nil)
+	kvi = func() (key types.Hashable, value interface{}, next types.KVIterator) {
+		if cur == nil || to.Less(cur.key) {
+			return nil, nil, nil
+		}
+		key, value = cur.key, cur.value
+		cur = cur.next[0]
+		return key, value, kvi
+	}
+	return kvi
+}
+
+func (self *SkipList) Items() (vi types.KIterator) {
+	return types.MakeItemsIterator(self)
+}
+
+func (self *SkipList) Values() types.Iterator {
+	return types.MakeValuesIterator(self)
+}
+
+func (self *SkipList) Keys() types.KIterator {
+	return types.MakeKeysIterator(self)
+}
@@ -0,0 +1,131 @@
+package skiplist
+
+import (
+	"testing"
+
+	"github.com/timtadh/data-structures/test"
+	"github.com/timtadh/data-structures/types"
+)
+
+func randstr(length int) types.String {
+	return types.String(test.RandStr(length))
+}
+
+func TestSkipListPutHasGetRemove(t *testing.T) {
+
+	type record struct {
+		key   types.String
+		value types.String
+	}
+
+	records := make([]*record, 400)
+	list := NewSkipList()
+
+	ranrec := func() *record {
+		return &record{randstr(20), randstr(20)}
+	}
+
+	for i := range records {
+		r := ranrec()
+		records[i] = r
+		if err := list.Put(r.key, types.String("")); err != nil {
+			t.Error(err)
+		}
+		if err := list.Put(r.key, r.value); err != nil {
+			t.Error(err)
+		}
+		if list.Size() != (i + 1) {
+			t.Error("size was wrong", list.Size(), i+1)
+		}
+	}
+
+	for _, r := range records {
+		if has := list.Has(r.key); !has {
+			t.Error("Missing key")
+		}
+		if has := list.Has(randstr(12)); has {
+			t.Error("Table has extra key")
+		}
+		if val, err := list.Get(r.key); err != nil {
+			t.Error(err)
+		} else if !(val.(types.String)).Equals(r.value) {
+			t.Error("wrong value")
+		}
+	}
+
+	for i, x := range records {
+		if val, err := list.Remove(x.key); err != nil {
+			t.Error(err)
+		} else if !(val.(types.String)).Equals(x.value) {
+			t.Error("wrong value")
+		}
+		if _, err := list.Remove(x.key); err == nil {
+			t.Error("removed a missing key")
+		}
+		if list.Size() != (len(records) - (i + 1)) {
+			t.Error("size was wrong", list.Size(), (len(records) - (i + 1)))
+		}
+	}
+	if list.level != 1 {
+		t.Error("empty list should have shrunk to one level", list.level)
+	}
+}
+
+func TestSkipListIterateRange(t *testing.T) {
+	list := NewSkipList()
+	for _, i := range rand.Perm(100) {
+		if err := list.Put(types.Int(i), i); err != nil {
+			t.Error(err)
+		}
+	}
+	j := 0
+	for k, v, next := list.Iterate()(); next != nil; k, v, next = next() {
+		if !k.Equals(types.Int(j)) || v.(int) != j {
+			t.Error("wrong entry", k, v, j)
+		}
+		j++
+	}
+	if j != 100 {
+		t.Error("wrong number of items", j)
+	}
+	j = 10
+	for k, _, next := list.Range(types.Int(10), types.Int(20))(); next != nil; k, _, next = next() {
+		if !k.Equals(types.Int(j)) {
+			t.Error("wrong key in range", k, j)
+		}
+		j++
+	}
+	if j != 21 {
+		t.Error("wrong range end", j)
+	}
+}
+
+func BenchmarkSkipList(b *testing.B) {
+	b.StopTimer()
+
+	type record struct {
+		key   types.String
+		value types.String
+	}
+
+	records := make([]*record, 100)
+
+	ranrec := func() *record {
+		return &record{randstr(20), randstr(20)}
+	}
+
+	for i := range records {
+		records[i] = ranrec()
+	}
+
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		t := NewSkipList()
+		for _, r := range records {
+			t.Put(r.key, r.value)
+		}
+		for _, r := range records {
+			t.Remove(r.key)
+		}
+	}
+}
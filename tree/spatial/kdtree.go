@@ -0,0 +1,329 @@
+package spatial
+
+import (
+	"sort"
+)
+
+import (
+	"github.com/timtadh/data-structures/errors"
+	"github.com/timtadh/data-structures/tree"
+	"github.com/timtadh/data-structures/types"
+)
+
+/* A KdTree is a binary search tree over k dimensional points which cycles
+ * through the dimensions as it descends: a node at depth d splits its subtree
+ * on dimension d mod k. Points less than the node on that dimension are on the
+ * left, the rest are on the right. The tree is not rebalanced so operations
+ * are O(log(n)) on average for points inserted in random order. Each point is
+ * stored once, putting it again replaces the value.
+ */
+type KdTree struct {
+	root *KdNode
+	dims int
+	size int
+}
+
+func NewKdTree(dims int) *KdTree {
+	return &KdTree{dims: dims}
+}
+
+func (self *KdTree) Root() types.TreeNode {
+	return self.root
+}
+
+func (self *KdTree) Size() int {
+	return self.size
+}
+
+func (self *KdTree) Dims() int {
+	return self.dims
+}
+
+func (self *KdTree) check(p Point) error {
+	if len(p) != self.dims {
+		return errors.Errorf("Point %v does not have %v dimensions", p, self.dims)
+	}
+	return nil
+}
+
+func (self *KdTree) Put(p Point, value interface{}) error {
+	if err := self.check(p); err != nil {
+		return err
+	}
+	var updated bool
+	self.root, updated = self.root.put(p, value, 0, self.dims)
+	if !updated {
+		self.size++
+	}
+	return nil
+}
+
+func (self *KdTree) Has(p Point) bool {
+	_, err := self.Get(p)
+	return err == nil
+}
+
+func (self *KdTree) Get(p Point) (value interface{}, err error) {
+	if err := self.check(p); err != nil {
+		return nil, err
+	}
+	n := self.root
+	for n != nil {
+		if n.point.Equals(p) {
+			return n.value, nil
+		} else if p[n.axis] < n.point[n.axis] {
+			n = n.left
+		} else {
+			n = n.right
+		}
+	}
+	return nil, errors.NotFound(p)
+}
+
+func (self *KdTree) Remove(p Point) (value interface{}, err error) {
+	if err := self.check(p); err != nil {
+		return nil, err
+	}
+	new_root, value, err := self.root.remove(p, self.dims)
+	if err != nil {
+		return nil, err
+	}
+	self.root = new_root
+	self.size--
+	return value, nil
+}
+
+// The closest point to p and its value.
+func (self *KdTree) Nearest(p Point) (nearest Point, value interface{}, err error) {
+	if err := self.check(p); err != nil {
+		return nil, nil, err
+	} else if self.root == nil {
+		return nil, nil, errors.Errorf("Nearest called on an empty KdTree")
+	}
+	near := &neighbors{k: 1}
+	self.root.nearest(p, near)
+	return near.nodes[0].point, near.nodes[0].value, nil
+}
+
+// Iterate over the k closest points to p, closest first. The keys are Point.
+func (self *KdTree) KNearest(p Point, k int) (kvi types.KVIterator) {
+	if self.check(p) != nil || k <= 0 {
+		return func() (types.Hashable, interface{}, types.KVIterator) {
+			return nil, nil, nil
+		}
+	}
+	near := &neighbors{k: k}
+	self.root.nearest(p, near)
+	i := 0
+	kvi = func() (key types.Hashable, value interface{}, next types.KVIterator) {
+		if i >= len(near.nodes) {
+			return nil, nil, nil
+		}
+		n := near.nodes[i]
+		i++
+		return n.point, n.value, kvi
+	}
+	return kvi
+}
+
+// Iterate over the points inside r (in no particular order).
+func (self *KdTree) Range(r Rect) (kvi types.KVIterator) {
+	stack := make([]*KdNode, 0, 10)
+	if self.root != nil && r.Dims() == self.dims {
+		stack = append(stack, self.root)
+	}
+	kvi = func() (key types.Hashable, value interface{}, next types.KVIterator) {
+		for len(stack) > 0 {
+			n := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if n.right != nil && r.Max[n.axis] >= n.point[n.axis] {
+				stack = append(stack, n.right)
+			}
+			if n.left != nil && r.Min[n.axis] < n.point[n.axis] {
+				stack = append(stack, n.left)
+			}
+			if r.Contains(n.point) {
+				return n.point, n.value, kvi
+			}
+		}
+		return nil, nil, nil
+	}
+	return kvi
+}
+
+func (self *KdTree) Iterate() types.KVIterator {
+	return types.MakeKVIteratorFromTreeNodeIterator(tree.TraverseBinaryTreeInOrder(self.root))
+}
+
+func (self *KdTree) Items() (vi types.KIterator) {
+	return types.MakeItemsIterator(self)
+}
+
+func (self *KdTree) Values() types.Iterator {
+	return types.MakeValuesIterator(self)
+}
+
+func (self *KdTree) Keys() types.KIterator {
+	return types.MakeKeysIterator(self)
+}
+
+// the k best nodes found so far, closest first
+type neighbors struct {
+	k     int
+	nodes []*KdNode
+	dists []float64
+}
+
+func (self *neighbors) full() bool {
+	return len(self.nodes) >= self.k
+}
+
+func (self *neighbors) worst() float64 {
+	return self.dists[len(self.dists)-1]
+}
+
+func (self *neighbors) add(n *KdNode, dist float64) {
+	if self.full() && dist >= self.worst() {
+		return
+	}
+	i := sort.SearchFloat64s(self.dists, dist)
+	if !self.full() {
+		self.nodes = append(self.nodes, nil)
+		self.dists = append(self.dists, 0)
+	}
+	copy(self.nodes[i+1:], self.nodes[i:])
+	copy(self.dists[i+1:], self.dists[i:])
+	self.nodes[i] = n
+	self.dists[i] = dist
+}
+
+type KdNode struct {
+	point Point
+	value interface{}
+	axis  int
+	left  *KdNode
+	right *KdNode
+}
+
+func (self *KdNode) put(p Point, value interface{}, axis, dims int) (_ *KdNode, updated bool) {
+	if self == nil {
+		return &KdNode{point: p, value: value, axis: axis}, false
+	}
+	if self.point.Equals(p) {
+		self.value = value
+		return self, true
+	} else if p[self.axis] < self.point[self.axis] {
+		self.left, updated = self.left.put(p, value, (self.axis+1)%dims, dims)
+	} else {
+		self.right, updated = self.right.put(p, value, (self.axis+1)%dims, dims)
+	}
+	return self, updated
+}
+
+// A removed node is replaced by the node with the smallest coordinate on its
+// axis from the right subtree. When there is no right subtree the left
+// subtree is moved to the right first.
+func (self *KdNode) remove(p Point, dims int) (_ *KdNode, value interface{}, err error) {
+	if self == nil {
+		return nil, nil, errors.NotFound(p)
+	}
+	if self.point.Equals(p) {
+		value = self.value
+		if self.right == nil && self.left == nil {
+			return nil, value, nil
+		} else if self.right == nil {
+			self.right = self.left
+			self.left = nil
+		}
+		min := self.right.min(self.axis)
+		point, min_value := min.point, min.value
+		self.right, _, err = self.right.remove(point, dims)
+		if err != nil {
+			return nil, nil, err
+		}
+		self.point = point
+		self.value = min_value
+		return self, value, nil
+	} else if p[self.axis] < self.point[self.axis] {
+		self.left, value, err = self.left.remove(p, dims)
+	} else {
+		self.right, value, err = self.right.remove(p, dims)
+	}
+	return self, value, err
+}
+
+// the node in this subtree with the smallest coordinate on axis
+func (self *KdNode) min(axis int) *KdNode {
+	if self == nil {
+		return nil
+	}
+	best := self.left.min(axis)
+	if self.axis != axis {
+		if r := self.right.min(axis); r != nil && (best == nil || r.point[axis] < best.point[axis]) {
+			best = r
+		}
+	}
+	if best == nil || self.point[axis] <= best.point[axis] {
+		best = self
+	}
+	return best
+}
+
+func (self *KdNode) nearest(p Point, near *neighbors) {
+	if self == nil {
+		return
+	}
+	near.add(self, self.point.Distance2(p))
+	diff := p[self.axis] - self.point[self.axis]
+	first, second := self.left, self.right
+	if diff >= 0 {
+		first, second = self.right, self.left
+	}
+	first.nearest(p, near)
+	if !near.full() || diff*diff < near.worst() {
+		second.nearest(p, near)
+	}
+}
+
+func (self *KdNode) Point() Point {
+	return self.point
+}
+
+// The dimension this node splits its subtree on.
+func (self *KdNode) Axis() int {
+	return self.axis
+}
+
+func (self *KdNode) Key() types.Hashable {
+	return self.point
+}
+
+func (self *KdNode) Value() interface{} {
+	return self.value
+}
+
+func (self *KdNode) Left() types.BinaryTreeNode {
+	if self.left == nil {
+		return nil
+	}
+	return self.left
+}
+
+func (self *KdNode) Right() types.BinaryTreeNode {
+	if self.right == nil {
+		return nil
+	}
+	return self.right
+}
+
+func (self *KdNode) GetChild(i int) types.TreeNode {
+	return types.DoGetChild(self, i)
+}
+
+func (self *KdNode) ChildCount() int {
+	return types.DoChildCount(self)
+}
+
+func (self *KdNode) Children() types.TreeNodeIterator {
+	return types.MakeChildrenIterator(self)
+}
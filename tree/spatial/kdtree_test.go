@@ -0,0 +1,179 @@
+package spatial
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	mrand "math/rand"
+	"sort"
+	"testing"
+
+	trand "github.com/timtadh/data-structures/rand"
+	"github.com/timtadh/data-structures/tree"
+	"github.com/timtadh/data-structures/types"
+)
+
+var rand *mrand.Rand
+
+func init() {
+	seed := make([]byte, 8)
+	if _, err := crand.Read(seed); err == nil {
+		rand = trand.ThreadSafeRand(int64(binary.BigEndian.Uint64(seed)))
+	} else {
+		panic(err)
+	}
+}
+
+func randPoint(dims int) Point {
+	p := make(Point, dims)
+	for i := range p {
+		p[i] = float64(rand.Intn(100))
+	}
+	return p
+}
+
+func checkKd(t *testing.T, n *KdNode, dims int) {
+	if n == nil {
+		return
+	}
+	for l, next := tree.TraverseBinaryTreeInOrder(n.left)(); next != nil; l, next = next() {
+		if !(l.Key().(Point)[n.axis] < n.point[n.axis]) {
+			t.Fatal("bad left descendant", l.Key(), n.point)
+		}
+	}
+	for r, next := tree.TraverseBinaryTreeInOrder(n.right)(); next != nil; r, next = next() {
+		if r.Key().(Point)[n.axis] < n.point[n.axis] {
+			t.Fatal("bad right descendant", r.Key(), n.point)
+		}
+	}
+	for _, c := range []*KdNode{n.left, n.right} {
+		if c != nil && c.axis != (n.axis+1)%dims {
+			t.Fatal("bad axis", c.point)
+		}
+	}
+	checkKd(t, n.left, dims)
+	checkKd(t, n.right, dims)
+}
+
+func TestKdTreePutGetRemove(t *testing.T) {
+	kd := NewKdTree(2)
+	points := make(map[string]Point)
+	for i := 0; i < 500; i++ {
+		p := randPoint(2)
+		points[p.String()] = p
+		if err := kd.Put(p, p.String()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := kd.Put(Point{1, 2, 3}, nil); err == nil {
+		t.Error("put a point with the wrong dimensions")
+	}
+	checkKd(t, kd.root, 2)
+	if kd.Size() != len(points) {
+		t.Fatal("wrong size", kd.Size(), len(points))
+	}
+	for s, p := range points {
+		if v, err := kd.Get(p); err != nil || v.(string) != s {
+			t.Fatal("wrong value", p, v, err)
+		}
+	}
+	i := 0
+	for s, p := range points {
+		if i%2 == 0 {
+			if v, err := kd.Remove(p); err != nil || v.(string) != s {
+				t.Fatal("wrong value removed", p, v, err)
+			}
+			if kd.Has(p) {
+				t.Fatal("point was not removed", p)
+			}
+			delete(points, s)
+		}
+		i++
+	}
+	checkKd(t, kd.root, 2)
+	count := 0
+	for tn, next := tree.TraverseTreePreOrder(kd.Root())(); next != nil; tn, next = next() {
+		if _, has := points[tn.Key().(Point).String()]; !has {
+			t.Fatal("unexpected point", tn.Key())
+		}
+		count++
+	}
+	if count != len(points) || kd.Size() != len(points) {
+		t.Fatal("wrong size", count, kd.Size(), len(points))
+	}
+}
+
+func TestKdTreeNearest(t *testing.T) {
+	kd := NewKdTree(3)
+	if _, _, err := kd.Nearest(Point{0, 0, 0}); err == nil {
+		t.Error("expected an error from an empty tree")
+	}
+	points := make([]Point, 0, 300)
+	for i := 0; i < 300; i++ {
+		p := randPoint(3)
+		if !kd.Has(p) {
+			points = append(points, p)
+		}
+		kd.Put(p, nil)
+	}
+	for i := 0; i < 50; i++ {
+		q := randPoint(3)
+		sort.Slice(points, func(a, b int) bool {
+			return points[a].Distance2(q) < points[b].Distance2(q)
+		})
+		near, _, err := kd.Nearest(q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if near.Distance2(q) != points[0].Distance2(q) {
+			t.Fatal("wrong nearest point", q, near, points[0])
+		}
+		j := 0
+		for k, _, next := kd.KNearest(q, 10)(); next != nil; k, _, next = next() {
+			if k.(Point).Distance2(q) != points[j].Distance2(q) {
+				t.Fatal("wrong neighbor", j, k, points[j])
+			}
+			j++
+		}
+		if j != 10 {
+			t.Fatal("wrong number of neighbors", j)
+		}
+	}
+}
+
+func TestKdTreeRange(t *testing.T) {
+	kd := NewKdTree(2)
+	for i := 0; i < 300; i++ {
+		kd.Put(randPoint(2), nil)
+	}
+	for i := 0; i < 50; i++ {
+		r, _ := NewRect(randPoint(2), randPoint(2))
+		expected := 0
+		for k, next := kd.Keys()(); next != nil; k, next = next() {
+			if r.Contains(k.(Point)) {
+				expected++
+			}
+		}
+		found := 0
+		for k, _, next := kd.Range(r)(); next != nil; k, _, next = next() {
+			if !r.Contains(k.(Point)) {
+				t.Fatal("point outside of range", k, r)
+			}
+			found++
+		}
+		if found != expected {
+			t.Fatal("wrong number of points in range", found, expected)
+		}
+	}
+}
+
+func TestPointHashable(t *testing.T) {
+	var _ types.Hashable = Point{}
+	var _ types.Hashable = Rect{}
+	a, b := Point{1, 2}, Point{1, 2}
+	if !a.Equals(b) || a.Hash() != b.Hash() || a.Less(b) {
+		t.Error("equal points should be equal")
+	}
+	if !a.Less(Point{1, 3}) || (Point{2, 0}).Less(a) {
+		t.Error("points should be ordered lexicographically")
+	}
+}
@@ -0,0 +1,173 @@
+package spatial
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+)
+
+import (
+	"github.com/timtadh/data-structures/errors"
+	"github.com/timtadh/data-structures/types"
+)
+
+// A point in k dimensional space. Points are ordered lexicographically.
+type Point []float64
+
+func (self Point) Dims() int {
+	return len(self)
+}
+
+func (self Point) Equals(other types.Equatable) bool {
+	if o, ok := other.(Point); ok {
+		if len(self) != len(o) {
+			return false
+		}
+		for i := range self {
+			if self[i] != o[i] {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func (self Point) Less(other types.Sortable) bool {
+	if o, ok := other.(Point); ok {
+		for i := 0; i < len(self) && i < len(o); i++ {
+			if self[i] != o[i] {
+				return self[i] < o[i]
+			}
+		}
+		return len(self) < len(o)
+	}
+	return false
+}
+
+func (self Point) Hash() int {
+	h := fnv.New32a()
+	b := make([]byte, 8)
+	for _, x := range self {
+		bits := math.Float64bits(x)
+		for i := range b {
+			b[i] = byte(bits >> (8 * uint(i)))
+		}
+		h.Write(b)
+	}
+	return int(h.Sum32())
+}
+
+// The squared euclidean distance between the points.
+func (self Point) Distance2(other Point) float64 {
+	var d float64
+	for i := range self {
+		x := self[i] - other[i]
+		d += x * x
+	}
+	return d
+}
+
+func (self Point) String() string {
+	parts := make([]string, 0, len(self))
+	for _, x := range self {
+		parts = append(parts, fmt.Sprint(x))
+	}
+	return "(" + strings.Join(parts, ", ") + ")"
+}
+
+// An axis aligned bounding box. Min and Max are inclusive.
+type Rect struct {
+	Min, Max Point
+}
+
+// Make a rectangle from two opposite corners.
+func NewRect(a, b Point) (Rect, error) {
+	if len(a) != len(b) {
+		return Rect{}, errors.Errorf("Corners have different dimensions, %v and %v", len(a), len(b))
+	}
+	min := make(Point, len(a))
+	max := make(Point, len(a))
+	for i := range a {
+		min[i] = math.Min(a[i], b[i])
+		max[i] = math.Max(a[i], b[i])
+	}
+	return Rect{min, max}, nil
+}
+
+// The degenerate rectangle containing only p.
+func PointRect(p Point) Rect {
+	return Rect{p, p}
+}
+
+func (self Rect) Dims() int {
+	return len(self.Min)
+}
+
+func (self Rect) Equals(other types.Equatable) bool {
+	if o, ok := other.(Rect); ok {
+		return self.Min.Equals(o.Min) && self.Max.Equals(o.Max)
+	}
+	return false
+}
+
+func (self Rect) Less(other types.Sortable) bool {
+	if o, ok := other.(Rect); ok {
+		if self.Min.Equals(o.Min) {
+			return self.Max.Less(o.Max)
+		}
+		return self.Min.Less(o.Min)
+	}
+	return false
+}
+
+func (self Rect) Hash() int {
+	return self.Min.Hash()*31 + self.Max.Hash()
+}
+
+func (self Rect) Contains(p Point) bool {
+	for i := range p {
+		if p[i] < self.Min[i] || p[i] > self.Max[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Is other entirely inside this rectangle?
+func (self Rect) Covers(other Rect) bool {
+	return self.Contains(other.Min) && self.Contains(other.Max)
+}
+
+func (self Rect) Intersects(other Rect) bool {
+	for i := range self.Min {
+		if other.Max[i] < self.Min[i] || other.Min[i] > self.Max[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// The smallest rectangle containing both rectangles.
+func (self Rect) Union(other Rect) Rect {
+	min := make(Point, len(self.Min))
+	max := make(Point, len(self.Max))
+	for i := range min {
+		min[i] = math.Min(self.Min[i], other.Min[i])
+		max[i] = math.Max(self.Max[i], other.Max[i])
+	}
+	return Rect{min, max}
+}
+
+func (self Rect) Area() float64 {
+	area := 1.0
+	for i := range self.Min {
+		area *= self.Max[i] - self.Min[i]
+	}
+	return area
+}
+
+func (self Rect) String() string {
+	return fmt.Sprintf("[%v, %v]", self.Min, self.Max)
+}
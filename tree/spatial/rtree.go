@@ -0,0 +1,355 @@
+package spatial
+
+import (
+	"math"
+)
+
+import (
+	"github.com/timtadh/data-structures/errors"
+	"github.com/timtadh/data-structures/types"
+)
+
+/* An RTree indexes rectangles by grouping nearby rectangles under a shared
+ * bounding box. Every node except the root holds between min and max
+ * children. An entry is added to the leaf whose bounding box needs the least
+ * enlargement and full nodes are divided with Guttman's quadratic split.
+ * When a deletion leaves a node under filled the node is dissolved and its
+ * entries are inserted again.
+ *
+ * The entries are the leaves of the types.TreeNode view of the tree: they
+ * are the nodes with a Height of 0 and they carry the values. The same
+ * rectangle may be inserted any number of times.
+ */
+type RTree struct {
+	root *RNode
+	dims int
+	min  int
+	max  int
+	size int
+}
+
+// A tree over rectangles with dims dimensions where each node has at most
+// node_size children. A node_size less than 4 is raised to 4.
+func NewRTree(dims, node_size int) *RTree {
+	if node_size < 4 {
+		node_size = 4
+	}
+	return &RTree{
+		dims: dims,
+		min:  (node_size * 2) / 5,
+		max:  node_size,
+	}
+}
+
+func (self *RTree) Root() types.TreeNode {
+	return self.root
+}
+
+func (self *RTree) Size() int {
+	return self.size
+}
+
+func (self *RTree) Dims() int {
+	return self.dims
+}
+
+func (self *RTree) check(r Rect) error {
+	if len(r.Min) != self.dims || len(r.Max) != self.dims {
+		return errors.Errorf("Rect %v does not have %v dimensions", r, self.dims)
+	}
+	for i := range r.Min {
+		if r.Max[i] < r.Min[i] {
+			return errors.Errorf("Invalid Rect %v, Max < Min", r)
+		}
+	}
+	return nil
+}
+
+func (self *RTree) Insert(r Rect, value interface{}) error {
+	if err := self.check(r); err != nil {
+		return err
+	}
+	self.insert(&RNode{rect: r, value: value})
+	self.size++
+	return nil
+}
+
+func (self *RTree) insert(entry *RNode) {
+	if self.root == nil {
+		self.root = &RNode{rect: entry.rect, height: 1}
+	}
+	if sibling := self.root.insert(entry, self.min, self.max); sibling != nil {
+		root := &RNode{height: self.root.height + 1, children: []*RNode{self.root, sibling}}
+		root.fix()
+		self.root = root
+	}
+}
+
+// Delete the entries with the rectangle r whose values match where. It is an
+// error if nothing was deleted.
+func (self *RTree) Delete(r Rect, where types.WhereFunc) error {
+	if err := self.check(r); err != nil {
+		return err
+	}
+	if self.root == nil || !self.root.rect.Covers(r) {
+		return errors.NotFound(r)
+	}
+	orphans := make([]*RNode, 0, 10)
+	removed := self.root.remove(r, where, self.min, &orphans)
+	if removed == 0 {
+		return errors.NotFound(r)
+	}
+	self.size -= removed
+	for self.root != nil && self.root.height > 1 && len(self.root.children) == 1 {
+		self.root = self.root.children[0]
+	}
+	if self.root != nil && len(self.root.children) == 0 {
+		self.root = nil
+	}
+	for _, entry := range orphans {
+		self.insert(entry)
+	}
+	return nil
+}
+
+// Iterate over the entries whose rectangles intersect r. The keys are Rect.
+func (self *RTree) Search(r Rect) (kvi types.KVIterator) {
+	return self.search(func(n *RNode) bool { return n.rect.Intersects(r) })
+}
+
+// Iterate over the entries whose rectangles contain p.
+func (self *RTree) SearchPoint(p Point) types.KVIterator {
+	return self.Search(PointRect(p))
+}
+
+func (self *RTree) Iterate() types.KVIterator {
+	return self.search(func(n *RNode) bool { return true })
+}
+
+func (self *RTree) Items() (vi types.KIterator) {
+	return types.MakeItemsIterator(self)
+}
+
+func (self *RTree) Values() types.Iterator {
+	return types.MakeValuesIterator(self)
+}
+
+func (self *RTree) Keys() types.KIterator {
+	return types.MakeKeysIterator(self)
+}
+
+func (self *RTree) search(match func(*RNode) bool) (kvi types.KVIterator) {
+	stack := make([]*RNode, 0, 10)
+	if self.root != nil && match(self.root) {
+		stack = append(stack, self.root)
+	}
+	kvi = func() (key types.Hashable, value interface{}, next types.KVIterator) {
+		for len(stack) > 0 {
+			n := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if n.height == 0 {
+				return n.rect, n.value, kvi
+			}
+			for _, c := range n.children {
+				if match(c) {
+					stack = append(stack, c)
+				}
+			}
+		}
+		return nil, nil, nil
+	}
+	return kvi
+}
+
+type RNode struct {
+	rect     Rect
+	value    interface{}
+	height   int
+	children []*RNode
+}
+
+// recompute the bounding box from the children
+func (self *RNode) fix() {
+	self.rect = self.children[0].rect
+	for _, c := range self.children[1:] {
+		self.rect = self.rect.Union(c.rect)
+	}
+}
+
+func enlargement(r, add Rect) float64 {
+	return r.Union(add).Area() - r.Area()
+}
+
+// the child which needs the least enlargement to cover r, ties go to the
+// smallest child
+func (self *RNode) choose(r Rect) *RNode {
+	var best *RNode
+	bestEnlarge, bestArea := math.Inf(1), math.Inf(1)
+	for _, c := range self.children {
+		e, a := enlargement(c.rect, r), c.rect.Area()
+		if e < bestEnlarge || (e == bestEnlarge && a < bestArea) {
+			best, bestEnlarge, bestArea = c, e, a
+		}
+	}
+	return best
+}
+
+// add an entry to the subtree. If this node overflows it is split and the new
+// sibling is returned.
+func (self *RNode) insert(entry *RNode, min, max int) (sibling *RNode) {
+	if self.height == 1 {
+		self.children = append(self.children, entry)
+	} else if s := self.choose(entry.rect).insert(entry, min, max); s != nil {
+		self.children = append(self.children, s)
+	}
+	if len(self.children) > max {
+		return self.split(min)
+	}
+	self.fix()
+	return nil
+}
+
+// Guttman's quadratic split. This node keeps one group and the other group is
+// returned as a new sibling.
+func (self *RNode) split(min int) *RNode {
+	children := self.children
+	var s1, s2 int
+	worst := math.Inf(-1)
+	for i := 0; i < len(children); i++ {
+		for j := i + 1; j < len(children); j++ {
+			a, b := children[i].rect, children[j].rect
+			d := a.Union(b).Area() - a.Area() - b.Area()
+			if d > worst {
+				s1, s2, worst = i, j, d
+			}
+		}
+	}
+	g1 := &RNode{height: self.height, children: []*RNode{children[s1]}, rect: children[s1].rect}
+	g2 := &RNode{height: self.height, children: []*RNode{children[s2]}, rect: children[s2].rect}
+	rest := make([]*RNode, 0, len(children)-2)
+	for i, c := range children {
+		if i != s1 && i != s2 {
+			rest = append(rest, c)
+		}
+	}
+	add := func(g *RNode, c *RNode) {
+		g.children = append(g.children, c)
+		g.rect = g.rect.Union(c.rect)
+	}
+	for len(rest) > 0 {
+		if len(g1.children)+len(rest) <= min {
+			for _, c := range rest {
+				add(g1, c)
+			}
+			break
+		} else if len(g2.children)+len(rest) <= min {
+			for _, c := range rest {
+				add(g2, c)
+			}
+			break
+		}
+		// pick the entry with the strongest preference for one group
+		next, diff := 0, math.Inf(-1)
+		for i, c := range rest {
+			d := math.Abs(enlargement(g1.rect, c.rect) - enlargement(g2.rect, c.rect))
+			if d > diff {
+				next, diff = i, d
+			}
+		}
+		c := rest[next]
+		rest = append(rest[:next], rest[next+1:]...)
+		e1, e2 := enlargement(g1.rect, c.rect), enlargement(g2.rect, c.rect)
+		a1, a2 := g1.rect.Area(), g2.rect.Area()
+		if e1 < e2 || (e1 == e2 && (a1 < a2 || (a1 == a2 && len(g1.children) <= len(g2.children)))) {
+			add(g1, c)
+		} else {
+			add(g2, c)
+		}
+	}
+	self.children = g1.children
+	self.rect = g1.rect
+	return g2
+}
+
+// remove the matching entries under this node. Children which are left with
+// fewer than min children are dissolved and their entries are added to
+// orphans.
+func (self *RNode) remove(r Rect, where types.WhereFunc, min int, orphans *[]*RNode) (removed int) {
+	kept := self.children[:0]
+	for _, c := range self.children {
+		if self.height == 1 {
+			if c.rect.Equals(r) && where(c.value) {
+				removed++
+				continue
+			}
+		} else if c.rect.Covers(r) {
+			if n := c.remove(r, where, min, orphans); n > 0 {
+				removed += n
+				if len(c.children) < min {
+					*orphans = c.entries(*orphans)
+					continue
+				}
+			}
+		}
+		kept = append(kept, c)
+	}
+	for i := len(kept); i < len(self.children); i++ {
+		self.children[i] = nil
+	}
+	self.children = kept
+	if len(self.children) > 0 {
+		self.fix()
+	}
+	return removed
+}
+
+// append the entries under this node to entries
+func (self *RNode) entries(entries []*RNode) []*RNode {
+	if self.height == 1 {
+		return append(entries, self.children...)
+	}
+	for _, c := range self.children {
+		entries = c.entries(entries)
+	}
+	return entries
+}
+
+// Entries have a height of 0 and leaves have a height of 1.
+func (self *RNode) Height() int {
+	return self.height
+}
+
+// The bounding box of this node or the rectangle of an entry.
+func (self *RNode) Rect() Rect {
+	return self.rect
+}
+
+func (self *RNode) Key() types.Hashable {
+	return self.rect
+}
+
+// The value of an entry, nil for the other nodes.
+func (self *RNode) Value() interface{} {
+	return self.value
+}
+
+func (self *RNode) Children() (it types.TreeNodeIterator) {
+	i := 0
+	it = func() (types.TreeNode, types.TreeNodeIterator) {
+		if i >= len(self.children) {
+			return nil, nil
+		}
+		c := self.children[i]
+		i++
+		return c, it
+	}
+	return it
+}
+
+func (self *RNode) GetChild(i int) types.TreeNode {
+	return self.children[i]
+}
+
+func (self *RNode) ChildCount() int {
+	return len(self.children)
+}
@@ -0,0 +1,126 @@
+package spatial
+
+import (
+	"testing"
+
+	"github.com/timtadh/data-structures/tree"
+)
+
+// checks the fill, bounding boxes and heights and returns the number of
+// entries under n
+func checkR(t *testing.T, rt *RTree, n *RNode) int {
+	if n.height == 0 {
+		return 1
+	}
+	if n != rt.root && (len(n.children) < rt.min || len(n.children) > rt.max) {
+		t.Fatal("bad fill", len(n.children), rt.min, rt.max)
+	}
+	count := 0
+	bound := n.children[0].rect
+	for _, c := range n.children {
+		if c.height != n.height-1 {
+			t.Fatal("bad height", c.height, n.height)
+		}
+		bound = bound.Union(c.rect)
+		count += checkR(t, rt, c)
+	}
+	if !bound.Equals(n.rect) {
+		t.Fatal("bad bounding box", n.rect, bound)
+	}
+	return count
+}
+
+func randRect(dims int) Rect {
+	a := randPoint(dims)
+	b := make(Point, dims)
+	for i := range b {
+		b[i] = a[i] + float64(rand.Intn(10))
+	}
+	r, _ := NewRect(a, b)
+	return r
+}
+
+func TestRTreeInsertSearchDelete(t *testing.T) {
+	type entry struct {
+		r Rect
+		v int
+	}
+	rt := NewRTree(2, 6)
+	entries := make([]entry, 0, 1000)
+	for i := 0; i < 1000; i++ {
+		e := entry{randRect(2), i}
+		entries = append(entries, e)
+		if err := rt.Insert(e.r, e.v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := rt.Insert(Rect{Point{0}, Point{1}}, nil); err == nil {
+		t.Error("inserted a rect with the wrong dimensions")
+	}
+	if checkR(t, rt, rt.root) != len(entries) || rt.Size() != len(entries) {
+		t.Fatal("wrong size", rt.Size())
+	}
+
+	search := func() {
+		for i := 0; i < 50; i++ {
+			q := randRect(2)
+			expected := make(map[int]bool)
+			for _, e := range entries {
+				if e.r.Intersects(q) {
+					expected[e.v] = true
+				}
+			}
+			found := 0
+			for k, v, next := rt.Search(q)(); next != nil; k, v, next = next() {
+				if !expected[v.(int)] || !k.(Rect).Intersects(q) {
+					t.Fatal("unexpected entry", k, v, q)
+				}
+				found++
+			}
+			if found != len(expected) {
+				t.Fatal("wrong number of entries found", found, len(expected))
+			}
+		}
+	}
+	search()
+
+	for i := 0; i < 700; i++ {
+		j := rand.Intn(len(entries))
+		e := entries[j]
+		err := rt.Delete(e.r, func(v interface{}) bool { return v.(int) == e.v })
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries[:j], entries[j+1:]...)
+		if i%50 == 0 && rt.root != nil {
+			if checkR(t, rt, rt.root) != len(entries) {
+				t.Fatal("wrong number of entries")
+			}
+		}
+	}
+	if err := rt.Delete(Rect{Point{-5, -5}, Point{-4, -4}}, func(interface{}) bool { return true }); err == nil {
+		t.Error("deleted a missing rect")
+	}
+	if rt.Size() != len(entries) {
+		t.Fatal("wrong size", rt.Size(), len(entries))
+	}
+	search()
+
+	count := 0
+	for tn, next := tree.TraverseTreePreOrder(rt.Root())(); next != nil; tn, next = next() {
+		if tn.(*RNode).Height() == 0 {
+			count++
+		}
+	}
+	if count != len(entries) {
+		t.Fatal("wrong number of entries in traversal", count, len(entries))
+	}
+
+	for _, e := range entries {
+		// deletes every entry with the same rect so later deletes may fail
+		rt.Delete(e.r, func(interface{}) bool { return true })
+	}
+	if rt.Size() != 0 || rt.root != nil {
+		t.Fatal("tree should be empty", rt.Size())
+	}
+}
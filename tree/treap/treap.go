@@ -0,0 +1,293 @@
+package treap
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	mrand "math/rand"
+)
+
+import (
+	"github.com/timtadh/data-structures/errors"
+	trand "github.com/timtadh/data-structures/rand"
+	"github.com/timtadh/data-structures/tree"
+	"github.com/timtadh/data-structures/types"
+)
+
+var rand *mrand.Rand
+
+func init() {
+	seed := make([]byte, 8)
+	if _, err := crand.Read(seed); err == nil {
+		rand = trand.ThreadSafeRand(int64(binary.BigEndian.Uint64(seed)))
+	} else {
+		panic(err)
+	}
+}
+
+/* A Treap is a binary search tree on the keys and a heap on randomly assigned
+ * priorities. The random priorities keep the tree balanced in expectation so
+ * all operations are expected O(log(n)). Treaps support splitting at a key
+ * and joining two trees with disjoint key ranges in expected O(log(n)).
+ */
+type Treap struct {
+	root *TreapNode
+}
+
+func NewTreap() *Treap {
+	return &Treap{}
+}
+
+func (self *Treap) Root() types.TreeNode {
+	return self.root
+}
+
+func (self *Treap) Size() int {
+	return self.root.Size()
+}
+
+func (self *Treap) Has(key types.Hashable) bool {
+	return self.root.Has(key)
+}
+
+func (self *Treap) Put(key types.Hashable, value interface{}) (err error) {
+	self.root = self.root.put(key, value, rand.Int())
+	return nil
+}
+
+func (self *Treap) Get(key types.Hashable) (value interface{}, err error) {
+	return self.root.Get(key)
+}
+
+func (self *Treap) Remove(key types.Hashable) (value interface{}, err error) {
+	new_root, value, err := self.root.remove(key)
+	if err != nil {
+		return nil, err
+	}
+	self.root = new_root
+	return value, nil
+}
+
+// Split the treap into two treaps. The left one contains all of the keys less
+// than the given key and the right one contains the rest. The treap that was
+// split is left empty.
+func (self *Treap) Split(key types.Hashable) (left, right *Treap) {
+	l, r := self.root.split(key)
+	self.root = nil
+	return &Treap{root: l}, &Treap{root: r}
+}
+
+// Join two treaps where every key in left is less than every key in right. The
+// two treaps are consumed (left empty) by the join.
+func Join(left, right *Treap) (*Treap, error) {
+	if left.root != nil && right.root != nil {
+		if !left.root.rmd().key.Less(right.root.lmd().key) {
+			return nil, errors.Errorf("Join requires every key in left to be less than every key in right")
+		}
+	}
+	root := join(left.root, right.root)
+	left.root = nil
+	right.root = nil
+	return &Treap{root: root}, nil
+}
+
+func (self *Treap) Iterate() types.KVIterator {
+	return self.root.Iterate()
+}
+
+func (self *Treap) Items() (vi types.KIterator) {
+	return types.MakeItemsIterator(self)
+}
+
+func (self *Treap) Values() types.Iterator {
+	return self.root.Values()
+}
+
+func (self *Treap) Keys() types.KIterator {
+	return self.root.Keys()
+}
+
+type TreapNode struct {
+	key      types.Hashable
+	value    interface{}
+	priority int
+	size     int
+	left     *TreapNode
+	right    *TreapNode
+}
+
+func (self *TreapNode) fix_size() *TreapNode {
+	self.size = 1 + self.left.Size() + self.right.Size()
+	return self
+}
+
+func (self *TreapNode) Has(key types.Hashable) (has bool) {
+	_, err := self.Get(key)
+	return err == nil
+}
+
+func (self *TreapNode) Get(key types.Hashable) (value interface{}, err error) {
+	for self != nil {
+		if self.key.Equals(key) {
+			return self.value, nil
+		} else if key.Less(self.key) {
+			self = self.left
+		} else {
+			self = self.right
+		}
+	}
+	return nil, errors.NotFound(key)
+}
+
+func (self *TreapNode) rotate_right() *TreapNode {
+	x := self.left
+	self.left = x.right
+	x.right = self.fix_size()
+	return x.fix_size()
+}
+
+func (self *TreapNode) rotate_left() *TreapNode {
+	x := self.right
+	self.right = x.left
+	x.left = self.fix_size()
+	return x.fix_size()
+}
+
+func (self *TreapNode) put(key types.Hashable, value interface{}, priority int) *TreapNode {
+	if self == nil {
+		return &TreapNode{key: key, value: value, priority: priority, size: 1}
+	}
+	if self.key.Equals(key) {
+		self.value = value
+		return self
+	} else if key.Less(self.key) {
+		self.left = self.left.put(key, value, priority)
+		if self.left.priority > self.priority {
+			return self.rotate_right()
+		}
+	} else {
+		self.right = self.right.put(key, value, priority)
+		if self.right.priority > self.priority {
+			return self.rotate_left()
+		}
+	}
+	return self.fix_size()
+}
+
+func (self *TreapNode) remove(key types.Hashable) (_ *TreapNode, value interface{}, err error) {
+	if self == nil {
+		return nil, nil, errors.NotFound(key)
+	}
+	if self.key.Equals(key) {
+		return join(self.left, self.right), self.value, nil
+	} else if key.Less(self.key) {
+		self.left, value, err = self.left.remove(key)
+	} else {
+		self.right, value, err = self.right.remove(key)
+	}
+	if err != nil {
+		return self, nil, err
+	}
+	return self.fix_size(), value, nil
+}
+
+// left gets the keys < key, right gets the keys >= key
+func (self *TreapNode) split(key types.Hashable) (left, right *TreapNode) {
+	if self == nil {
+		return nil, nil
+	}
+	if self.key.Less(key) {
+		self.right, right = self.right.split(key)
+		return self.fix_size(), right
+	} else {
+		left, self.left = self.left.split(key)
+		return left, self.fix_size()
+	}
+}
+
+// every key in left must be less than every key in right
+func join(left, right *TreapNode) *TreapNode {
+	if left == nil {
+		return right
+	} else if right == nil {
+		return left
+	}
+	if left.priority > right.priority {
+		left.right = join(left.right, right)
+		return left.fix_size()
+	} else {
+		right.left = join(left, right.left)
+		return right.fix_size()
+	}
+}
+
+func (self *TreapNode) lmd() *TreapNode {
+	for self.left != nil {
+		self = self.left
+	}
+	return self
+}
+
+func (self *TreapNode) rmd() *TreapNode {
+	for self.right != nil {
+		self = self.right
+	}
+	return self
+}
+
+func (self *TreapNode) Size() int {
+	if self == nil {
+		return 0
+	}
+	return self.size
+}
+
+func (self *TreapNode) Priority() int {
+	return self.priority
+}
+
+func (self *TreapNode) Key() types.Hashable {
+	return self.key
+}
+
+func (self *TreapNode) Value() interface{} {
+	return self.value
+}
+
+func (self *TreapNode) Left() types.BinaryTreeNode {
+	if self.left == nil {
+		return nil
+	}
+	return self.left
+}
+
+func (self *TreapNode) Right() types.BinaryTreeNode {
+	if self.right == nil {
+		return nil
+	}
+	return self.right
+}
+
+func (self *TreapNode) GetChild(i int) types.TreeNode {
+	return types.DoGetChild(self, i)
+}
+
+func (self *TreapNode) ChildCount() int {
+	return types.DoChildCount(self)
+}
+
+func (self *TreapNode) Children() types.TreeNodeIterator {
+	return types.MakeChildrenIterator(self)
+}
+
+func (self *TreapNode) Iterate() types.KVIterator {
+	tni := tree.TraverseBinaryTreeInOrder(self)
+	return types.MakeKVIteratorFromTreeNodeIterator(tni)
+}
+
+func (self *TreapNode) Keys() types.KIterator {
+	return types.MakeKeysIterator(self)
+}
+
+func (self *TreapNode) Values() types.Iterator {
+	return types.MakeValuesIterator(self)
+}
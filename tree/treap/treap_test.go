@@ -0,0 +1,164 @@
+package treap
+
+import (
+	"testing"
+
+	"github.com/timtadh/data-structures/test"
+	"github.com/timtadh/data-structures/types"
+)
+
+func randstr(length int) types.String {
+	return types.String(test.RandStr(length))
+}
+
+func check(t *testing.T, n *TreapNode) {
+	if n == nil {
+		return
+	}
+	if n.left != nil && (n.left.priority > n.priority || !n.left.key.Less(n.key)) {
+		t.Fatal("bad left child at", n.key)
+	}
+	if n.right != nil && (n.right.priority > n.priority || !n.key.Less(n.right.key)) {
+		t.Fatal("bad right child at", n.key)
+	}
+	if n.size != 1+n.left.Size()+n.right.Size() {
+		t.Fatal("bad size at", n.key)
+	}
+	check(t, n.left)
+	check(t, n.right)
+}
+
+func TestTreapPutHasGetRemove(t *testing.T) {
+
+	type record struct {
+		key   types.String
+		value types.String
+	}
+
+	records := make([]*record, 400)
+	tree := NewTreap()
+
+	ranrec := func() *record {
+		return &record{randstr(20), randstr(20)}
+	}
+
+	for i := range records {
+		r := ranrec()
+		records[i] = r
+		if err := tree.Put(r.key, types.String("")); err != nil {
+			t.Error(err)
+		}
+		if err := tree.Put(r.key, r.value); err != nil {
+			t.Error(err)
+		}
+		if tree.Size() != (i + 1) {
+			t.Error("size was wrong", tree.Size(), i+1)
+		}
+	}
+	check(t, tree.root)
+
+	for _, r := range records {
+		if has := tree.Has(r.key); !has {
+			t.Error("Missing key")
+		}
+		if has := tree.Has(randstr(12)); has {
+			t.Error("Table has extra key")
+		}
+		if val, err := tree.Get(r.key); err != nil {
+			t.Error(err)
+		} else if !(val.(types.String)).Equals(r.value) {
+			t.Error("wrong value")
+		}
+	}
+
+	for i, x := range records {
+		if val, err := tree.Remove(x.key); err != nil {
+			t.Error(err)
+		} else if !(val.(types.String)).Equals(x.value) {
+			t.Error("wrong value")
+		}
+		if _, err := tree.Remove(x.key); err == nil {
+			t.Error("removed a missing key")
+		}
+		if tree.Size() != (len(records) - (i + 1)) {
+			t.Error("size was wrong", tree.Size(), (len(records) - (i + 1)))
+		}
+	}
+	check(t, tree.root)
+}
+
+func TestTreapSplitJoin(t *testing.T) {
+	tree := NewTreap()
+	for _, i := range rand.Perm(200) {
+		if err := tree.Put(types.Int(i), i); err != nil {
+			t.Error(err)
+		}
+	}
+	left, right := tree.Split(types.Int(73))
+	if tree.Size() != 0 {
+		t.Error("split tree should be empty")
+	}
+	check(t, left.root)
+	check(t, right.root)
+	if left.Size() != 73 || right.Size() != 127 {
+		t.Error("wrong split sizes", left.Size(), right.Size())
+	}
+	for k, next := left.Keys()(); next != nil; k, next = next() {
+		if !k.Less(types.Int(73)) {
+			t.Error("key on wrong side of split", k)
+		}
+	}
+	for k, next := right.Keys()(); next != nil; k, next = next() {
+		if k.Less(types.Int(73)) {
+			t.Error("key on wrong side of split", k)
+		}
+	}
+	if _, err := Join(right, left); err == nil {
+		t.Error("joined overlapping trees")
+	}
+	joined, err := Join(left, right)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, joined.root)
+	j := 0
+	for k, v, next := joined.Iterate()(); next != nil; k, v, next = next() {
+		if !k.Equals(types.Int(j)) || v.(int) != j {
+			t.Error("wrong entry", k, v, j)
+		}
+		j++
+	}
+	if j != 200 {
+		t.Error("wrong number of items", j)
+	}
+}
+
+func BenchmarkTreap(b *testing.B) {
+	b.StopTimer()
+
+	type record struct {
+		key   types.String
+		value types.String
+	}
+
+	records := make([]*record, 100)
+
+	ranrec := func() *record {
+		return &record{randstr(20), randstr(20)}
+	}
+
+	for i := range records {
+		records[i] = ranrec()
+	}
+
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		t := NewTreap()
+		for _, r := range records {
+			t.Put(r.key, r.value)
+		}
+		for _, r := range records {
+			t.Remove(r.key)
+		}
+	}
+}
@@ -47,8 +47,32 @@ func TraverseBinaryTreeInOrder(node types.BinaryTreeNode) types.TreeNodeIterator
 	return tn_iterator
 }
 
+func TraverseBinaryTreeReverseInOrder(node types.BinaryTreeNode) types.TreeNodeIterator {
+	stack := make([]types.TreeNode, 0, 10)
+	var cur types.TreeNode = btn_expose_nil(node)
+	var tn_iterator types.TreeNodeIterator
+	tn_iterator = func() (tn types.TreeNode, next types.TreeNodeIterator) {
+		if len(stack) > 0 || cur != nil {
+			for cur != nil {
+				stack = append(stack, cur)
+				cur = btn_expose_nil(cur.(types.BinaryTreeNode).Right())
+			}
+			stack, cur = pop(stack)
+			tn = cur
+			cur = btn_expose_nil(cur.(types.BinaryTreeNode).Left())
+			return tn, tn_iterator
+		} else {
+			return nil, nil
+		}
+	}
+	return tn_iterator
+}
+
 func TraverseTreePreOrder(node types.TreeNode) types.TreeNodeIterator {
-	stack := append(make([]types.TreeNode, 0, 10), tn_expose_nil(node))
+	stack := make([]types.TreeNode, 0, 10)
+	if tn := tn_expose_nil(node); tn != nil {
+		stack = append(stack, tn)
+	}
 	var tn_iterator types.TreeNodeIterator
 	tn_iterator = func() (tn types.TreeNode, next types.TreeNodeIterator) {
 		if len(stack) <= 0 {
@@ -86,7 +110,10 @@ func TraverseTreePostOrder(node types.TreeNode) types.TreeNodeIterator {
 		}
 	}
 
-	stack := append(make([]entry, 0, 10), entry{tn_expose_nil(node), 0})
+	stack := make([]entry, 0, 10)
+	if tn := tn_expose_nil(node); tn != nil {
+		stack = append(stack, entry{tn, 0})
+	}
 
 	var tn_iterator types.TreeNodeIterator
 	tn_iterator = func() (tn types.TreeNode, next types.TreeNodeIterator) {
@@ -107,3 +134,82 @@ func TraverseTreePostOrder(node types.TreeNode) types.TreeNodeIterator {
 	}
 	return tn_iterator
 }
+
+type LevelIterator func() (node types.TreeNode, depth int, next LevelIterator)
+
+// Visits the nodes breadth first. The root is at depth 0.
+func TraverseTreeLevelOrder(node types.TreeNode) LevelIterator {
+	type entry struct {
+		tn    types.TreeNode
+		depth int
+	}
+	queue := make([]entry, 0, 10)
+	if tn := tn_expose_nil(node); tn != nil {
+		queue = append(queue, entry{tn, 0})
+	}
+	var level_iterator LevelIterator
+	level_iterator = func() (tn types.TreeNode, depth int, next LevelIterator) {
+		if len(queue) <= 0 {
+			return nil, 0, nil
+		}
+		e := queue[0]
+		queue = queue[1:]
+		for child, next := e.tn.Children()(); next != nil; child, next = next() {
+			if kid := tn_expose_nil(child); kid != nil {
+				queue = append(queue, entry{kid, e.depth + 1})
+			}
+		}
+		return e.tn, e.depth, level_iterator
+	}
+	return level_iterator
+}
+
+type WalkAction int
+
+const (
+	// Visit the children of the node.
+	Continue WalkAction = iota
+	// Do not visit the children of the node (but carry on with its siblings).
+	SkipChildren
+	// End the walk.
+	Stop
+)
+
+// Called on each node (with its depth) by Walk. The returned action controls
+// how the walk proceeds.
+type Visitor func(node types.TreeNode, depth int) WalkAction
+
+// Walks the tree in pre-order calling visit on each node. It returns false if
+// the walk was ended early by Stop.
+func Walk(node types.TreeNode, visit Visitor) bool {
+	type entry struct {
+		tn    types.TreeNode
+		depth int
+	}
+	stack := make([]entry, 0, 10)
+	if tn := tn_expose_nil(node); tn != nil {
+		stack = append(stack, entry{tn, 0})
+	}
+	for len(stack) > 0 {
+		e := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		switch visit(e.tn, e.depth) {
+		case Stop:
+			return false
+		case SkipChildren:
+			continue
+		}
+		mark := len(stack)
+		for child, next := e.tn.Children()(); next != nil; child, next = next() {
+			if kid := tn_expose_nil(child); kid != nil {
+				stack = append(stack, entry{kid, e.depth + 1})
+			}
+		}
+		// the children were pushed in order so reverse them to visit the
+		// first child first
+		for i, j := mark, len(stack)-1; i < j; i, j = i+1, j-1 {
+			stack[i], stack[j] = stack[j], stack[i]
+		}
+	}
+	return true
+}
@@ -0,0 +1,112 @@
+package data_structures
+
+import (
+	"sort"
+	"testing"
+)
+
+import (
+	"github.com/timtadh/data-structures/test"
+	"github.com/timtadh/data-structures/tree"
+	"github.com/timtadh/data-structures/tree/avl"
+	"github.com/timtadh/data-structures/tree/rbtree"
+	"github.com/timtadh/data-structures/tree/skiplist"
+	"github.com/timtadh/data-structures/tree/treap"
+	"github.com/timtadh/data-structures/types"
+)
+
+// The sorted maps are interchangeable so they all run through the same tests.
+var sortedMaps = map[string]func() types.Map{
+	"AvlTree":          func() types.Map { return avl.NewAvlTree() },
+	"ImmutableAvlTree": func() types.Map { return avl.NewImmutableAvlTree() },
+	"RbTree":           func() types.Map { return rbtree.NewRbTree() },
+	"Treap":            func() types.Map { return treap.NewTreap() },
+	"SkipList":         func() types.Map { return skiplist.NewSkipList() },
+}
+
+func TestSortedMapsPutHasGetRemove(t *testing.T) {
+	for name, mk := range sortedMaps {
+		m := mk()
+		keys := make([]string, 0, 300)
+		for len(keys) < cap(keys) {
+			key := test.RandHex(16)
+			if m.Has(types.String(key)) {
+				continue
+			}
+			keys = append(keys, key)
+			if err := m.Put(types.String(key), key); err != nil {
+				t.Fatal(name, err)
+			}
+			if m.Size() != len(keys) {
+				t.Fatal(name, "size was wrong", m.Size(), len(keys))
+			}
+		}
+		sort.Strings(keys)
+		i := 0
+		for k, v, next := m.Iterate()(); next != nil; k, v, next = next() {
+			if string(k.(types.String)) != keys[i] || v.(string) != keys[i] {
+				t.Fatal(name, "iterated out of order", k, keys[i])
+			}
+			i++
+		}
+		if i != len(keys) {
+			t.Fatal(name, "iterated the wrong number of items", i)
+		}
+		for i, key := range keys {
+			if v, err := m.Get(types.String(key)); err != nil || v.(string) != key {
+				t.Fatal(name, "wrong value", v, err)
+			}
+			if v, err := m.Remove(types.String(key)); err != nil || v.(string) != key {
+				t.Fatal(name, "wrong value removed", v, err)
+			}
+			if m.Has(types.String(key)) {
+				t.Fatal(name, "key was not removed")
+			}
+			if _, err := m.Get(types.String(key)); err == nil {
+				t.Fatal(name, "got a removed key")
+			}
+			if m.Size() != len(keys)-i-1 {
+				t.Fatal(name, "size was wrong", m.Size(), len(keys)-i-1)
+			}
+		}
+	}
+}
+
+func TestTreeMapsTraversals(t *testing.T) {
+	for name, mk := range sortedMaps {
+		tm, ok := mk().(types.TreeMap)
+		if !ok {
+			continue
+		}
+		for _, i := range []int{6, 1, 8, 2, 4, 9, 5, 7, 0, 3} {
+			if err := tm.Put(types.Int(i), i); err != nil {
+				t.Fatal(name, err)
+			}
+		}
+		j := 0
+		for tn, next := tree.TraverseBinaryTreeInOrder(tm.Root().(types.BinaryTreeNode))(); next != nil; tn, next = next() {
+			if !tn.Key().Equals(types.Int(j)) {
+				t.Error(name, "key in wrong spot in-order", tn.Key(), j)
+			}
+			j++
+		}
+		count := 0
+		for _, next := tree.TraverseTreePostOrder(tm.Root())(); next != nil; _, next = next() {
+			count++
+		}
+		if count != 10 || j != 10 {
+			t.Error(name, "traversal visited the wrong number of nodes", j, count)
+		}
+	}
+}
+
+func FuzzSortedMaps(f *testing.F) {
+	test.AddSeeds(f)
+	f.Fuzz(func(t *testing.T, ops []byte) {
+		for name, mk := range sortedMaps {
+			t.Run(name, func(t *testing.T) {
+				test.CheckMap(t, mk(), ops, test.IntKey)
+			})
+		}
+	})
+}
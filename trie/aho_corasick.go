@@ -0,0 +1,167 @@
+package trie
+
+import (
+	"github.com/timtadh/data-structures/errors"
+	. "github.com/timtadh/data-structures/types"
+)
+
+// A Match is reported by AhoCorasick.FindAll. It carries the matched pattern
+// (as the Key), the value associated with that pattern and the offset in the
+// text where the match starts.
+type Match struct {
+	KV
+	Offset int
+}
+
+type MatchIterator func() (match *Match, next MatchIterator)
+
+type acNode struct {
+	next   map[byte]*acNode
+	fail   *acNode
+	output *acNode // nearest accepting node on the failure chain
+	kv     *KV     // set when a pattern ends at this node
+}
+
+func newAcNode() *acNode {
+	return &acNode{next: make(map[byte]*acNode)}
+}
+
+// An AhoCorasick automaton matches a fixed set of byte string patterns
+// against a text in a single pass. It is built from a list of patterns or
+// from the keys of a TST and is immutable once built.
+type AhoCorasick struct {
+	root *acNode
+	size int
+}
+
+// Build an automaton from a list of patterns. The value associated with each
+// pattern is its index in the list. If a pattern appears more than once the
+// last index wins.
+func NewAhoCorasick(patterns ...[]byte) (*AhoCorasick, error) {
+	self := &AhoCorasick{root: newAcNode()}
+	for i, pattern := range patterns {
+		if err := self.add(pattern, i); err != nil {
+			return nil, err
+		}
+	}
+	self.link()
+	return self, nil
+}
+
+// Build an automaton matching every key in the TST. The values reported by
+// FindAll are the values stored in the TST.
+func NewAhoCorasickFromTST(tst *TST) *AhoCorasick {
+	self := &AhoCorasick{root: newAcNode()}
+	for k, v, next := tst.Iterate()(); next != nil; k, v, next = next() {
+		if err := self.add(k.(ByteSlice), v); err != nil {
+			// keys in a TST are always valid patterns
+			panic(err)
+		}
+	}
+	self.link()
+	return self
+}
+
+func (self *AhoCorasick) Size() int {
+	return self.size
+}
+
+func (self *AhoCorasick) add(pattern []byte, value interface{}) error {
+	if len(pattern) == 0 {
+		return errors.InvalidKey(pattern, "len(key) == 0")
+	}
+	cur := self.root
+	for _, ch := range pattern {
+		n, has := cur.next[ch]
+		if !has {
+			n = newAcNode()
+			cur.next[ch] = n
+		}
+		cur = n
+	}
+	if cur.kv == nil {
+		key := make(ByteSlice, len(pattern))
+		copy(key, pattern)
+		cur.kv = &KV{key: key}
+		self.size++
+	}
+	cur.kv.value = value
+	return nil
+}
+
+/* Compute the failure and output links with a breadth first walk of the trie.
+ * The failure link of a node points at the node for the longest proper suffix
+ * of its string which is also in the trie. The output link points at the
+ * nearest node on the failure chain which ends a pattern.
+ */
+func (self *AhoCorasick) link() {
+	queue := make([]*acNode, 0, len(self.root.next))
+	for _, n := range self.root.next {
+		n.fail = self.root
+		queue = append(queue, n)
+	}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for ch, n := range cur.next {
+			f := cur.fail
+			for f != nil && f.next[ch] == nil {
+				f = f.fail
+			}
+			if f == nil {
+				n.fail = self.root
+			} else {
+				n.fail = f.next[ch]
+			}
+			if n.fail.kv != nil {
+				n.output = n.fail
+			} else {
+				n.output = n.fail.output
+			}
+			queue = append(queue, n)
+		}
+	}
+}
+
+func (self *AhoCorasick) step(cur *acNode, ch byte) *acNode {
+	for cur != self.root && cur.next[ch] == nil {
+		cur = cur.fail
+	}
+	if n, has := cur.next[ch]; has {
+		return n
+	}
+	return self.root
+}
+
+// Find every occurrence of every pattern in the text, including overlapping
+// occurrences. Matches are yielded in order of where they end in the text.
+// Matches ending at the same position are yielded longest first.
+func (self *AhoCorasick) FindAll(text []byte) (mi MatchIterator) {
+	cur := self.root
+	i := -1
+	var pending *acNode
+	mi = func() (match *Match, next MatchIterator) {
+		for pending == nil {
+			i++
+			if i >= len(text) {
+				return nil, nil
+			}
+			cur = self.step(cur, text[i])
+			if cur.kv != nil {
+				pending = cur
+			} else {
+				pending = cur.output
+			}
+		}
+		kv := pending.kv
+		pending = pending.output
+		return &Match{KV: *kv, Offset: i + 1 - len(kv.key)}, mi
+	}
+	return mi
+}
+
+// Does any pattern occur in the text?
+func (self *AhoCorasick) Matches(text []byte) bool {
+	_, next := self.FindAll(text)()
+	return next != nil
+}
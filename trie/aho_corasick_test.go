@@ -0,0 +1,136 @@
+package trie
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/timtadh/data-structures/types"
+)
+
+type naiveMatch struct {
+	pattern string
+	offset  int
+}
+
+func naiveFindAll(patterns [][]byte, text []byte) map[naiveMatch]bool {
+	found := make(map[naiveMatch]bool)
+	for _, p := range patterns {
+		for i := 0; i+len(p) <= len(text); i++ {
+			if bytes.Equal(text[i:i+len(p)], p) {
+				found[naiveMatch{string(p), i}] = true
+			}
+		}
+	}
+	return found
+}
+
+func TestAhoCorasickFindAll(t *testing.T) {
+	patterns := [][]byte{
+		[]byte("he"),
+		[]byte("she"),
+		[]byte("his"),
+		[]byte("hers"),
+	}
+	ac, err := NewAhoCorasick(patterns...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ac.Size() != len(patterns) {
+		t.Error("wrong size", ac.Size())
+	}
+	expected := []naiveMatch{
+		{"she", 1},
+		{"he", 2},
+		{"hers", 2},
+	}
+	i := 0
+	for m, next := ac.FindAll([]byte("ushers"))(); next != nil; m, next = next() {
+		if i >= len(expected) {
+			t.Fatal("too many matches", string(m.Key().(types.ByteSlice)))
+		}
+		if string(m.Key().(types.ByteSlice)) != expected[i].pattern {
+			t.Error("wrong pattern", string(m.Key().(types.ByteSlice)), expected[i].pattern)
+		}
+		if m.Offset != expected[i].offset {
+			t.Error("wrong offset", m.Offset, expected[i].offset)
+		}
+		if !bytes.Equal(patterns[m.Value().(int)], m.Key().(types.ByteSlice)) {
+			t.Error("wrong value", m.Value())
+		}
+		i++
+	}
+	if i != len(expected) {
+		t.Error("missing matches", i)
+	}
+	if ac.Matches([]byte("hxsxe")) {
+		t.Error("should not have matched")
+	}
+	if _, err := NewAhoCorasick([]byte("a"), []byte{}); err == nil {
+		t.Error("expected an error for an empty pattern")
+	}
+}
+
+func TestAhoCorasickRandom(t *testing.T) {
+	alpha := []byte("abc")
+	randtext := func(length int) []byte {
+		text := make([]byte, length)
+		for i := range text {
+			text[i] = alpha[rand.Intn(len(alpha))]
+		}
+		return text
+	}
+	for j := 0; j < 25; j++ {
+		patterns := make([][]byte, 0, 20)
+		for i := 0; i < 20; i++ {
+			patterns = append(patterns, randtext(rand.Intn(5)+1))
+		}
+		ac, err := NewAhoCorasick(patterns...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		text := randtext(200)
+		expected := naiveFindAll(patterns, text)
+		found := make(map[naiveMatch]bool)
+		end := -1
+		for m, next := ac.FindAll(text)(); next != nil; m, next = next() {
+			key := m.Key().(types.ByteSlice)
+			if m.Offset+len(key) < end {
+				t.Error("matches out of order")
+			}
+			end = m.Offset + len(key)
+			found[naiveMatch{string(key), m.Offset}] = true
+		}
+		if len(found) != len(expected) {
+			t.Error("wrong number of matches", len(found), len(expected))
+		}
+		for m := range expected {
+			if !found[m] {
+				t.Error("missing match", m)
+			}
+		}
+	}
+}
+
+func TestAhoCorasickFromTST(t *testing.T) {
+	table := New()
+	for _, key := range []string{"cat", "catty", "at", "tt"} {
+		if err := table.Put([]byte(key), key+"!"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ac := NewAhoCorasickFromTST(table)
+	if ac.Size() != 4 {
+		t.Error("wrong size", ac.Size())
+	}
+	count := 0
+	for m, next := ac.FindAll([]byte("a catty cat"))(); next != nil; m, next = next() {
+		if m.Value().(string) != string(m.Key().(types.ByteSlice))+"!" {
+			t.Error("wrong value", m.Value())
+		}
+		count++
+	}
+	// cat, at, tt, catty, cat, at
+	if count != 6 {
+		t.Error("wrong number of matches", count)
+	}
+}
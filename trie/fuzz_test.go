@@ -0,0 +1,16 @@
+package trie
+
+import (
+	"testing"
+)
+
+import (
+	"github.com/timtadh/data-structures/test"
+)
+
+func FuzzTSTMap(f *testing.F) {
+	test.AddSeeds(f)
+	f.Fuzz(func(t *testing.T, ops []byte) {
+		test.CheckMap(t, NewTSTMap(), ops, test.ByteSliceKey)
+	})
+}
@@ -0,0 +1,252 @@
+package trie
+
+import (
+	"bytes"
+	"sort"
+)
+
+import (
+	. "github.com/timtadh/data-structures/types"
+)
+
+type suffix struct {
+	doc    int
+	offset int
+}
+
+type OccurrenceIterator func() (doc, offset int, next OccurrenceIterator)
+
+// A SuffixArray indexes every suffix of a collection of documents to answer
+// substring queries. Lookups are a binary search over the sorted suffixes so
+// Contains and Count run in O(m log(n)) for a pattern of length m over a
+// corpus with n total bytes. The longest common prefix (LCP) of each pair of
+// adjacent suffixes is also computed.
+type SuffixArray struct {
+	docs     []ByteSlice
+	suffixes []suffix
+	lcp      []int
+}
+
+// Index the given documents. Documents are referred to by their position in
+// the argument list. The suffixes are sorted by prefix doubling in
+// O(n log(n)^2) for n total bytes, however repetitive the documents are.
+func NewSuffixArray(docs ...ByteSlice) *SuffixArray {
+	self := &SuffixArray{docs: docs}
+	n := 0
+	for _, doc := range docs {
+		n += len(doc)
+	}
+	self.suffixes = make([]suffix, 0, n)
+	for d, doc := range docs {
+		for i := range doc {
+			self.suffixes = append(self.suffixes, suffix{d, i})
+		}
+	}
+	self.sortSuffixes()
+	self.computeLCP()
+	return self
+}
+
+/* Prefix doubling. Suffixes are numbered by their position in document order.
+ * After the round for k every suffix has a rank which orders it by its first k
+ * bytes, and the next round orders by the pair of the ranks of the first k
+ * bytes and of the k bytes after them. A suffix which ends within k bytes has
+ * no second half and sorts first. Suffixes which are equal (the same text in
+ * different documents) are left in document order.
+ */
+func (self *SuffixArray) sortSuffixes() {
+	n := len(self.suffixes)
+	if n == 0 {
+		return
+	}
+	longest := 0
+	rank := make([]int, n)
+	for i, s := range self.suffixes {
+		rank[i] = int(self.docs[s.doc][s.offset])
+		if len(self.docs[s.doc]) > longest {
+			longest = len(self.docs[s.doc])
+		}
+	}
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	next := make([]int, n)
+	for k := 1; ; k *= 2 {
+		// the rank of the second half, 0 when there is none
+		second := func(i int) int {
+			s := self.suffixes[i]
+			if s.offset+k < len(self.docs[s.doc]) {
+				return rank[i+k] + 1
+			}
+			return 0
+		}
+		sort.Slice(order, func(x, y int) bool {
+			a, b := order[x], order[y]
+			if rank[a] != rank[b] {
+				return rank[a] < rank[b]
+			} else if sa, sb := second(a), second(b); sa != sb {
+				return sa < sb
+			}
+			return a < b
+		})
+		next[order[0]] = 0
+		for x := 1; x < n; x++ {
+			a, b := order[x-1], order[x]
+			next[b] = next[a]
+			if rank[a] != rank[b] || second(a) != second(b) {
+				next[b]++
+			}
+		}
+		rank, next = next, rank
+		if rank[order[n-1]] == n-1 || 2*k >= longest {
+			break
+		}
+	}
+	sorted := make([]suffix, n)
+	for x, i := range order {
+		sorted[x] = self.suffixes[i]
+	}
+	self.suffixes = sorted
+}
+
+func (self *SuffixArray) bytes(s suffix) []byte {
+	return self.docs[s.doc][s.offset:]
+}
+
+/* Kasai's algorithm. Each suffix is visited in text order so that the common
+ * prefix found for suffix i (minus its first byte) is a lower bound for suffix
+ * i+1 of the same document. This keeps the computation linear.
+ */
+func (self *SuffixArray) computeLCP() {
+	self.lcp = make([]int, len(self.suffixes))
+	rank := make([][]int, len(self.docs))
+	for d, doc := range self.docs {
+		rank[d] = make([]int, len(doc))
+	}
+	for r, s := range self.suffixes {
+		rank[s.doc][s.offset] = r
+	}
+	for d, doc := range self.docs {
+		h := 0
+		for i := range doc {
+			r := rank[d][i]
+			if r == 0 {
+				h = 0
+				continue
+			}
+			a := doc[i:]
+			b := self.bytes(self.suffixes[r-1])
+			for h < len(a) && h < len(b) && a[h] == b[h] {
+				h++
+			}
+			self.lcp[r] = h
+			if h > 0 {
+				h--
+			}
+		}
+	}
+}
+
+// The number of indexed suffixes (the total length of the corpus).
+func (self *SuffixArray) Size() int {
+	return len(self.suffixes)
+}
+
+// The document with the given index.
+func (self *SuffixArray) Document(doc int) ByteSlice {
+	return self.docs[doc]
+}
+
+// The length of the longest common prefix of the i-th and (i-1)-th suffixes
+// in sorted order. LCP(0) is always 0.
+func (self *SuffixArray) LCP(i int) int {
+	return self.lcp[i]
+}
+
+// Returns the half open range [lo, hi) of sorted suffixes which start with
+// the pattern.
+func (self *SuffixArray) find(pattern []byte) (lo, hi int) {
+	prefix := func(i int) []byte {
+		b := self.bytes(self.suffixes[i])
+		if len(b) > len(pattern) {
+			return b[:len(pattern)]
+		}
+		return b
+	}
+	lo = sort.Search(len(self.suffixes), func(i int) bool {
+		return bytes.Compare(prefix(i), pattern) >= 0
+	})
+	hi = sort.Search(len(self.suffixes), func(i int) bool {
+		return bytes.Compare(prefix(i), pattern) > 0
+	})
+	return lo, hi
+}
+
+// Does the pattern occur anywhere in the corpus?
+func (self *SuffixArray) Contains(pattern ByteSlice) bool {
+	lo, hi := self.find(pattern)
+	return lo < hi
+}
+
+// How many times does the pattern occur in the corpus (counting overlapping
+// occurrences)?
+func (self *SuffixArray) Count(pattern ByteSlice) int {
+	lo, hi := self.find(pattern)
+	return hi - lo
+}
+
+// Iterate over every occurrence of the pattern as (document, offset) pairs.
+// The occurrences are yielded in the sorted order of the suffixes which
+// contain them rather than in document order.
+func (self *SuffixArray) Occurrences(pattern ByteSlice) (oi OccurrenceIterator) {
+	i, hi := self.find(pattern)
+	oi = func() (doc, offset int, next OccurrenceIterator) {
+		if i >= hi {
+			return -1, -1, nil
+		}
+		s := self.suffixes[i]
+		i++
+		return s.doc, s.offset, oi
+	}
+	return oi
+}
+
+// Iterate over the documents which contain the pattern. Each document is
+// yielded once, in the order of their indices.
+func (self *SuffixArray) Documents(pattern ByteSlice) (ki KIterator) {
+	lo, hi := self.find(pattern)
+	seen := make(map[int]bool)
+	docs := make([]int, 0, hi-lo)
+	for i := lo; i < hi; i++ {
+		d := self.suffixes[i].doc
+		if !seen[d] {
+			seen[d] = true
+			docs = append(docs, d)
+		}
+	}
+	sort.Ints(docs)
+	ki = func() (doc Hashable, next KIterator) {
+		if len(docs) == 0 {
+			return nil, nil
+		}
+		doc = self.docs[docs[0]]
+		docs = docs[1:]
+		return doc, ki
+	}
+	return ki
+}
+
+// The longest substring which occurs at least twice in the corpus.
+func (self *SuffixArray) LongestRepeated() ByteSlice {
+	best := 0
+	for i, h := range self.lcp {
+		if h > self.lcp[best] {
+			best = i
+		}
+	}
+	if len(self.lcp) == 0 || self.lcp[best] == 0 {
+		return ByteSlice{}
+	}
+	return ByteSlice(self.bytes(self.suffixes[best])[:self.lcp[best]])
+}
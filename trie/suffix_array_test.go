@@ -0,0 +1,124 @@
+package trie
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/timtadh/data-structures/types"
+)
+
+func TestSuffixArrayQueries(t *testing.T) {
+	docs := []types.ByteSlice{
+		types.ByteSlice("banana"),
+		types.ByteSlice("bandana"),
+		types.ByteSlice("cabana"),
+	}
+	sa := NewSuffixArray(docs...)
+	if sa.Size() != 6+7+6 {
+		t.Error("wrong size", sa.Size())
+	}
+	if !sa.Contains(types.ByteSlice("nan")) {
+		t.Error("should contain nan")
+	}
+	if sa.Contains(types.ByteSlice("nab")) {
+		t.Error("should not contain nab")
+	}
+	if c := sa.Count(types.ByteSlice("ana")); c != 4 {
+		t.Error("wrong count for ana", c)
+	}
+	if c := sa.Count(types.ByteSlice("band")); c != 1 {
+		t.Error("wrong count for band", c)
+	}
+	for doc, offset, next := sa.Occurrences(types.ByteSlice("an"))(); next != nil; doc, offset, next = next() {
+		if !bytes.HasPrefix(docs[doc][offset:], []byte("an")) {
+			t.Error("bad occurrence", doc, offset)
+		}
+	}
+	expected := []types.ByteSlice{docs[0], docs[2]}
+	i := 0
+	for doc, next := sa.Documents(types.ByteSlice("bana"))(); next != nil; doc, next = next() {
+		if !doc.Equals(expected[i]) {
+			t.Error("wrong document", doc)
+		}
+		i++
+	}
+	if i != len(expected) {
+		t.Error("wrong number of documents", i)
+	}
+	if lr := sa.LongestRepeated(); !lr.Equals(types.ByteSlice("bana")) {
+		t.Error("wrong longest repeated substring", string(lr))
+	}
+}
+
+func TestSuffixArrayRandom(t *testing.T) {
+	alpha := []byte("ab")
+	randbytes := func(length int) types.ByteSlice {
+		text := make(types.ByteSlice, length)
+		for i := range text {
+			text[i] = alpha[rand.Intn(len(alpha))]
+		}
+		return text
+	}
+	docs := make([]types.ByteSlice, 0, 10)
+	for i := 0; i < 10; i++ {
+		docs = append(docs, randbytes(rand.Intn(50)))
+	}
+	sa := NewSuffixArray(docs...)
+	for i := 1; i < sa.Size(); i++ {
+		a := sa.bytes(sa.suffixes[i-1])
+		b := sa.bytes(sa.suffixes[i])
+		if bytes.Compare(a, b) > 0 {
+			t.Fatal("suffixes out of order")
+		}
+		h := 0
+		for h < len(a) && h < len(b) && a[h] == b[h] {
+			h++
+		}
+		if sa.LCP(i) != h {
+			t.Error("wrong lcp", i, sa.LCP(i), h)
+		}
+	}
+	for j := 0; j < 50; j++ {
+		pattern := randbytes(rand.Intn(5) + 1)
+		count := 0
+		for _, doc := range docs {
+			for i := 0; i+len(pattern) <= len(doc); i++ {
+				if bytes.Equal(doc[i:i+len(pattern)], pattern) {
+					count++
+				}
+			}
+		}
+		if c := sa.Count(pattern); c != count {
+			t.Error("wrong count", string(pattern), c, count)
+		}
+		if sa.Contains(pattern) != (count > 0) {
+			t.Error("wrong contains", string(pattern))
+		}
+	}
+}
+
+// Long runs make comparing whole suffixes quadratic, prefix doubling is not.
+func TestSuffixArrayRepetitive(t *testing.T) {
+	run := types.ByteSlice(bytes.Repeat([]byte("a"), 50000))
+	abab := types.ByteSlice(bytes.Repeat([]byte("ab"), 25000))
+	sa := NewSuffixArray(run, abab, abab)
+	if sa.Size() != 150000 {
+		t.Fatal("wrong size", sa.Size())
+	}
+	for i := 1; i < sa.Size(); i++ {
+		a, b := sa.suffixes[i-1], sa.suffixes[i]
+		c := bytes.Compare(sa.bytes(a), sa.bytes(b))
+		if c > 0 || (c == 0 && a.doc > b.doc) {
+			t.Fatal("suffixes out of order", a, b)
+		}
+	}
+	if c := sa.Count(types.ByteSlice("aaa")); c != 49998 {
+		t.Error("wrong count for aaa", c)
+	}
+	if c := sa.Count(types.ByteSlice("bab")); c != 2*24999 {
+		t.Error("wrong count for bab", c)
+	}
+	if lr := sa.LongestRepeated(); !lr.Equals(abab) {
+		t.Error("wrong longest repeated substring length", len(lr))
+	}
+}
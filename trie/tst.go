@@ -48,25 +48,35 @@ func (self *TST) Put(key []byte, value interface{}) (err error) {
 }
 
 func (self *TST) Has(key []byte) bool {
-	if _, err := self.Get(key); err != nil {
+	if self.ValidateKey(key) != nil {
 		return false
 	}
-	return true
+	_, has := self.get(key)
+	return has
 }
 
 func (self *TST) Get(key []byte) (value interface{}, err error) {
+	if err := self.ValidateKey(key); err != nil {
+		return nil, err
+	}
+	value, has := self.get(key)
+	if !has {
+		return nil, errors.NotFound(key)
+	}
+	return value, nil
+}
+
+// Get without making an error (they are expensive) for a missing key.
+func (self *TST) get(key []byte) (value interface{}, has bool) {
 	type entry struct {
 		n *TSTNode
 		d int
 	}
-	if err := self.ValidateKey(key); err != nil {
-		return nil, err
-	}
 	symbol := append(key, END)
 	next := &entry{self.heads[symbol[0]], 1}
 	for next != nil {
 		if next.n == nil {
-			return nil, errors.NotFound(key)
+			return nil, false
 		} else if next.n.Internal() {
 			ch := symbol[next.d]
 			if ch < next.n.ch {
@@ -77,13 +87,13 @@ func (self *TST) Get(key []byte) (value interface{}, err error) {
 				next = &entry{next.n.r, next.d}
 			}
 		} else if next.n.KeyEq(symbol) {
-			return next.n.value, nil
+			return next.n.value, true
 		} else {
-			return nil, errors.NotFound(key)
+			return nil, false
 		}
 	}
 	// should never reach ...
-	return nil, errors.NotFound(key)
+	return nil, false
 }
 
 func (self *TST) Remove(key []byte) (value interface{}, err error) {
@@ -136,7 +146,7 @@ func (self *TST) Remove(key []byte) (value interface{}, err error) {
 		}
 		return n, nil
 	}
-	n, err := remove(self.heads[symbol[0]], 1)
+	n, err := check(remove(self.heads[symbol[0]], 1))
 	if err != nil {
 		return nil, err
 	}
@@ -200,6 +210,26 @@ func (self *TST) PrefixFind(prefix ByteSlice) KVIterator {
 	return kv_iterator
 }
 
+/* Verify the structure of the trie:
+ *  - only leaves are accepting and every leaf is accepting
+ *  - every key ends in END and starts with the bytes on the path to it
+ *  - the left and right subtrees of a node hold bytes less than and greater
+ *    than the node's byte at that depth
+ *  - the keys are unique and in order
+ */
+func (self *TST) Verify() error {
+	var prev ByteSlice
+	for i, n := range self.heads {
+		if n == nil {
+			continue
+		}
+		if err := n.verify(ByteSlice{byte(i)}, -1, 256, &prev); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (self *TST) Iterate() KVIterator {
 	tnis := make([]TreeNodeIterator, 0, 256)
 	for _, n := range self.heads {
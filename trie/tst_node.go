@@ -119,6 +119,15 @@ func (self *TSTNode) ChildCount() int {
 	return len(self.make_child_slice())
 }
 
+// The byte checked at this node, and the key if it is accepting, for
+// tree.ToDot and tree.Pretty.
+func (self *TSTNode) Label() string {
+	if self.accepting {
+		return fmt.Sprintf("%q %q", self.ch, self.key[:len(self.key)-1])
+	}
+	return fmt.Sprintf("%q", self.ch)
+}
+
 func (self *TSTNode) String() string {
 	if self == nil {
 		return "-"
@@ -168,7 +177,11 @@ func (n *TSTNode) insert(key ByteSlice, val interface{}, d int) (*TSTNode, error
 			}
 			n.l = l
 		} else if ch == n.ch {
-			if d+1 == len(key) && ch == END {
+			if d+1 == len(key) && ch == END && n.m == nil {
+				// the key was removed but its END node is still needed
+				// for the keys on its left and right
+				n.m = NewAcceptingTSTNode(END, key, val)
+			} else if d+1 == len(key) && ch == END {
 				n.m = n.m.Copy()
 				n.m.value = val
 			} else {
@@ -189,6 +202,55 @@ func (n *TSTNode) insert(key ByteSlice, val interface{}, d int) (*TSTNode, error
 	}
 }
 
+/* checks the subtree. prefix holds the bytes matched to reach this depth and
+ * the byte at this depth must be in (lo, hi). prev is the last key seen.
+ */
+func (self *TSTNode) verify(prefix ByteSlice, lo, hi int, prev *ByteSlice) error {
+	d := len(prefix)
+	if !self.Internal() {
+		if !self.accepting || self.key == nil {
+			return errors.TSTError("leaf at depth %v is not accepting", d)
+		} else if self.key[len(self.key)-1] != END {
+			return errors.TSTError("key %v does not end in END", self.key)
+		}
+		for i := 0; i < d && i < len(self.key); i++ {
+			if self.key[i] != prefix[i] {
+				return errors.TSTError("key %v is under the prefix %v", self.key, prefix)
+			}
+		}
+		if d < len(self.key) && (int(self.key[d]) <= lo || int(self.key[d]) >= hi) {
+			return errors.TSTError("key %v is on the wrong side of its parent", self.key)
+		}
+		if *prev != nil && !prev.Less(self.key) {
+			return errors.TSTError("key %v is out of order, previous key %v", self.key, *prev)
+		}
+		*prev = self.key
+		return nil
+	}
+	if self.accepting {
+		return errors.TSTError("internal node at depth %v is accepting", d)
+	} else if int(self.ch) <= lo || int(self.ch) >= hi {
+		return errors.TSTError("byte %x at depth %v is on the wrong side of its parent", self.ch, d)
+	}
+	if self.l != nil {
+		if err := self.l.verify(prefix, lo, int(self.ch), prev); err != nil {
+			return err
+		}
+	}
+	if self.m != nil {
+		m_prefix := append(append(make(ByteSlice, 0, d+1), prefix...), self.ch)
+		if err := self.m.verify(m_prefix, -1, 256, prev); err != nil {
+			return err
+		}
+	}
+	if self.r != nil {
+		if err := self.r.verify(prefix, int(self.ch), hi, prev); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 /* a is the new (conflicting node)
  * b is the node that needs to be split
  * d is the depth
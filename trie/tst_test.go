@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"sort"
+	"strings"
 	"testing"
 
 	crand "crypto/rand"
@@ -12,6 +13,7 @@ import (
 
 	trand "github.com/timtadh/data-structures/rand"
 	"github.com/timtadh/data-structures/test"
+	"github.com/timtadh/data-structures/tree"
 	"github.com/timtadh/data-structures/types"
 )
 
@@ -291,6 +293,77 @@ func TestPutHasGetRemove(t *testing.T) {
 	test(new(TST))
 }
 
+func TestRemoveAndPutAgain(t *testing.T) {
+	table := new(TST)
+	for _, key := range []string{"a", "ab"} {
+		if err := table.Put([]byte(key), key); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the END node of "a" has "ab" beside it so it stays in the tree
+	if _, err := table.Remove([]byte("a")); err != nil {
+		t.Fatal(err)
+	}
+	if table.Has([]byte("a")) {
+		t.Fatal("has a removed key")
+	}
+	if err := table.Put([]byte("a"), "again"); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := table.Get([]byte("a")); err != nil || v != "again" {
+		t.Fatalf("got %v, %v", v, err)
+	}
+	if err := table.Verify(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRemoveLastKeysOfHead(t *testing.T) {
+	table := new(TST)
+	for _, key := range []string{"bc", "b"} {
+		if err := table.Put([]byte(key), key); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, key := range []string{"b", "bc"} {
+		if _, err := table.Remove([]byte(key)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if table.heads['b'] != nil {
+		t.Fatalf("removing the last keys left their head %v", table.heads['b'])
+	}
+	if err := table.Verify(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTSTMap(t *testing.T) {
+	m := NewTSTMap()
+	var _ types.Map = m
+	if err := m.Put(types.String("ab"), 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Put(types.ByteSlice("ab"), 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Put(types.Int(1), 3); err == nil {
+		t.Fatal("put a key which is not a byte string")
+	}
+	if m.Size() != 1 {
+		t.Fatalf("size %v", m.Size())
+	}
+	if v, err := m.Get(types.String("ab")); err != nil || v != 2 {
+		t.Fatalf("got %v, %v", v, err)
+	}
+	if v, err := m.Remove(types.ByteSlice("ab")); err != nil || v != 2 || m.Size() != 0 {
+		t.Fatalf("removed %v, %v leaving %v", v, err, m.Size())
+	}
+	if _, err := m.Remove(types.ByteSlice("ab")); err == nil || m.Size() != 0 {
+		t.Fatalf("removed a missing key leaving %v", m.Size())
+	}
+}
+
 func BenchmarkTST(b *testing.B) {
 	b.StopTimer()
 
@@ -320,3 +393,82 @@ func BenchmarkTST(b *testing.B) {
 		}
 	}
 }
+
+func TestTSTLevelOrderAndWalk(t *testing.T) {
+	table := new(TST)
+	keys := []string{"cat", "car", "cart", "dog", "do"}
+	for _, k := range keys {
+		if err := table.Put([]byte(k), k); err != nil {
+			t.Fatal(err)
+		}
+	}
+	accepting := 0
+	for _, head := range table.heads {
+		last := 0
+		for tn, depth, next := tree.TraverseTreeLevelOrder(head)(); next != nil; tn, depth, next = next() {
+			if depth < last {
+				t.Fatal("level-order went back up the tree")
+			}
+			last = depth
+			if tn.(*TSTNode).accepting {
+				accepting++
+			}
+		}
+	}
+	if accepting != len(keys) {
+		t.Error("wrong number of accepting nodes", accepting)
+	}
+
+	// prune everything below the 'r' of "car" so only "cat" is found under 'c'
+	found := make([]string, 0)
+	tree.Walk(table.heads['c'], func(tn types.TreeNode, depth int) tree.WalkAction {
+		n := tn.(*TSTNode)
+		if n.accepting {
+			found = append(found, n.value.(string))
+		}
+		if n.ch == 'r' {
+			return tree.SkipChildren
+		}
+		return tree.Continue
+	})
+	if len(found) != 1 || found[0] != "cat" {
+		t.Error("wrong keys found by pruned walk", found)
+	}
+	if s := tree.Pretty(table.heads['d']); !strings.HasPrefix(s, "'o'\n") || !strings.Contains(s, `'\x00' "dog"`) {
+		t.Errorf("wrong pretty tree\n%v", s)
+	}
+}
+
+func TestTSTVerify(t *testing.T) {
+	table := new(TST)
+	keys := make([][]byte, 0, 500)
+	for i := 0; i < 500; i++ {
+		// a small alphabet gives lots of shared prefixes
+		key := make([]byte, rand.Intn(5)+1)
+		for j := range key {
+			key[j] = "abc"[rand.Intn(3)]
+		}
+		keys = append(keys, key)
+		if err := table.Put(key, i); err != nil {
+			t.Fatal(err)
+		}
+		if err := table.Verify(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, key := range keys[:250] {
+		table.Remove(key)
+		if err := table.Verify(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, n := range table.heads {
+		if n != nil && n.Internal() {
+			n.accepting = true
+			break
+		}
+	}
+	if err := table.Verify(); err == nil {
+		t.Error("expected an error for an accepting internal node")
+	}
+}
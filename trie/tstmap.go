@@ -0,0 +1,77 @@
+package trie
+
+import (
+	"github.com/timtadh/data-structures/errors"
+	. "github.com/timtadh/data-structures/types"
+)
+
+/* A TSTMap is a TST which takes Hashable keys so it can be used as a
+ * types.Map. The keys must be a ByteSlice or a String and they are always
+ * returned as a ByteSlice.
+ */
+type TSTMap struct {
+	TST
+	size int
+}
+
+func NewTSTMap() *TSTMap {
+	return &TSTMap{}
+}
+
+func tst_key(key Hashable) ([]byte, error) {
+	switch k := key.(type) {
+	case ByteSlice:
+		return []byte(k), nil
+	case String:
+		return []byte(k), nil
+	}
+	return nil, errors.InvalidKey(key, "a TSTMap key must be a ByteSlice or a String")
+}
+
+func (self *TSTMap) Size() int {
+	return self.size
+}
+
+func (self *TSTMap) Has(key Hashable) bool {
+	k, err := tst_key(key)
+	if err != nil {
+		return false
+	}
+	return self.TST.Has(k)
+}
+
+func (self *TSTMap) Put(key Hashable, value interface{}) (err error) {
+	k, err := tst_key(key)
+	if err != nil {
+		return err
+	}
+	had := self.TST.Has(k)
+	if err := self.TST.Put(k, value); err != nil {
+		return err
+	}
+	if !had {
+		self.size += 1
+	}
+	return nil
+}
+
+func (self *TSTMap) Get(key Hashable) (value interface{}, err error) {
+	k, err := tst_key(key)
+	if err != nil {
+		return nil, err
+	}
+	return self.TST.Get(k)
+}
+
+func (self *TSTMap) Remove(key Hashable) (value interface{}, err error) {
+	k, err := tst_key(key)
+	if err != nil {
+		return nil, err
+	}
+	value, err = self.TST.Remove(k)
+	if err != nil {
+		return nil, err
+	}
+	self.size -= 1
+	return value, nil
+}